@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/kkato1030/al/internal/config"
 	"github.com/spf13/cobra"
@@ -13,10 +14,19 @@ func NewConfigAliasCmd() *cobra.Command {
 	aliasCmd := &cobra.Command{
 		Use:   "alias",
 		Short: "Manage command aliases",
-		Long:  "Manage command aliases",
+		Long: `Manage command aliases.
+
+Alias commands may contain variables:
+  {1}, {2}, ...        positional arguments given after the alias (consumed)
+  {args}               remaining arguments (appended at the end if omitted)
+  {package.<field>}    field of the package named by the first argument (e.g. profile, provider, promote_to, stage)
+  {profile.<field>}    field of that package's profile, or of the default profile
+  {config.<field>}     field of config.json (default_provider, default_profile, default_stage)`,
 	}
 
 	aliasCmd.AddCommand(NewConfigAliasListCmd())
+	aliasCmd.AddCommand(NewConfigAliasAddCmd())
+	aliasCmd.AddCommand(NewConfigAliasRemoveCmd())
 
 	return aliasCmd
 }
@@ -26,14 +36,23 @@ func NewConfigAliasListCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "list",
 		Short: "List all command aliases",
-		Long:  "List all available command aliases",
+		Long:  "List all available command aliases (default and user-defined)",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			aliases := config.GetDefaultAliases()
+			aliases, err := config.GetAliases()
+			if err != nil {
+				return fmt.Errorf("error loading aliases: %w", err)
+			}
 			if len(aliases) == 0 {
 				fmt.Println("No aliases configured")
 				return nil
 			}
 
+			appConfig, err := config.LoadAppConfig()
+			if err != nil {
+				return fmt.Errorf("error loading config: %w", err)
+			}
+			defaults := config.GetDefaultAliases()
+
 			fmt.Println("Available aliases:")
 
 			// Sort alias names for consistent output
@@ -44,9 +63,67 @@ func NewConfigAliasListCmd() *cobra.Command {
 			sort.Strings(aliasNames)
 
 			for _, name := range aliasNames {
-				fmt.Printf("  %-10s %s\n", name, aliases[name])
+				marker := ""
+				if _, isUser := appConfig.Aliases[name]; isUser {
+					marker = " (user)"
+					if _, isDefault := defaults[name]; isDefault {
+						marker = " (user, overrides default)"
+					}
+				}
+				fmt.Printf("  %-10s %s%s\n", name, aliases[name], marker)
+			}
+
+			return nil
+		},
+	}
+}
+
+// NewConfigAliasAddCmd creates the config alias add command
+func NewConfigAliasAddCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "add <name> <command>...",
+		Short: "Add or override a command alias",
+		Long:  "Add a user-defined alias, or override a default alias with the same name. The command may be given as one quoted argument or as several arguments.",
+		Example: `  al config alias add demote 'package move {1} --to {package.profile}.trial'
+  al config alias add trial 'package add {1} --stage trial {args}'`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			command := strings.Join(args[1:], " ")
+
+			// Aliases are resolved before commands, so an alias would hide a built-in command
+			for _, c := range cmd.Root().Commands() {
+				if c.Name() == name {
+					return fmt.Errorf("'%s' is a built-in command and cannot be used as an alias name", name)
+				}
+			}
+
+			if err := config.SetAlias(name, command); err != nil {
+				return fmt.Errorf("error setting alias: %w", err)
 			}
+			fmt.Printf("Alias '%s' set to: %s\n", name, command)
+			return nil
+		},
+	}
+}
 
+// NewConfigAliasRemoveCmd creates the config alias remove command
+func NewConfigAliasRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Remove a command alias",
+		Long:  "Remove a user-defined alias. Removing an override restores the default alias; removing a default alias disables it.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			restored, err := config.RemoveAlias(args[0])
+			if err != nil {
+				return fmt.Errorf("error removing alias: %w", err)
+			}
+			if restored {
+				fmt.Printf("Alias '%s' has been restored to its default: %s\n", args[0], config.GetDefaultAliases()[args[0]])
+			} else {
+				fmt.Printf("Alias '%s' has been removed\n", args[0])
+			}
 			return nil
 		},
 	}
@@ -12,14 +12,17 @@ func NewConfigSetCmd() *cobra.Command {
 	var defaultProvider string
 	var defaultProfile string
 	var defaultStage string
+	var logRetentionDays int
+	var logMaxRuns int
 
 	cmd := &cobra.Command{
 		Use:   "set",
 		Short: "Set configuration values",
-		Long:  "Set default_provider, default_profile, and/or default_stage",
+		Long:  "Set default_provider, default_profile, default_stage, and/or the retention of operation logs",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			if defaultProvider == "" && defaultProfile == "" && defaultStage == "" {
-				return fmt.Errorf("at least one of --default-provider, --default-profile, or --default-stage must be specified")
+			logsChanged := cmd.Flags().Changed("log-retention-days") || cmd.Flags().Changed("log-max-runs")
+			if defaultProvider == "" && defaultProfile == "" && defaultStage == "" && !logsChanged {
+				return fmt.Errorf("at least one of --default-provider, --default-profile, --default-stage, --log-retention-days, or --log-max-runs must be specified")
 			}
 
 			if defaultProvider != "" {
@@ -43,6 +46,20 @@ func NewConfigSetCmd() *cobra.Command {
 				fmt.Printf("Default stage set to: %s\n", defaultStage)
 			}
 
+			if logsChanged {
+				var retentionDays, maxRuns *int
+				if cmd.Flags().Changed("log-retention-days") {
+					retentionDays = &logRetentionDays
+				}
+				if cmd.Flags().Changed("log-max-runs") {
+					maxRuns = &logMaxRuns
+				}
+				if err := config.SetLogRetention(retentionDays, maxRuns); err != nil {
+					return fmt.Errorf("error setting log retention: %w", err)
+				}
+				fmt.Println("Log retention updated")
+			}
+
 			return nil
 		},
 	}
@@ -50,6 +67,8 @@ func NewConfigSetCmd() *cobra.Command {
 	cmd.Flags().StringVar(&defaultProvider, "default-provider", "", "Set the default provider")
 	cmd.Flags().StringVar(&defaultProfile, "default-profile", "", "Set the default profile")
 	cmd.Flags().StringVar(&defaultStage, "default-stage", "", "Set the default stage")
+	cmd.Flags().IntVar(&logRetentionDays, "log-retention-days", 0, "Remove operation logs older than this many days (0: default of 30, -1: keep regardless of age)")
+	cmd.Flags().IntVar(&logMaxRuns, "log-max-runs", 0, "Keep at most this many operation logs (0: default of 50, -1: no limit)")
 
 	return cmd
 }
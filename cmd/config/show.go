@@ -2,14 +2,18 @@ package config
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/spf13/cobra"
 )
 
 // NewConfigShowCmd creates the config show command
 func NewConfigShowCmd() *cobra.Command {
-	return &cobra.Command{
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "Show current configuration",
 		Long:  "Display the current configuration values",
@@ -19,26 +23,50 @@ func NewConfigShowCmd() *cobra.Command {
 				return fmt.Errorf("error loading config: %w", err)
 			}
 
-			fmt.Println("Current configuration:")
-			if appConfig.DefaultProvider != "" {
-				fmt.Printf("  default_provider: %s\n", appConfig.DefaultProvider)
-			} else {
-				fmt.Println("  default_provider: (not set)")
-			}
+			return output.Print(outputOpts, output.Result{
+				Data:    appConfig,
+				Columns: []string{"default_provider", "default_profile", "default_stage"},
+				Table: func(w io.Writer) error {
+					printConfigTable(w, appConfig)
+					return nil
+				},
+			})
+		},
+	}
 
-			if appConfig.DefaultProfile != "" {
-				fmt.Printf("  default_profile: %s\n", appConfig.DefaultProfile)
-			} else {
-				fmt.Println("  default_profile: (not set)")
-			}
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
 
-			if appConfig.DefaultStage != "" {
-				fmt.Printf("  default_stage: %s\n", appConfig.DefaultStage)
-			} else {
-				fmt.Println("  default_stage: (not set)")
-			}
+	return cmd
+}
 
-			return nil
-		},
+func printConfigTable(w io.Writer, appConfig *config.AppConfig) {
+	fmt.Fprintln(w, "Current configuration:")
+	if appConfig.DefaultProvider != "" {
+		fmt.Fprintf(w, "  default_provider: %s\n", appConfig.DefaultProvider)
+	} else {
+		fmt.Fprintln(w, "  default_provider: (not set)")
+	}
+
+	if appConfig.DefaultProfile != "" {
+		fmt.Fprintf(w, "  default_profile: %s\n", appConfig.DefaultProfile)
+	} else {
+		fmt.Fprintln(w, "  default_profile: (not set)")
+	}
+
+	if appConfig.DefaultStage != "" {
+		fmt.Fprintf(w, "  default_stage: %s\n", appConfig.DefaultStage)
+	} else {
+		fmt.Fprintln(w, "  default_stage: (not set)")
+	}
+
+	fmt.Fprintf(w, "  logs.retention_days: %s\n", formatLogLimit(appConfig.Logs.EffectiveRetentionDays()))
+	fmt.Fprintf(w, "  logs.max_runs: %s\n", formatLogLimit(appConfig.Logs.EffectiveMaxRuns()))
+}
+
+// formatLogLimit formats a log retention limit, where negative means no limit
+func formatLogLimit(limit int) string {
+	if limit < 0 {
+		return "(no limit)"
 	}
+	return fmt.Sprint(limit)
 }
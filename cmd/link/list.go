@@ -2,8 +2,10 @@ package link
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/kkato1030/al/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -11,20 +13,22 @@ import (
 // NewListCmd creates the link list command
 func NewListCmd() *cobra.Command {
 	var pkgName string
+	var outputOpts output.Options
 	cmd := &cobra.Command{
 		Use:   "list [--package <pkg>]",
 		Short: "List link.d entries",
 		Long:  "List managed links. Use --package to filter by package name.",
 		Args:  cobra.NoArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runList(pkgName)
+			return runList(pkgName, outputOpts)
 		},
 	}
 	cmd.Flags().StringVar(&pkgName, "package", "", "Filter by package name")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
 	return cmd
 }
 
-func runList(pkgName string) error {
+func runList(pkgName string, outputOpts output.Options) error {
 	var packageID, packageProvider string
 	if pkgName != "" {
 		pkg, err := ui.ResolvePackageByName(pkgName)
@@ -38,16 +42,25 @@ func runList(pkgName string) error {
 	if err != nil {
 		return err
 	}
-	if len(links) == 0 {
-		fmt.Println("(no links)")
-		return nil
+	if links == nil {
+		links = []config.LinkEntry{}
 	}
-	for _, l := range links {
-		pkgInfo := ""
-		if l.Manifest.PackageID != "" {
-			pkgInfo = fmt.Sprintf(" [package: %s/%s]", l.Manifest.PackageID, l.Manifest.PackageProvider)
-		}
-		fmt.Printf("%s -> %s (%s)%s\n", l.Name, l.Manifest.UserPath, l.Manifest.Type, pkgInfo)
-	}
-	return nil
+	return output.Print(outputOpts, output.Result{
+		Data:    links,
+		Columns: []string{"name", "manifest.user_path", "manifest.type", "manifest.package_id", "manifest.package_provider"},
+		Table: func(w io.Writer) error {
+			if len(links) == 0 {
+				fmt.Fprintln(w, "(no links)")
+				return nil
+			}
+			for _, l := range links {
+				pkgInfo := ""
+				if l.Manifest.PackageID != "" {
+					pkgInfo = fmt.Sprintf(" [package: %s/%s]", l.Manifest.PackageID, l.Manifest.PackageProvider)
+				}
+				fmt.Fprintf(w, "%s -> %s (%s)%s\n", l.Name, l.Manifest.UserPath, l.Manifest.Type, pkgInfo)
+			}
+			return nil
+		},
+	})
 }
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kkato1030/al/internal/oplog"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/spf13/cobra"
+)
+
+// logEntry is a logged operation as listed by 'al logs --list'
+type logEntry struct {
+	ID    string   `json:"id"`
+	Op    string   `json:"op"`
+	Time  string   `json:"time"`
+	Dir   string   `json:"dir"`
+	Files []string `json:"files"`
+}
+
+// NewLogsCmd creates the logs command
+func NewLogsCmd() *cobra.Command {
+	var list bool
+	var packageName string
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
+		Use:   "logs [last|<id>]",
+		Short: "Show the provider output of past operations",
+		Long: `Show the output of provider commands (brew, mas, ...) recorded by upgrade, add, import, remove, and provider upgrade.
+Each operation is logged in $AL_HOME/logs/<timestamp>-<op>/ with one <package>.log per package.
+Without an argument the most recent operation is shown; <id> may be shortened to a unique prefix.
+How long logs are kept is set with 'al config set --log-retention-days' and '--log-max-runs'.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if list {
+				return runLogsList(outputOpts)
+			}
+			id := "last"
+			if len(args) > 0 {
+				id = args[0]
+			}
+			return runLogsShow(id, packageName)
+		},
+	}
+
+	cmd.Flags().BoolVarP(&list, "list", "l", false, "List logged operations instead of showing one")
+	cmd.Flags().StringVarP(&packageName, "package", "p", "", "Only show the log of this package")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
+}
+
+func runLogsList(outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	runs, err := oplog.List()
+	if err != nil {
+		return fmt.Errorf("error loading logs: %w", err)
+	}
+
+	entries := make([]logEntry, 0, len(runs))
+	for _, run := range runs {
+		files, err := run.Files()
+		if err != nil {
+			return fmt.Errorf("error reading log %s: %w", run.ID, err)
+		}
+		names := make([]string, len(files))
+		for i, file := range files {
+			names[i] = strings.TrimSuffix(filepath.Base(file), ".log")
+		}
+		entries = append(entries, logEntry{
+			ID:    run.ID,
+			Op:    run.Op,
+			Time:  run.Time.Format("2006-01-02 15:04:05"),
+			Dir:   run.Dir,
+			Files: names,
+		})
+	}
+
+	return output.Print(outputOpts, output.Result{
+		Data:    entries,
+		Columns: []string{"id", "op", "time", "files"},
+		Table: func(w io.Writer) error {
+			if len(entries) == 0 {
+				fmt.Fprintln(w, "No logs found")
+				return nil
+			}
+			for _, entry := range entries {
+				fmt.Fprintf(w, "  %s  (%d log(s))\n", entry.ID, len(entry.Files))
+			}
+			return nil
+		},
+	})
+}
+
+func runLogsShow(id, packageName string) error {
+	run, err := oplog.Find(id)
+	if err != nil {
+		return err
+	}
+
+	files, err := run.Files()
+	if err != nil {
+		return fmt.Errorf("error reading log %s: %w", run.ID, err)
+	}
+
+	fmt.Printf("Log %s (%s)\n", run.ID, run.Dir)
+	shown := 0
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".log")
+		if packageName != "" && name != packageName && name != strings.ReplaceAll(packageName, "/", "_") {
+			continue
+		}
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %w", file, err)
+		}
+		fmt.Printf("\n==> %s <==\n", name)
+		os.Stdout.Write(data)
+		if len(data) > 0 && data[len(data)-1] != '\n' {
+			fmt.Println()
+		}
+		shown++
+	}
+
+	if shown == 0 {
+		if packageName != "" {
+			return fmt.Errorf("no log for package '%s' in %s", packageName, run.ID)
+		}
+		fmt.Println("(no provider output)")
+	}
+	return nil
+}
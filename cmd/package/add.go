@@ -2,35 +2,65 @@ package packagecmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/download"
+	"github.com/kkato1030/al/internal/oplog"
 	"github.com/kkato1030/al/internal/provider"
 	"github.com/kkato1030/al/internal/ui"
 	"github.com/spf13/cobra"
 )
 
+// packageAddOptions are the settings of 'al package add' besides the package name
+type packageAddOptions struct {
+	provider    string
+	profile     string
+	version     string
+	description string
+	id          string
+	url         string
+	options     []string
+	scripts     map[string]string // script package scripts by kind
+	git         gitFlags
+	binary      binaryFlags
+}
+
 // NewPackageAddCmd creates the package add command
 func NewPackageAddCmd() *cobra.Command {
-	var provider string
-	var profile string
+	var opts packageAddOptions
 	var stage string
-	var version string
-	var description string
-	var packageID string
+	scripts := make(map[string]*string, len(config.ScriptKinds))
+	for _, kind := range config.ScriptKinds {
+		scripts[kind] = new(string)
+	}
 
 	cmd := &cobra.Command{
 		Use:   "add [package-name]",
 		Short: "Add a package",
-		Long:  "Add a package to a profile with a provider. If package-name is not provided, interactive mode will be used.",
+		Long: `Add a package to a profile with a provider. If package-name is not provided, interactive mode will be used.
+For brew, a formula or cask from a tap (user/repo/tool) needs the tap user/repo: it is tapped and registered in the
+same profile unless already registered. Use --url to add a tap with a custom remote (brew tap user/repo <url>).
+Use --opt (repeatable) to pass options to the provider on install and upgrade, e.g. --opt=--HEAD or
+--opt=--no-quarantine --opt=--appdir=~/Applications --opt=--greedy for brew. Re-adding a package without --opt
+keeps its options. A script package needs --install-script and --check-script (exit status 0 when installed), and
+can have --uninstall-script and --upgrade-script; they are stored in ~/.al/scripts.d/<id>/. A git package is cloned
+from --url to --path and follows the remote's default branch, or --ref (a branch, tag, or commit); --source writes a
+shell.d snippet that sources a file of the clone (e.g. --source=zsh-autosuggestions.zsh). A binary package is
+downloaded from --url, a template with {version}, {os}, and {arch}, verified with --sha256 (a digest, or the URL of a
+checksums file), and installed to --dir (default: ~/.local/bin); --member selects the file of a .tar.gz or .zip
+archive, and --latest-from (github:owner/repo or a URL) where newer versions are found.`,
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.scripts = scriptFlagValues(scripts)
+
 			// If no package name provided, use fully interactive mode
 			if len(args) == 0 {
-				return runPackageAddInteractive("", provider, profile, stage, version, description, packageID)
+				return runPackageAddInteractive(cmd.Context(), "", stage, opts)
 			}
 
 			packageName := args[0]
@@ -42,13 +72,13 @@ func NewPackageAddCmd() *cobra.Command {
 			}
 
 			// Determine provider from flag or default
-			finalProvider := provider
+			finalProvider := opts.provider
 			if finalProvider == "" {
 				finalProvider = appConfig.DefaultProvider
 			}
 
 			// Build final profile name from profile and stage flags/defaults
-			finalProfile, err := buildProfileName(profile, stage, appConfig.DefaultProfile, appConfig.DefaultStage)
+			finalProfile, err := buildProfileName(opts.profile, stage, appConfig.DefaultProfile, appConfig.DefaultStage)
 			if err != nil {
 				return fmt.Errorf("error building profile name: %w", err)
 			}
@@ -58,6 +88,10 @@ func NewPackageAddCmd() *cobra.Command {
 				return fmt.Errorf("provider and profile must be specified via flags or default config. Use 'al config set --default-provider <provider> --default-profile <profile> --default-stage <stage>' to set defaults")
 			}
 
+			if err := provider.CheckHost(finalProvider); err != nil {
+				return err
+			}
+
 			// Verify that provider and profile exist
 			providerConfig, err := config.GetProvider(finalProvider)
 			if err != nil {
@@ -76,19 +110,32 @@ func NewPackageAddCmd() *cobra.Command {
 				return fmt.Errorf("profile '%s' does not exist", finalProfile)
 			}
 			
-			// Update finalProfile to the actual profile name found
-			finalProfile = profileConfig.Name
+			// Use the actual profile name found
+			opts.provider = finalProvider
+			opts.profile = profileConfig.Name
 
-			return runPackageAdd(packageName, finalProvider, finalProfile, version, description, packageID)
+			return runPackageAdd(cmd.Context(), packageName, opts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Provider name (required)")
-	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Profile name (profile_name, or full profile_name.stage_name)")
+	cmd.Flags().StringVarP(&opts.provider, "provider", "p", "", "Provider name (required)")
+	cmd.Flags().StringVarP(&opts.profile, "profile", "f", "", "Profile name (profile_name, or full profile_name.stage_name)")
 	cmd.Flags().StringVarP(&stage, "stage", "s", "", "Stage name (stage_name)")
-	cmd.Flags().StringVarP(&version, "version", "v", "", "Package version (optional)")
-	cmd.Flags().StringVarP(&description, "description", "d", "", "Package description (optional)")
-	cmd.Flags().StringVarP(&packageID, "id", "i", "", "Package ID (required for mas, optional for brew)")
+	cmd.Flags().StringVarP(&opts.version, "version", "v", "", "Package version (optional)")
+	cmd.Flags().StringVarP(&opts.description, "description", "d", "", "Package description (optional)")
+	cmd.Flags().StringVarP(&opts.id, "id", "i", "", "Package ID (required for mas, optional for brew)")
+	cmd.Flags().StringVar(&opts.url, "url", "", "Remote URL of a brew tap that is not on GitHub (adds package-name as a tap) or of a git package, or download URL template of a binary package")
+	cmd.Flags().StringVar(&opts.git.path, "path", "", "Destination path of a git package's clone (its package ID)")
+	cmd.Flags().StringVar(&opts.git.ref, "ref", "", "Branch, tag, or commit a git package follows (default: the remote's default branch)")
+	cmd.Flags().StringVar(&opts.git.source, "source", "", "File of a git package's clone to source from shell.d (relative to --path)")
+	cmd.Flags().StringVar(&opts.binary.sha256, "sha256", "", "SHA-256 of a binary package's download, or URL template of its checksums file")
+	cmd.Flags().StringVar(&opts.binary.member, "member", "", "File of a binary package's archive to install (default: the file named like the package)")
+	cmd.Flags().StringVar(&opts.binary.dir, "dir", "", "Directory a binary package is installed to (default: ~/.local/bin)")
+	cmd.Flags().StringVar(&opts.binary.latestFrom, "latest-from", "", "Where a binary package's latest version is found: github:owner/repo, or the URL of a version file")
+	cmd.Flags().StringArrayVar(&opts.options, "opt", nil, "Option passed to the provider on install and upgrade (repeatable, e.g. --opt=--HEAD)")
+	for _, kind := range config.ScriptKinds {
+		cmd.Flags().StringVar(scripts[kind], scriptFlagName(kind), "", fmt.Sprintf("Shell script run as the %s script of a script package", kind))
+	}
 
 	return cmd
 }
@@ -155,11 +202,22 @@ func findProfileWithFallback(fullProfileName, stageFlag string) (*config.Profile
 }
 
 // RunPackageAdd runs the package add logic (exported for use by other commands)
-func RunPackageAdd(packageName, providerName, profile, version, description, packageID string) error {
-	return runPackageAdd(packageName, providerName, profile, version, description, packageID)
+func RunPackageAdd(ctx context.Context, packageName, providerName, profile, version, description, packageID string) error {
+	return runPackageAdd(ctx, packageName, packageAddOptions{
+		provider:    providerName,
+		profile:     profile,
+		version:     version,
+		description: description,
+		id:          packageID,
+	})
 }
 
-func runPackageAdd(packageName, providerName, profile, version, description, packageID string) error {
+func runPackageAdd(ctx context.Context, packageName string, opts packageAddOptions) error {
+	providerName := opts.provider
+	if err := provider.CheckHost(providerName); err != nil {
+		return err
+	}
+
 	// Validate provider exists
 	providerConfig, err := config.GetProvider(providerName)
 	if err != nil {
@@ -172,90 +230,93 @@ func runPackageAdd(packageName, providerName, profile, version, description, pac
 	// Validate profile exists, with fallback to profile_name without stage if stage is specified
 	// Check if profile name contains "." (indicating stage is specified)
 	stageFlag := ""
-	if strings.Contains(profile, ".") {
+	if strings.Contains(opts.profile, ".") {
 		stageFlag = "specified" // Any non-empty string to trigger fallback
 	}
-	profileConfig, err := findProfileWithFallback(profile, stageFlag)
+	profileConfig, err := findProfileWithFallback(opts.profile, stageFlag)
 	if err != nil {
 		return fmt.Errorf("error loading profile: %w", err)
 	}
 	if profileConfig == nil {
-		return fmt.Errorf("profile '%s' does not exist", profile)
+		return fmt.Errorf("profile '%s' does not exist", opts.profile)
 	}
-	
+
 	// Update profile to the actual profile name found
-	profile = profileConfig.Name
+	profile := profileConfig.Name
 
-	// Determine package ID and name based on provider
-	var finalID string
-	var finalName string
-	var p provider.Provider
+	if opts.url != "" && providerName != "brew" && providerName != "git" && providerName != "binary" {
+		return fmt.Errorf("--url is only supported for brew taps, git packages, and binary packages")
+	}
+	if opts.git.isSet() && providerName != "git" {
+		return fmt.Errorf("--path, --ref, and --source are only supported by the git provider")
+	}
+	if opts.binary.isSet() && providerName != "binary" {
+		return fmt.Errorf("--sha256, --member, --dir, and --latest-from are only supported by the binary provider")
+	}
+	if len(opts.scripts) > 0 && providerName != "script" {
+		return fmt.Errorf("scripts are only supported by the script provider")
+	}
+	for _, option := range opts.options {
+		if !strings.HasPrefix(option, "-") {
+			return fmt.Errorf("invalid option '%s' (options must start with '-')", option)
+		}
+	}
+
+	p, err := provider.New(providerName)
+	if err != nil {
+		return err
+	}
+	if _, ok := p.(provider.OptionsInstaller); len(opts.options) > 0 && !ok {
+		return fmt.Errorf("--opt is not supported by provider '%s'", providerName)
+	}
 
+	// The ID and name given on the command line; the provider derives the registered ones from them
+	name, id := packageName, opts.id
 	switch providerName {
 	case "brew":
-		brewProvider := provider.NewBrewProvider()
-		p = brewProvider
-		// For brew, detect package type and generate ID in format "{formula,cask,tap}:<package_name>"
-		generatedID, err := brewProvider.GeneratePackageID(packageName)
-		if err != nil {
-			return fmt.Errorf("error detecting package type: %w", err)
+		if opts.url != "" {
+			// A tap with a custom remote cannot be detected before it is tapped
+			if err := provider.ValidateBrewTapName(packageName); err != nil {
+				return err
+			}
+			if id != "" && id != "tap:"+packageName {
+				return fmt.Errorf("--url is only supported for brew taps (got --id %s)", id)
+			}
+			id = "tap:" + packageName
 		}
-		finalID = generatedID
-		finalName = packageName
 	case "mas":
-		masProvider := provider.NewMasProvider()
-		p = masProvider
-		// For mas, if --id is not provided, search and let user select
-		if packageID == "" {
-			// Search for packages
-			results, err := masProvider.SearchPackage(packageName)
-			if err != nil {
-				return fmt.Errorf("error searching packages: %w", err)
-			}
-
-			if len(results) == 0 {
-				return fmt.Errorf("no packages found for query '%s'", packageName)
+		// Without --id, search and let the user select
+		if id == "" {
+			if id, name, err = selectMasPackage(ctx, p, packageName); err != nil {
+				return err
 			}
-
-			// If only one result, use it automatically
-			if len(results) == 1 {
-				finalID = results[0].ID
-				finalName = results[0].Name
-				if finalName == "" {
-					finalName = packageName
-				}
-			} else {
-				// Multiple results, let user select with UI
-				model := ui.NewSearchResultSelectModel(results, fmt.Sprintf("Select package (found %d package(s) for query '%s')", len(results), packageName))
-				p := tea.NewProgram(model)
-				if _, err := p.Run(); err != nil {
-					return fmt.Errorf("error running UI: %w", err)
-				}
-
-				selected := model.GetSelected()
-				if selected == nil {
-					return fmt.Errorf("package selection is required")
-				}
-
-				finalID = selected.ID
-				finalName = selected.Name
-				if finalName == "" {
-					finalName = packageName
-				}
+		}
+	case "git":
+		// The ID is the destination path of the clone
+		if opts.git.path != "" {
+			id = opts.git.path
+		}
+		if id == "" {
+			return fmt.Errorf("a git package needs --path (the destination of the clone)")
+		}
+	case "binary":
+		if opts.binary.sha256 != "" {
+			if err := provider.ValidateBinaryChecksum(opts.binary.sha256); err != nil {
+				return err
 			}
-		} else {
-			// --id is provided
-			finalID = packageID
-			finalName = packageName
 		}
-	case "manual":
-		manualProvider := provider.NewManualProvider()
-		p = manualProvider
-		// For manual, use package name as ID
-		finalID = packageName
-		finalName = packageName
-	default:
-		return fmt.Errorf("unsupported provider: %s", providerName)
+		if _, err := download.NewVersionSource(opts.binary.latestFrom, opts.url); err != nil {
+			return err
+		}
+	}
+	finalID, finalName, err := provider.ResolvePackage(ctx, p, name, id)
+	if err != nil {
+		return fmt.Errorf("error resolving package: %w", err)
+	}
+	if providerName == "script" {
+		if err := writePackageScripts(finalID, opts.scripts); err != nil {
+			return err
+		}
 	}
 
 	// Check if package already exists in config
@@ -264,17 +325,81 @@ func runPackageAdd(packageName, providerName, profile, version, description, pac
 		return fmt.Errorf("error loading packages config: %w", err)
 	}
 
+	url, options, version, git, binary := opts.url, opts.options, opts.version, opts.git, opts.binary
 	packageExists := false
+	global := false
 	for _, existingPkg := range packagesConfig.Packages {
 		if existingPkg.ID == finalID && existingPkg.Provider == providerName && existingPkg.Profile == profile {
 			packageExists = true
+			if url == "" {
+				url = existingPkg.URL
+			}
+			if options == nil {
+				options = existingPkg.Options
+			}
+			if git.ref == "" {
+				git.ref = existingPkg.Ref
+			}
+			global = existingPkg.Global
+			if providerName == "binary" {
+				if version == "" {
+					version = existingPkg.Version
+				}
+				if binary.sha256 == "" {
+					binary.sha256 = existingPkg.SHA256
+				}
+				if binary.member == "" {
+					binary.member = existingPkg.Member
+				}
+				if binary.dir == "" {
+					binary.dir = existingPkg.Dir
+				}
+				if binary.latestFrom == "" {
+					binary.latestFrom = existingPkg.LatestFrom
+				}
+			}
 			break
 		}
 	}
 
 	// Install the package only if it doesn't exist in config
 	if !packageExists {
-		if err := p.InstallPackage(finalID); err != nil {
+		logs := oplog.StartOrWarn("add")
+		logs.Attach(p, finalName)
+		var err error
+		switch pp := p.(type) {
+		case *provider.BrewProvider:
+			// A formula or cask from a tap needs the tap first
+			err = ensureTapDependency(ctx, pp, finalID, profile, true)
+			if err == nil && url != "" {
+				err = pp.InstallTap(ctx, packageName, url)
+			} else if err == nil {
+				err = provider.InstallPackage(ctx, p, finalID, options)
+			}
+		case *provider.GitProvider:
+			err = pp.Clone(ctx, url, finalID, git.ref)
+		case *provider.BinaryProvider:
+			// The installed version is recorded, as the binary provider installs at that version
+			version, err = pp.InstallBinary(ctx, config.PackageConfig{
+				ID:         finalID,
+				URL:        url,
+				Version:    version,
+				SHA256:     binary.sha256,
+				Member:     binary.member,
+				Dir:        binary.dir,
+				LatestFrom: binary.latestFrom,
+			})
+		default:
+			err = provider.InstallPackage(ctx, p, finalID, options)
+		}
+		logs.Close()
+		if err != nil {
+			if providerName == "script" {
+				// Scripts of a package that could not be added cannot be edited; drop them unless another profile uses them
+				if shared, _ := config.SamePackageInOtherProfile(finalID, providerName, profile); !shared {
+					config.RemoveScriptPackageDir(finalID)
+				}
+			}
 			return fmt.Errorf("error installing package: %w", err)
 		}
 	} else {
@@ -288,7 +413,15 @@ func runPackageAdd(packageName, providerName, profile, version, description, pac
 		Provider:    providerName,
 		Profile:     profile,
 		Version:     version,
-		Description: description,
+		Description: opts.description,
+		URL:         url,
+		Ref:         git.ref,
+		SHA256:      binary.sha256,
+		Member:      binary.member,
+		Dir:         binary.dir,
+		LatestFrom:  binary.latestFrom,
+		Global:      global,
+		Options:     options,
 	}
 
 	// Add or update package in config
@@ -296,6 +429,12 @@ func runPackageAdd(packageName, providerName, profile, version, description, pac
 		return fmt.Errorf("error adding package: %w", err)
 	}
 
+	if git.source != "" {
+		if err := writeGitSourceSnippets(finalID, git.source); err != nil {
+			return err
+		}
+	}
+
 	if packageExists {
 		fmt.Printf("Package '%s' (ID: %s) has been successfully updated in profile '%s' with provider '%s'\n", finalName, finalID, profile, providerName)
 	} else {
@@ -304,7 +443,37 @@ func runPackageAdd(packageName, providerName, profile, version, description, pac
 	return nil
 }
 
-func runPackageAddInteractive(packageName, provider, profile, stage, version, description, packageID string) error {
+// selectMasPackage searches the App Store for query and returns the ID and name of the app: the only
+// result, or the one the user selects
+func selectMasPackage(ctx context.Context, p provider.Provider, query string) (string, string, error) {
+	results, err := p.SearchPackage(ctx, query)
+	if err != nil {
+		return "", "", fmt.Errorf("error searching packages: %w", err)
+	}
+	if len(results) == 0 {
+		return "", "", fmt.Errorf("no packages found for query '%s'", query)
+	}
+
+	selected := &results[0]
+	if len(results) > 1 {
+		// Multiple results, let user select with UI
+		model := ui.NewSearchResultSelectModel(results, fmt.Sprintf("Select package (found %d package(s) for query '%s')", len(results), query))
+		if _, err := tea.NewProgram(model).Run(); err != nil {
+			return "", "", fmt.Errorf("error running UI: %w", err)
+		}
+		if selected = model.GetSelected(); selected == nil {
+			return "", "", fmt.Errorf("package selection is required")
+		}
+	}
+
+	name := selected.Name
+	if name == "" {
+		name = query
+	}
+	return selected.ID, name, nil
+}
+
+func runPackageAddInteractive(ctx context.Context, packageName, stage string, opts packageAddOptions) error {
 	scanner := bufio.NewScanner(os.Stdin)
 
 	// Get package name (if not provided)
@@ -322,7 +491,7 @@ func runPackageAddInteractive(packageName, provider, profile, stage, version, de
 	}
 
 	// Get provider
-	if provider == "" {
+	if opts.provider == "" {
 		selectedProvider, err := selectProviderUI()
 		if err != nil {
 			return err
@@ -330,9 +499,9 @@ func runPackageAddInteractive(packageName, provider, profile, stage, version, de
 		if selectedProvider == "" {
 			return fmt.Errorf("provider is required")
 		}
-		provider = selectedProvider
+		opts.provider = selectedProvider
 	} else {
-		fmt.Printf("Provider: %s\n", provider)
+		fmt.Printf("Provider: %s\n", opts.provider)
 	}
 
 	// Load app config for defaults
@@ -342,7 +511,7 @@ func runPackageAddInteractive(packageName, provider, profile, stage, version, de
 	}
 
 	// Get profile
-	if profile == "" {
+	if opts.profile == "" {
 		selectedProfile, err := selectProfileUI()
 		if err != nil {
 			return err
@@ -350,13 +519,13 @@ func runPackageAddInteractive(packageName, provider, profile, stage, version, de
 		if selectedProfile == "" {
 			return fmt.Errorf("profile is required")
 		}
-		profile = selectedProfile
+		opts.profile = selectedProfile
 	} else {
-		fmt.Printf("Profile: %s\n", profile)
+		fmt.Printf("Profile: %s\n", opts.profile)
 	}
 
 	// Build final profile name from profile and stage
-	finalProfile, err := buildProfileName(profile, stage, appConfig.DefaultProfile, appConfig.DefaultStage)
+	finalProfile, err := buildProfileName(opts.profile, stage, appConfig.DefaultProfile, appConfig.DefaultStage)
 	if err != nil {
 		return fmt.Errorf("error building profile name: %w", err)
 	}
@@ -371,31 +540,31 @@ func runPackageAddInteractive(packageName, provider, profile, stage, version, de
 	}
 	
 	// Update profile to the actual profile name found
-	profile = profileConfig.Name
+	opts.profile = profileConfig.Name
 
 	// Get version
-	if version == "" {
+	if opts.version == "" {
 		fmt.Print("Version (optional, press Enter to skip): ")
 		if !scanner.Scan() {
 			return fmt.Errorf("failed to read input")
 		}
-		version = strings.TrimSpace(scanner.Text())
+		opts.version = strings.TrimSpace(scanner.Text())
 	} else {
-		fmt.Printf("Version: %s\n", version)
+		fmt.Printf("Version: %s\n", opts.version)
 	}
 
 	// Get description
-	if description == "" {
+	if opts.description == "" {
 		fmt.Print("Description (optional, press Enter to skip): ")
 		if !scanner.Scan() {
 			return fmt.Errorf("failed to read input")
 		}
-		description = strings.TrimSpace(scanner.Text())
+		opts.description = strings.TrimSpace(scanner.Text())
 	} else {
-		fmt.Printf("Description: %s\n", description)
+		fmt.Printf("Description: %s\n", opts.description)
 	}
 
-	return runPackageAdd(packageName, provider, profile, version, description, packageID)
+	return runPackageAdd(ctx, packageName, opts)
 }
 
 // selectProviderUI allows selection of a provider with UI
@@ -411,11 +580,19 @@ func selectProviderUI() (string, error) {
 		return "", fmt.Errorf("error loading providers config: %w", err)
 	}
 
-	if len(providersConfig.Providers) == 0 {
+	// providers.json may list providers added on another operating system
+	var providers []config.ProviderConfig
+	for _, providerConfig := range providersConfig.Providers {
+		if provider.SupportsHost(providerConfig.Name) {
+			providers = append(providers, providerConfig)
+		}
+	}
+
+	if len(providers) == 0 {
 		return "", fmt.Errorf("no providers available. Please add a provider first using 'al provider add'")
 	}
 
-	model := ui.NewProviderSelectModel(providersConfig.Providers, "Provider", appConfig.DefaultProvider)
+	model := ui.NewProviderSelectModel(providers, "Provider", appConfig.DefaultProvider)
 	p := tea.NewProgram(model)
 	if _, err := p.Run(); err != nil {
 		return "", fmt.Errorf("error running TUI: %w", err)
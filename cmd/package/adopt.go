@@ -0,0 +1,134 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// NewPackageAdoptCmd creates the package adopt command
+func NewPackageAdoptCmd() *cobra.Command {
+	var providerName string
+	var profile string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "adopt [package-name...]",
+		Short: "Register packages that are already installed",
+		Long: `Register packages installed with a provider's package manager but not yet registered for that provider
+(e.g. tools installed with pipx or uv tool before al) in a profile, without installing anything.
+Without package names, every unregistered package is adopted.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackageAdopt(cmd.Context(), providerName, profile, args, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (required)")
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Profile name (profile_name, or full profile_name.stage_name; default: default profile)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be registered without writing packages.json")
+	cmd.MarkFlagRequired("provider")
+
+	return cmd
+}
+
+func runPackageAdopt(ctx context.Context, providerName, profile string, names []string, dryRun bool) error {
+	appConfig, err := config.LoadAppConfig()
+	if err != nil {
+		return fmt.Errorf("error loading app config: %w", err)
+	}
+	finalProfile, err := buildProfileName(profile, "", appConfig.DefaultProfile, appConfig.DefaultStage)
+	if err != nil {
+		return fmt.Errorf("error building profile name: %w", err)
+	}
+	if finalProfile == "" {
+		return fmt.Errorf("profile is required. Use --profile or set default profile with 'al config set --default-profile <profile>'")
+	}
+	profileConfig, err := findProfileWithFallback(finalProfile, "")
+	if err != nil {
+		return fmt.Errorf("error loading profile: %w", err)
+	}
+	if profileConfig == nil {
+		return fmt.Errorf("profile '%s' does not exist", finalProfile)
+	}
+	finalProfile = profileConfig.Name
+
+	if pc, _ := config.GetProvider(providerName); pc == nil {
+		return fmt.Errorf("provider '%s' is not added. Add it first with 'al provider add %s'", providerName, providerName)
+	}
+	p, err := provider.New(providerName)
+	if err != nil {
+		return err
+	}
+
+	installed, err := p.ListInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing installed packages: %w", err)
+	}
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+	// Packages registered for the provider in any profile are already managed
+	registered := make(map[string]bool)
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider == providerName {
+			registered[provider.NormalizeID(p, pkg.ID)] = true
+		}
+	}
+
+	wanted := make(map[string]bool)
+	for _, name := range names {
+		wanted[provider.NormalizeID(p, name)] = true
+	}
+
+	adopted := 0
+	for _, pkg := range installed {
+		key := provider.NormalizeID(p, pkg.ID)
+		if len(wanted) > 0 {
+			if !wanted[key] {
+				continue
+			}
+			delete(wanted, key)
+		}
+		if registered[key] {
+			if len(names) > 0 {
+				fmt.Printf("Package '%s' is already registered for provider '%s'\n", pkg.Name, providerName)
+			}
+			continue
+		}
+
+		if dryRun {
+			fmt.Printf("Would adopt %s (%s:%s) %s\n", pkg.Name, providerName, pkg.ID, pkg.Version)
+			adopted++
+			continue
+		}
+		newPkg := config.PackageConfig{
+			ID:          pkg.ID,
+			Name:        pkg.Name,
+			Provider:    providerName,
+			Profile:     finalProfile,
+			Version:     pkg.Version,
+			InstalledAt: time.Now(),
+		}
+		if err := config.AddPackage(newPkg); err != nil {
+			return fmt.Errorf("error adding package to config: %w", err)
+		}
+		fmt.Printf("Adopted %s (%s:%s) in profile '%s'\n", pkg.Name, providerName, pkg.ID, finalProfile)
+		adopted++
+	}
+
+	for _, name := range names {
+		if wanted[provider.NormalizeID(p, name)] {
+			fmt.Printf("Package '%s' is not installed with provider '%s'\n", name, providerName)
+		}
+	}
+	if adopted == 0 {
+		fmt.Println("No packages to adopt.")
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package packagecmd
+
+// binaryFlags are the package add flags of a binary package
+type binaryFlags struct {
+	sha256     string
+	member     string
+	dir        string
+	latestFrom string
+}
+
+// isSet reports whether any binary flag is given
+func (f binaryFlags) isSet() bool {
+	return f.sha256 != "" || f.member != "" || f.dir != "" || f.latestFrom != ""
+}
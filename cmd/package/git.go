@@ -0,0 +1,206 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// gitFlags are the package add flags of a git package
+type gitFlags struct {
+	path   string
+	ref    string
+	source string
+}
+
+// isSet reports whether any git flag is given
+func (f gitFlags) isSet() bool {
+	return f.path != "" || f.ref != "" || f.source != ""
+}
+
+// gitEntry is a registered git package with the state of its clone
+type gitEntry struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	URL      string   `json:"url,omitempty"`
+	Ref      string   `json:"ref,omitempty"`
+	Profiles []string `json:"profiles"`
+	Cloned   bool     `json:"cloned"`
+	*provider.GitStatus
+}
+
+// gitColumns are the TSV columns of git status output
+var gitColumns = []string{"name", "path", "url", "ref", "profiles", "cloned", "head", "branch", "target", "dirty", "ahead", "behind"}
+
+// writeGitSourceSnippets writes shell.d snippets that source a file of a git package's clone, so that
+// `al activate` loads a cloned shell plugin. A .zsh or .bash file is sourced only by that shell.
+func writeGitSourceSnippets(id, file string) error {
+	path := filepath.Join(id, file)
+	if strings.HasPrefix(path, "~/") {
+		path = "$HOME" + strings.TrimPrefix(path, "~")
+	}
+	content := fmt.Sprintf("source \"%s\"", path)
+
+	exts := []string{".zsh", ".bash"}
+	if ext := filepath.Ext(file); ext == ".zsh" || ext == ".bash" {
+		exts = []string{ext}
+	}
+	for _, ext := range exts {
+		if err := config.WriteShellSnippet(id, "git", ext, content); err != nil {
+			return fmt.Errorf("error writing %s snippet: %w", ext, err)
+		}
+	}
+	return nil
+}
+
+// NewPackageGitCmd creates the package git command
+func NewPackageGitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "git",
+		Short: "Check the clones of git packages",
+		Long:  "Check the clones of registered git packages: uncommitted changes and commits ahead of and behind their upstream or ref.",
+	}
+
+	cmd.AddCommand(newGitStatusCmd())
+
+	return cmd
+}
+
+func newGitStatusCmd() *cobra.Command {
+	var profile string
+	var fetch bool
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
+		Use:   "status [package-name]",
+		Short: "Show the state of git package clones",
+		Long: `Show the number of changed files (dirty) and the commits ahead of and behind the upstream branch,
+or the ref of the package, for a registered git package or every registered git package.
+The counts are against the last fetch; use --fetch to fetch origin first.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var packageName string
+			if len(args) > 0 {
+				packageName = args[0]
+			}
+			return runGitStatus(cmd.Context(), packageName, profile, fetch, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only show packages in this profile")
+	cmd.Flags().BoolVar(&fetch, "fetch", false, "Fetch origin before counting the commits behind")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
+}
+
+// collectGitEntries merges the registered git packages named packageName (all when empty) in
+// profile (all when empty) into one entry per clone
+func collectGitEntries(packages []config.PackageConfig, packageName, profile string) []gitEntry {
+	index := make(map[string]int)
+	var entries []gitEntry
+	for _, pkg := range packages {
+		if pkg.Provider != "git" {
+			continue
+		}
+		if packageName != "" && pkg.Name != packageName {
+			continue
+		}
+		if profile != "" && pkg.Profile != profile {
+			continue
+		}
+		i, ok := index[pkg.ID]
+		if !ok {
+			i = len(entries)
+			index[pkg.ID] = i
+			entries = append(entries, gitEntry{Name: pkg.Name, Path: pkg.ID, URL: pkg.URL, Ref: pkg.Ref})
+		}
+		entries[i].Profiles = append(entries[i].Profiles, pkg.Profile)
+	}
+
+	for i := range entries {
+		sort.Strings(entries[i].Profiles)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+func runGitStatus(ctx context.Context, packageName, profile string, fetch bool, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	entries := collectGitEntries(packagesConfig.Packages, packageName, profile)
+	if packageName != "" && len(entries) == 0 {
+		return fmt.Errorf("git package '%s' not found", packageName)
+	}
+
+	git := provider.NewGitProvider()
+	for i := range entries {
+		entry := &entries[i]
+		dir, err := provider.GitPath(entry.Path)
+		if err != nil {
+			return err
+		}
+		if entry.Cloned = provider.IsGitClone(dir); !entry.Cloned {
+			continue
+		}
+		if fetch {
+			if err := git.Fetch(ctx, entry.Path); err != nil {
+				return err
+			}
+		}
+		if entry.GitStatus, err = git.Status(ctx, entry.Path, entry.Ref); err != nil {
+			return err
+		}
+	}
+
+	shown := entries
+	if shown == nil {
+		shown = []gitEntry{}
+	}
+	return output.Print(outputOpts, output.Result{
+		Data:    shown,
+		Columns: gitColumns,
+		Table: func(w io.Writer) error {
+			if len(shown) == 0 {
+				fmt.Fprintln(w, "No git packages found")
+				return nil
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tPATH\tHEAD\tTARGET\tDIRTY\tAHEAD\tBEHIND")
+			for _, entry := range shown {
+				if !entry.Cloned {
+					fmt.Fprintf(tw, "%s\t%s\tnot cloned\t-\t-\t-\t-\n", entry.Name, entry.Path)
+					continue
+				}
+				head := entry.Head
+				if entry.Branch != "" {
+					head = entry.Branch + "@" + entry.Head
+				}
+				target := entry.Target
+				if target == "" {
+					target = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\n", entry.Name, entry.Path, head, target, entry.Dirty, entry.Ahead, entry.Behind)
+			}
+			return tw.Flush()
+		},
+	})
+}
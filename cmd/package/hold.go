@@ -0,0 +1,204 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/kkato1030/al/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// NewPackageHoldCmd creates the package hold command
+func NewPackageHoldCmd() *cobra.Command {
+	var profile string
+	var providerName string
+	var manual bool
+	var constraint string
+
+	cmd := &cobra.Command{
+		Use:   "hold <package-name>",
+		Short: "Hold a package back from upgrades",
+		Long: `Set the upgrade policy of a package to hold: it is never upgraded (brew formulae are also pinned with 'brew pin').
+With --manual, the package is upgraded only when it is named explicitly ('al package upgrade <package-name>').
+With --constraint, the policy is left unchanged and upgrades are limited to versions that satisfy the constraint
+(e.g. '20' for 20.x, '>=20, <22').
+The setting applies to the package in every profile unless --profile or --provider is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if manual && constraint != "" {
+				return fmt.Errorf("--manual and --constraint cannot be used together")
+			}
+			return runPackageHold(cmd.Context(), args[0], profile, providerName, manual, constraint)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only hold the package in this profile")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Only hold the package of this provider")
+	cmd.Flags().BoolVar(&manual, "manual", false, "Upgrade the package only when it is named explicitly")
+	cmd.Flags().StringVar(&constraint, "constraint", "", "Limit upgrades to versions satisfying this constraint")
+
+	return cmd
+}
+
+// NewPackageUnholdCmd creates the package unhold command
+func NewPackageUnholdCmd() *cobra.Command {
+	var profile string
+	var providerName string
+
+	cmd := &cobra.Command{
+		Use:   "unhold <package-name>",
+		Short: "Allow upgrades of a held package",
+		Long:  "Set the upgrade policy of a package back to auto and clear its version constraint (brew formulae are unpinned).",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackageUnhold(cmd.Context(), args[0], profile, providerName)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only unhold the package in this profile")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Only unhold the package of this provider")
+
+	return cmd
+}
+
+func runPackageHold(ctx context.Context, packageName, profile, providerName string, manual bool, constraint string) error {
+	if constraint != "" {
+		if _, err := version.ParseConstraint(constraint); err != nil {
+			return err
+		}
+	}
+
+	policy := config.UpgradeHold
+	if manual {
+		policy = config.UpgradeManual
+	}
+
+	changed, err := updatePackageEntries(packageName, profile, providerName, func(pkg *config.PackageConfig) {
+		if constraint != "" {
+			pkg.Constraint = constraint
+		} else {
+			pkg.Upgrade = policy
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range changed {
+		switch {
+		case constraint != "":
+			fmt.Printf("Package '%s' (%s:%s) in profile '%s' is now limited to versions matching '%s'\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile, constraint)
+		default:
+			fmt.Printf("Package '%s' (%s:%s) in profile '%s' is now set to upgrade policy '%s'\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile, policy)
+		}
+	}
+
+	// Holding pins the package; a manual policy or a constraint lets al upgrade it again, so it is
+	// unpinned unless another entry still holds it
+	pinPackages(ctx, changed, policy == config.UpgradeHold && constraint == "")
+	return nil
+}
+
+func runPackageUnhold(ctx context.Context, packageName, profile, providerName string) error {
+	changed, err := updatePackageEntries(packageName, profile, providerName, func(pkg *config.PackageConfig) {
+		pkg.Upgrade = ""
+		pkg.Constraint = ""
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, pkg := range changed {
+		fmt.Printf("Package '%s' (%s:%s) in profile '%s' is now upgraded automatically\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile)
+	}
+
+	pinPackages(ctx, changed, false)
+	return nil
+}
+
+// updatePackageEntries applies update to every registered entry of packageName (optionally limited
+// to a profile and provider), saves packages.json, and returns the updated entries
+func updatePackageEntries(packageName, profile, providerName string, update func(pkg *config.PackageConfig)) ([]config.PackageConfig, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	var changed []config.PackageConfig
+	for i := range packagesConfig.Packages {
+		pkg := &packagesConfig.Packages[i]
+		if pkg.Name != packageName {
+			continue
+		}
+		if profile != "" && pkg.Profile != profile {
+			continue
+		}
+		if providerName != "" && pkg.Provider != providerName {
+			continue
+		}
+		update(pkg)
+		changed = append(changed, *pkg)
+	}
+
+	if len(changed) == 0 {
+		return nil, fmt.Errorf("package '%s' not found", packageName)
+	}
+
+	if err := config.SavePackagesConfig(packagesConfig); err != nil {
+		return nil, fmt.Errorf("error saving packages config: %w", err)
+	}
+	return changed, nil
+}
+
+// pinPackages pins or unpins packages with providers that support it. A package registered in
+// several profiles is only pinned once; it is only unpinned when no other entry still holds it.
+func pinPackages(ctx context.Context, packages []config.PackageConfig, pin bool) {
+	done := make(map[string]bool)
+	for _, pkg := range packages {
+		key := pkg.Provider + ":" + pkg.ID
+		if done[key] {
+			continue
+		}
+		done[key] = true
+
+		p, err := provider.New(pkg.Provider)
+		if err != nil {
+			continue
+		}
+		pinner, ok := p.(provider.Pinner)
+		if !ok {
+			continue
+		}
+		if installed, err := p.CheckInstalled(ctx); err != nil || !installed {
+			continue
+		}
+
+		if pin {
+			err = pinner.Pin(ctx, pkg.ID)
+		} else {
+			if stillHeld(pkg) {
+				continue
+			}
+			err = pinner.Unpin(ctx, pkg.ID)
+		}
+		if err != nil {
+			fmt.Printf("Warning: %v\n", err)
+		}
+	}
+}
+
+// stillHeld reports whether another registered entry of the same package still has the hold policy
+func stillHeld(pkg config.PackageConfig) bool {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return false
+	}
+	for _, other := range packagesConfig.Packages {
+		if other.ID == pkg.ID && other.Provider == pkg.Provider && other.UpgradePolicy() == config.UpgradeHold {
+			return true
+		}
+	}
+	return false
+}
@@ -3,11 +3,13 @@ package packagecmd
 import (
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/kkato1030/al/internal/brewfile"
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/oplog"
 	"github.com/kkato1030/al/internal/provider"
 	"github.com/spf13/cobra"
 )
@@ -22,9 +24,9 @@ func NewPackageImportCmd() *cobra.Command {
 	var verbose bool
 
 	cmd := &cobra.Command{
-		Use:   "import [Brewfile]",
-		Short: "Import packages from a Brewfile",
-		Long:  "Parse a Brewfile (tap, brew, cask, mas) and register packages to a profile. By default only registers; use --install to install missing packages.",
+		Use:   "import [Brewfile|packages.json]",
+		Short: "Import packages from a Brewfile or an npm package list",
+		Long:  "Parse a Brewfile (tap, brew, cask, mas, cargo, go, vscode, flatpak) or a package.json-style list of global npm packages (a .json file) and register packages to a profile. By default only registers; use --install to install missing packages (packages of providers for another operating system, e.g. flatpak on macOS, are only registered).",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			var brewfilePath string
@@ -67,52 +69,62 @@ func NewPackageImportCmd() *cobra.Command {
 			}
 			finalProfile = profileConfig.Name
 
-			result, err := brewfile.ParseFile(brewfilePath)
+			result, err := brewfile.ParseImportFile(brewfilePath)
 			if err != nil {
-				return fmt.Errorf("parse Brewfile: %w", err)
+				return fmt.Errorf("parse %s: %w", brewfilePath, err)
 			}
+			// Taps first, so that formulae and casks from a tap find it registered (and tapped)
+			sort.SliceStable(result.Entries, func(i, j int) bool {
+				return strings.HasPrefix(result.Entries[i].ID, "tap:") && !strings.HasPrefix(result.Entries[j].ID, "tap:")
+			})
 
-			needBrew := false
-			needMas := false
+			// Every provider used by the file must be added first, except providers for another
+			// operating system: their packages are registered for the machines that run them
+			var providerNames []string
+			seen := make(map[string]bool)
 			for _, e := range result.Entries {
-				if e.Provider == "brew" {
-					needBrew = true
-				}
-				if e.Provider == "mas" {
-					needMas = true
+				if !seen[e.Provider] {
+					seen[e.Provider] = true
+					providerNames = append(providerNames, e.Provider)
 				}
 			}
-			if needBrew {
-				pc, _ := config.GetProvider("brew")
-				if pc == nil {
-					return fmt.Errorf("provider 'brew' is required for this Brewfile. Add it first with 'al provider add brew'")
+			sort.Strings(providerNames)
+			for _, name := range providerNames {
+				if !provider.SupportsHost(name) {
+					continue
 				}
-			}
-			if needMas {
-				pc, _ := config.GetProvider("mas")
+				pc, _ := config.GetProvider(name)
 				if pc == nil {
-					return fmt.Errorf("provider 'mas' is required for this Brewfile. Add it first with 'al provider add mas'")
+					return fmt.Errorf("provider '%s' is required for this file. Add it first with 'al provider add %s'", name, name)
 				}
 			}
 
 			if verbose && len(result.Skipped) > 0 {
 				for _, s := range result.Skipped {
+					if s.LineNum == 0 {
+						// Entries of an npm package list have no line number
+						fmt.Fprintf(os.Stderr, "Skipped (%s): %s\n", s.Reason, strings.TrimSpace(s.Line))
+						continue
+					}
 					fmt.Fprintf(os.Stderr, "Skipped line %d (%s): %s\n", s.LineNum, s.Reason, strings.TrimSpace(s.Line))
 				}
 			}
 
 			if dryRun {
 				fmt.Printf("Would import %d packages to profile '%s'\n", len(result.Entries), finalProfile)
-				brewCount, masCount := 0, 0
+				counts := make(map[string]int)
 				for _, e := range result.Entries {
-					if e.Provider == "brew" {
-						brewCount++
-					} else {
-						masCount++
+					counts[e.Provider]++
+					details := ""
+					if len(e.Options) > 0 {
+						details += fmt.Sprintf(" [options: %s]", strings.Join(e.Options, " "))
+					}
+					if e.Service != "" {
+						details += fmt.Sprintf(" [service: %s]", e.Service)
 					}
-					fmt.Printf("  %s %s (%s)\n", e.Provider, e.ID, e.Name)
+					fmt.Printf("  %s %s (%s)%s\n", e.Provider, e.ID, e.Name, details)
 				}
-				fmt.Printf("  brew: %d, mas: %d\n", brewCount, masCount)
+				fmt.Printf("  %s\n", formatProviderCounts(providerNames, counts))
 				if len(result.Skipped) > 0 {
 					fmt.Printf("Skipped %d lines (use --verbose to see details).\n", len(result.Skipped))
 				}
@@ -130,21 +142,38 @@ func NewPackageImportCmd() *cobra.Command {
 				existing[key] = true
 			}
 
-			var brewProv provider.Provider
-			var masProv provider.Provider
-			if needBrew {
-				brewProv = provider.NewBrewProvider()
+			var brewProv *provider.BrewProvider
+			providers := make(map[string]provider.Provider)
+			for _, name := range providerNames {
+				if name == "brew" {
+					brewProv = provider.NewBrewProvider()
+					providers[name] = brewProv
+					continue
+				}
+				p, err := provider.New(name)
+				if err != nil {
+					return err
+				}
+				providers[name] = p
 			}
-			if needMas {
-				masProv = provider.NewMasProvider()
+
+			var logs *oplog.Run
+			if install {
+				logs = oplog.StartOrWarn("import")
+				defer logs.Close()
 			}
 
 			imported := 0
 			skipped := 0
-			brewImported := 0
-			masImported := 0
+			importedCounts := make(map[string]int)
 
+			ctx := cmd.Context()
 			for _, e := range result.Entries {
+				if ctx.Err() != nil {
+					// Interrupted: packages imported so far stay registered, the rest are not
+					fmt.Printf("Import interrupted: imported %d packages (%s)\n", imported, formatProviderCounts(providerNames, importedCounts))
+					return ctx.Err()
+				}
 				key := e.Provider + ":" + finalProfile + ":" + e.ID
 				if existing[key] && !overwrite {
 					skipped++
@@ -152,14 +181,35 @@ func NewPackageImportCmd() *cobra.Command {
 				}
 
 
-				if install {
+				if e.Provider == "brew" && brewProv != nil {
+					// A formula or cask from a tap needs the tap, even if the Brewfile does not list it
+					logs.Attach(brewProv, e.Name)
+					if err := ensureTapDependency(ctx, brewProv, e.ID, finalProfile, install); err != nil {
+						return fmt.Errorf("import %s: %w", e.ID, err)
+					}
+				}
+
+				if install && provider.SupportsHost(e.Provider) {
 					if e.Provider == "brew" && brewProv != nil {
-						if err := brewProv.InstallPackage(e.ID); err != nil {
+						var err error
+						if e.URL != "" {
+							err = brewProv.InstallTap(ctx, e.Name, e.URL)
+						} else {
+							err = brewProv.InstallPackageWithOptions(ctx, e.ID, e.Options)
+						}
+						if err != nil {
 							return fmt.Errorf("install %s: %w", e.ID, err)
 						}
+						// restart_service: starts the formula's service once it is installed
+						if e.Service == config.ServiceRun {
+							if err := brewProv.StartService(ctx, e.ID); err != nil {
+								fmt.Printf("Warning: %v (start it later with 'al package service sync')\n", err)
+							}
+						}
 					}
-					if e.Provider == "mas" && masProv != nil {
-						if err := masProv.InstallPackage(e.ID); err != nil {
+					if p := providers[e.Provider]; e.Provider != "brew" && p != nil {
+						logs.Attach(p, e.Name)
+						if err := provider.InstallPackage(ctx, p, e.ID, e.Options); err != nil {
 							return fmt.Errorf("install %s: %w", e.ID, err)
 						}
 					}
@@ -171,6 +221,9 @@ func NewPackageImportCmd() *cobra.Command {
 					Provider:    e.Provider,
 					Profile:     finalProfile,
 					InstalledAt: time.Now(),
+					URL:         e.URL,
+					Options:     e.Options,
+					Service:     e.Service,
 				}
 				if overwrite {
 					if err := config.AddOrUpdatePackage(pkg); err != nil {
@@ -182,21 +235,17 @@ func NewPackageImportCmd() *cobra.Command {
 					}
 				}
 				imported++
-				if e.Provider == "brew" {
-					brewImported++
-				} else {
-					masImported++
-				}
+				importedCounts[e.Provider]++
 				existing[key] = true
 			}
 
-			fmt.Printf("Imported %d packages (brew: %d, mas: %d)", imported, brewImported, masImported)
+			fmt.Printf("Imported %d packages (%s)", imported, formatProviderCounts(providerNames, importedCounts))
 			if skipped > 0 {
 				fmt.Printf(". Skipped %d (already registered)", skipped)
 			}
 			fmt.Println()
 			if len(result.Skipped) > 0 {
-				fmt.Printf("Skipped %d lines (vscode/go/cargo/...). Use --verbose to see details.\n", len(result.Skipped))
+				fmt.Printf("Skipped %d lines (unsupported entries). Use --verbose to see details.\n", len(result.Skipped))
 			}
 			return nil
 		},
@@ -204,10 +253,19 @@ func NewPackageImportCmd() *cobra.Command {
 
 	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Profile to register packages to (required)")
 	cmd.Flags().StringVarP(&stage, "stage", "s", "", "Stage name (optional)")
-	cmd.Flags().BoolVar(&install, "install", false, "Install packages that are not yet installed via brew/mas")
+	cmd.Flags().BoolVar(&install, "install", false, "Install packages that are not yet installed")
 	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be imported without writing")
 	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite existing entries with same id, provider, profile")
 	cmd.Flags().BoolVar(&verbose, "verbose", false, "Show skipped lines (unsupported types)")
 
 	return cmd
 }
+
+// formatProviderCounts formats package counts per provider, e.g. "brew: 3, mas: 1"
+func formatProviderCounts(providerNames []string, counts map[string]int) string {
+	parts := make([]string, len(providerNames))
+	for i, name := range providerNames {
+		parts[i] = fmt.Sprintf("%s: %d", name, counts[name])
+	}
+	return strings.Join(parts, ", ")
+}
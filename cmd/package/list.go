@@ -1,42 +1,53 @@
 package packagecmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"sort"
 	"strings"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/kkato1030/al/internal/query"
 	"github.com/spf13/cobra"
 )
 
+// packageColumns are the TSV columns of package output
+var packageColumns = []string{"name", "id", "provider", "profile", "version", "installed_at", "upgrade", "constraint", "description"}
+
 // NewPackageListCmd creates the package list command
 func NewPackageListCmd() *cobra.Command {
 	var profile string
 	var provider string
+	var where string
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all packages",
-		Long:  "List all configured packages. Optionally filter by profile and/or provider.",
+		Long:  "List all configured packages. Optionally filter by profile and/or provider, or with a --where expression. The table shows whether script packages are installed, running their is-installed scripts.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPackageList(profile, provider)
+			return runPackageList(cmd.Context(), profile, provider, where, outputOpts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Filter packages by profile name")
 	cmd.Flags().StringVarP(&provider, "provider", "p", "", "Filter packages by provider name")
+	addWhereFlag(cmd, &where)
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
 
 	return cmd
 }
 
-func runPackageList(profileFilter, providerFilter string) error {
+func runPackageList(ctx context.Context, profileFilter, providerFilter, where string, outputOpts output.Options) error {
 	packagesConfig, err := config.LoadPackagesConfig()
 	if err != nil {
 		return fmt.Errorf("error loading packages config: %w", err)
 	}
 
 	// Filter packages based on provided flags
-	var filteredPackages []config.PackageConfig
+	filteredPackages := []config.PackageConfig{}
 	for _, pkg := range packagesConfig.Packages {
 		matchesProfile := profileFilter == "" || pkg.Profile == profileFilter
 		matchesProvider := providerFilter == "" || pkg.Provider == providerFilter
@@ -46,11 +57,40 @@ func runPackageList(profileFilter, providerFilter string) error {
 		}
 	}
 
+	filteredPackages, err = query.FilterPackages(where, filteredPackages)
+	if err != nil {
+		return err
+	}
+
+	// Sort for stable output: by profile, provider, then name
+	sort.Slice(filteredPackages, func(i, j int) bool {
+		a, b := filteredPackages[i], filteredPackages[j]
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	})
+
+	return output.Print(outputOpts, output.Result{
+		Data:    filteredPackages,
+		Columns: packageColumns,
+		Table: func(w io.Writer) error {
+			return printPackageTable(w, filteredPackages, profileFilter != "" || providerFilter != "" || where != "", scriptPackageStates(ctx, filteredPackages))
+		},
+	})
+}
+
+// printPackageTable writes packages grouped by profile and provider. states marks packages with
+// their installed state by ID (script packages).
+func printPackageTable(w io.Writer, filteredPackages []config.PackageConfig, filtered bool, states map[string]string) error {
 	if len(filteredPackages) == 0 {
-		if profileFilter != "" || providerFilter != "" {
-			fmt.Println("No packages found matching the specified filters")
+		if filtered {
+			fmt.Fprintln(w, "No packages found matching the specified filters")
 		} else {
-			fmt.Println("No packages configured")
+			fmt.Fprintln(w, "No packages configured")
 		}
 		return nil
 	}
@@ -81,12 +121,12 @@ func runPackageList(profileFilter, providerFilter string) error {
 	}
 	sort.Strings(profiles)
 
-	fmt.Println("Configured packages:")
+	fmt.Fprintln(w, "Configured packages:")
 	for i, profileName := range profiles {
 		if i > 0 {
-			fmt.Println()
+			fmt.Fprintln(w)
 		}
-		fmt.Printf("%s\n", profileName)
+		fmt.Fprintf(w, "%s\n", profileName)
 
 		providers := make([]string, 0, len(grouped[profileName]))
 		for provider := range grouped[profileName] {
@@ -105,11 +145,17 @@ func runPackageList(profileFilter, providerFilter string) error {
 			packageNames := make([]string, len(packages))
 			for idx, pkg := range packages {
 				packageNames[idx] = pkg.Name
+				// Mark packages that are not upgraded automatically
+				if policy := pkg.UpgradePolicy(); policy != config.UpgradeAuto {
+					packageNames[idx] += " (" + policy + ")"
+				}
+				if state, ok := states[pkg.ID]; ok && pkg.Provider == "script" {
+					packageNames[idx] += " [" + state + "]"
+				}
 			}
 
-			fmt.Printf("  %s: %s\n", providerName, strings.Join(packageNames, ", "))
+			fmt.Fprintf(w, "  %s: %s\n", providerName, strings.Join(packageNames, ", "))
 		}
 	}
-
 	return nil
 }
@@ -0,0 +1,230 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// packageStatus is a registered package with the versions reported by its provider
+type packageStatus struct {
+	Name              string `json:"name"`
+	ID                string `json:"id"`
+	Provider          string `json:"provider"`
+	Profile           string `json:"profile"`
+	RegisteredVersion string `json:"registered_version,omitempty"`
+	// Checked is false when the provider cannot report installed packages (e.g. manual, or not installed)
+	Checked        bool   `json:"checked"`
+	Installed      bool   `json:"installed"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	LatestVersion  string `json:"latest_version,omitempty"`
+	Outdated       bool   `json:"outdated"`
+}
+
+// NewPackageOutdatedCmd creates the package outdated command
+func NewPackageOutdatedCmd() *cobra.Command {
+	var profile string
+	var providerName string
+	var all bool
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "Show registered packages with newer versions available",
+		Long:  "Compare registered packages with what their providers report as installed and outdated, and show the current and latest versions. Use --all to show every registered package.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackageOutdated(cmd.Context(), profile, providerName, all, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Filter packages by profile name")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Filter packages by provider name")
+	cmd.Flags().BoolVarP(&all, "all", "a", false, "Show all registered packages, not only outdated ones")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
+}
+
+func runPackageOutdated(ctx context.Context, profileFilter, providerFilter string, all bool, outputOpts output.Options) error {
+	packages, err := loadFilteredPackages(profileFilter, providerFilter)
+	if err != nil {
+		return err
+	}
+
+	statuses := collectPackageStatuses(ctx, packages, true)
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	rows := []packageStatus{}
+	for _, status := range statuses {
+		if all || status.Outdated {
+			rows = append(rows, status)
+		}
+	}
+
+	return output.Print(outputOpts, output.Result{
+		Data:    rows,
+		Columns: []string{"name", "id", "provider", "profile", "installed", "current_version", "latest_version", "outdated"},
+		Table: func(w io.Writer) error {
+			if len(rows) == 0 {
+				if all {
+					fmt.Fprintln(w, "No packages found.")
+				} else {
+					fmt.Fprintln(w, "All packages are up to date.")
+				}
+				return nil
+			}
+
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tPROVIDER\tPROFILE\tCURRENT\tLATEST")
+			for _, row := range rows {
+				current := row.CurrentVersion
+				switch {
+				case !row.Checked:
+					current = "(unknown)"
+				case !row.Installed:
+					current = "(not installed)"
+				}
+				latest := row.LatestVersion
+				if latest == "" && row.Installed && !row.Outdated {
+					latest = row.CurrentVersion
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", row.Name, row.Provider, row.Profile, current, latest)
+			}
+			return tw.Flush()
+		},
+	})
+}
+
+// loadFilteredPackages returns registered packages filtered by profile and provider, sorted by profile, provider, then name
+func loadFilteredPackages(profileFilter, providerFilter string) ([]config.PackageConfig, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	var packages []config.PackageConfig
+	for _, pkg := range packagesConfig.Packages {
+		if profileFilter != "" && pkg.Profile != profileFilter {
+			continue
+		}
+		if providerFilter != "" && pkg.Provider != providerFilter {
+			continue
+		}
+		packages = append(packages, pkg)
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		a, b := packages[i], packages[j]
+		if a.Profile != b.Profile {
+			return a.Profile < b.Profile
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Name < b.Name
+	})
+	return packages, nil
+}
+
+// collectPackageStatuses asks each provider once for its installed (and, if withOutdated is set,
+// outdated) packages and matches them with the registered packages. Providers that cannot be
+// queried are reported as warnings on stderr and their packages are left unchecked.
+func collectPackageStatuses(ctx context.Context, packages []config.PackageConfig, withOutdated bool) []packageStatus {
+	type providerState struct {
+		p         provider.Provider
+		checked   bool
+		installed map[string]provider.InstalledPackage
+		outdated  map[string]provider.OutdatedPackage
+	}
+	states := make(map[string]*providerState)
+
+	stateFor := func(providerName string) *providerState {
+		if state, ok := states[providerName]; ok {
+			return state
+		}
+		state := &providerState{}
+		states[providerName] = state
+
+		// Manual packages are not tracked by any package manager, and packages of providers for
+		// another operating system (e.g. apt in a profile shared with a Mac) are not on this machine
+		if providerName == "manual" || !provider.SupportsHost(providerName) {
+			return state
+		}
+		p, err := provider.New(providerName)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v, skipping its packages\n", err)
+			return state
+		}
+		if installed, err := p.CheckInstalled(ctx); err != nil || !installed {
+			fmt.Fprintf(os.Stderr, "Warning: provider '%s' is not installed, skipping its packages\n", providerName)
+			return state
+		}
+		state.p = p
+
+		installedList, err := p.ListInstalled(ctx)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			return state
+		}
+		state.installed = make(map[string]provider.InstalledPackage, len(installedList))
+		for _, pkg := range installedList {
+			state.installed[provider.NormalizeID(p, pkg.ID)] = pkg
+		}
+
+		if withOutdated {
+			outdatedList, err := p.ListOutdated(ctx)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				return state
+			}
+			state.outdated = make(map[string]provider.OutdatedPackage, len(outdatedList))
+			for _, pkg := range outdatedList {
+				state.outdated[provider.NormalizeID(p, pkg.ID)] = pkg
+			}
+		}
+
+		state.checked = true
+		return state
+	}
+
+	statuses := make([]packageStatus, 0, len(packages))
+	for _, pkg := range packages {
+		status := packageStatus{
+			Name:              pkg.Name,
+			ID:                pkg.ID,
+			Provider:          pkg.Provider,
+			Profile:           pkg.Profile,
+			RegisteredVersion: pkg.Version,
+		}
+
+		state := stateFor(pkg.Provider)
+		if state.checked {
+			status.Checked = true
+			id := provider.NormalizeID(state.p, pkg.ID)
+			if installed, ok := state.installed[id]; ok {
+				status.Installed = true
+				status.CurrentVersion = installed.Version
+			}
+			if outdated, ok := state.outdated[id]; ok {
+				status.Outdated = true
+				if outdated.CurrentVersion != "" {
+					status.CurrentVersion = outdated.CurrentVersion
+				}
+				status.LatestVersion = outdated.LatestVersion
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
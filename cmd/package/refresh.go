@@ -0,0 +1,104 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewPackageRefreshCmd creates the package refresh command
+func NewPackageRefreshCmd() *cobra.Command {
+	var profile string
+	var providerName string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "Record installed versions in packages.json",
+		Long:  "Ask each provider for the installed version of every registered package and write it to the version field of packages.json. Packages that are not installed are left unchanged.",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPackageRefresh(cmd.Context(), profile, providerName, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Refresh only packages in this profile")
+	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Refresh only packages of this provider")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the changes without writing packages.json")
+
+	return cmd
+}
+
+func runPackageRefresh(ctx context.Context, profileFilter, providerFilter string, dryRun bool) error {
+	packages, err := loadFilteredPackages(profileFilter, providerFilter)
+	if err != nil {
+		return err
+	}
+	if len(packages) == 0 {
+		fmt.Println("No packages found.")
+		return nil
+	}
+
+	statuses := collectPackageStatuses(ctx, packages, false)
+	if err := ctx.Err(); err != nil {
+		// Interrupted: some providers were not asked, so nothing is written
+		return fmt.Errorf("refresh interrupted, packages.json was not updated: %w", err)
+	}
+
+	// key identifies a registered package (same as AddOrUpdatePackage)
+	key := func(id, providerName, profile string) string {
+		return id + "\x00" + providerName + "\x00" + profile
+	}
+	updates := make(map[string]string)
+	notInstalled := 0
+	for _, status := range statuses {
+		if !status.Checked {
+			continue
+		}
+		if !status.Installed {
+			fmt.Printf("  %s (%s:%s) [profile: %s]: not installed\n", status.Name, status.Provider, status.ID, status.Profile)
+			notInstalled++
+			continue
+		}
+		if status.CurrentVersion == "" || status.CurrentVersion == status.RegisteredVersion {
+			continue
+		}
+		from := status.RegisteredVersion
+		if from == "" {
+			from = "(none)"
+		}
+		fmt.Printf("  %s (%s:%s) [profile: %s]: %s -> %s\n", status.Name, status.Provider, status.ID, status.Profile, from, status.CurrentVersion)
+		updates[key(status.ID, status.Provider, status.Profile)] = status.CurrentVersion
+	}
+
+	if len(updates) == 0 {
+		fmt.Println("All recorded versions are up to date.")
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("\n%d package(s) would be updated (dry run)\n", len(updates))
+		return nil
+	}
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+	for i, pkg := range packagesConfig.Packages {
+		if version, ok := updates[key(pkg.ID, pkg.Provider, pkg.Profile)]; ok {
+			packagesConfig.Packages[i].Version = version
+		}
+	}
+	if err := config.SavePackagesConfig(packagesConfig); err != nil {
+		return fmt.Errorf("error saving packages config: %w", err)
+	}
+
+	fmt.Printf("\nUpdated %d package(s)", len(updates))
+	if notInstalled > 0 {
+		fmt.Printf(", %d not installed", notInstalled)
+	}
+	fmt.Println()
+	return nil
+}
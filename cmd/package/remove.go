@@ -2,14 +2,18 @@ package packagecmd
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/oplog"
 	"github.com/kkato1030/al/internal/provider"
+	"github.com/kkato1030/al/internal/query"
 	"github.com/kkato1030/al/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -20,21 +24,33 @@ func NewPackageRemoveCmd() *cobra.Command {
 	var profile string
 	var keepShell bool
 	var keepLink bool
+	var where string
+	var yes bool
 
 	cmd := &cobra.Command{
-		Use:   "remove <package-name>",
+		Use:   "remove [package-name]",
 		Short: "Remove a package",
-		Long:  "Remove a package from a profile. If the same package is still in another profile, only the config entry for this profile is removed (app is not uninstalled). When it is the last profile, the app is uninstalled and shell.d/link.d are cleaned up. Use --keep-shell to leave shell.d content; use --keep-link to leave link.d entry (clear package association only). If required flags are not provided, interactive mode will be used.",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Remove a package from a profile. If the same package is still in another profile, only the config entry for this profile is removed (app is not uninstalled). When it is the last profile, the app is uninstalled and shell.d/link.d are cleaned up. Use --keep-shell to leave shell.d content; use --keep-link to leave link.d entry (clear package association only). If required flags are not provided, interactive mode will be used. With --where, every matching package is removed (the package name is optional).",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if where != "" {
+				var packageName string
+				if len(args) > 0 {
+					packageName = args[0]
+				}
+				return runPackageRemoveWhere(cmd.Context(), packageName, provider, profile, where, yes, keepShell, keepLink)
+			}
+			if len(args) == 0 {
+				return fmt.Errorf("package name or --where is required")
+			}
 			packageName := args[0]
 
 			// If required flags are not set, use interactive mode
 			if provider == "" || profile == "" {
-				return runPackageRemoveInteractive(packageName, provider, profile, keepShell, keepLink)
+				return runPackageRemoveInteractive(cmd.Context(), packageName, provider, profile, keepShell, keepLink)
 			}
 
-			return runPackageRemove(packageName, provider, profile, keepShell, keepLink)
+			return runPackageRemove(cmd.Context(), packageName, provider, profile, keepShell, keepLink)
 		},
 	}
 
@@ -42,11 +58,13 @@ func NewPackageRemoveCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Profile name (required)")
 	cmd.Flags().BoolVar(&keepShell, "keep-shell", false, "Keep shell.d content when removing package")
 	cmd.Flags().BoolVar(&keepLink, "keep-link", false, "Keep link.d entry (clear package association only) when removing package")
+	addWhereFlag(cmd, &where)
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt when removing packages with --where")
 
 	return cmd
 }
 
-func runPackageRemove(packageName, providerName, profile string, keepShell, keepLink bool) error {
+func runPackageRemove(ctx context.Context, packageName, providerName, profile string, keepShell, keepLink bool) error {
 	// Check if package exists
 	packagesConfig, err := config.LoadPackagesConfig()
 	if err != nil {
@@ -65,12 +83,32 @@ func runPackageRemove(packageName, providerName, profile string, keepShell, keep
 		return fmt.Errorf("package '%s' with provider '%s' in profile '%s' not found", packageName, providerName, profile)
 	}
 
+	// A tap cannot be removed while registered formulae or casks still come from it
+	if isBrewTap(*foundPkg) {
+		if dependents := tapDependents(packagesConfig.Packages, *foundPkg); len(dependents) > 0 {
+			names := make([]string, len(dependents))
+			for i, pkg := range dependents {
+				names[i] = fmt.Sprintf("%s (profile: %s)", pkg.Name, pkg.Profile)
+			}
+			return fmt.Errorf("tap '%s' is required by %s; remove them first", packageName, strings.Join(names, ", "))
+		}
+	}
+
 	// If the same package (same ID+provider) exists in another profile, only remove from this profile (do not uninstall).
 	stillInOtherProfile, err := config.SamePackageInOtherProfile(foundPkg.ID, providerName, profile)
 	if err != nil {
 		return fmt.Errorf("error checking other profiles: %w", err)
 	}
 
+	// A package of a provider for another operating system is not installed on this machine
+	if !provider.SupportsHost(providerName) {
+		if err := config.RemovePackage(foundPkg.ID, providerName, profile); err != nil {
+			return fmt.Errorf("error removing package: %w", err)
+		}
+		fmt.Printf("Package '%s' (ID: %s) has been removed from profile '%s'; it was not uninstalled: %v\n", packageName, foundPkg.ID, profile, provider.CheckHost(providerName))
+		return nil
+	}
+
 	if stillInOtherProfile {
 		// Detach from this profile only: config removal, no uninstall/shell.d/link.d
 		if err := config.RemovePackage(foundPkg.ID, providerName, profile); err != nil {
@@ -96,20 +134,17 @@ func runPackageRemove(packageName, providerName, profile string, keepShell, keep
 	}
 
 	// Get provider instance
-	var p provider.Provider
-	switch providerName {
-	case "brew":
-		p = provider.NewBrewProvider()
-	case "mas":
-		p = provider.NewMasProvider()
-	case "manual":
-		p = provider.NewManualProvider()
-	default:
-		return fmt.Errorf("unsupported provider: %s", providerName)
+	p, err := provider.New(providerName)
+	if err != nil {
+		return err
 	}
 
 	// Uninstall the package using ID
-	if err := p.UninstallPackage(foundPkg.ID); err != nil {
+	logs := oplog.StartOrWarn("remove")
+	logs.Attach(p, packageName)
+	err = p.UninstallPackage(ctx, foundPkg.ID)
+	logs.Close()
+	if err != nil {
 		return fmt.Errorf("error uninstalling package: %w", err)
 	}
 
@@ -125,6 +160,19 @@ func runPackageRemove(packageName, providerName, profile string, keepShell, keep
 		}
 	}
 
+	// scripts.d: the scripts of a script package are not needed any more once no profile has it
+	if providerName == "script" {
+		shared, err := config.HasPackage(foundPkg.ID, providerName)
+		if err != nil {
+			return fmt.Errorf("error checking other profiles: %w", err)
+		}
+		if !shared {
+			if err := config.RemoveScriptPackageDir(foundPkg.ID); err != nil {
+				return fmt.Errorf("error removing scripts.d: %w", err)
+			}
+		}
+	}
+
 	// link.d: for each link associated with this package, either clear association (--keep-link) or remove link
 	links, err := config.ListLinks(foundPkg.ID, providerName)
 	if err != nil {
@@ -152,7 +200,73 @@ func runPackageRemove(packageName, providerName, profile string, keepShell, keep
 	return nil
 }
 
-func runPackageRemoveInteractive(packageName, provider, profile string, keepShell, keepLink bool) error {
+// runPackageRemoveWhere removes every package matching the --where expression (and the name, provider, and profile if given)
+func runPackageRemoveWhere(ctx context.Context, packageName, providerName, profile, where string, yes, keepShell, keepLink bool) error {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	var candidates []config.PackageConfig
+	for _, pkg := range packagesConfig.Packages {
+		if packageName != "" && pkg.Name != packageName {
+			continue
+		}
+		if providerName != "" && pkg.Provider != providerName {
+			continue
+		}
+		if profile != "" && pkg.Profile != profile {
+			continue
+		}
+		candidates = append(candidates, pkg)
+	}
+
+	targets, err := query.FilterPackages(where, candidates)
+	if err != nil {
+		return err
+	}
+	if len(targets) == 0 {
+		fmt.Println("No packages found.")
+		return nil
+	}
+
+	if !yes {
+		fmt.Printf("This will remove %d package(s) matching '%s':\n", len(targets), where)
+		for _, pkg := range targets {
+			fmt.Printf("  - %s (%s:%s) [profile: %s]\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile)
+		}
+		fmt.Print("\nDo you want to continue? [y/N]: ")
+		var response string
+		fmt.Scanln(&response)
+		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
+			fmt.Println("Removal cancelled.")
+			return nil
+		}
+	}
+
+	// Remove brew taps last, after the formulae and casks that come from them
+	sort.SliceStable(targets, func(i, j int) bool {
+		return !isBrewTap(targets[i]) && isBrewTap(targets[j])
+	})
+
+	errorCount := 0
+	for i, pkg := range targets {
+		if ctx.Err() != nil {
+			// Interrupted: the remaining packages stay registered
+			return fmt.Errorf("removal interrupted after %d of %d package(s): %w", i, len(targets), ctx.Err())
+		}
+		if err := runPackageRemove(ctx, pkg.Name, pkg.Provider, pkg.Profile, keepShell, keepLink); err != nil {
+			fmt.Printf("Error removing %s (%s:%s) from profile '%s': %v\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile, err)
+			errorCount++
+		}
+	}
+	if errorCount > 0 {
+		return fmt.Errorf("%d of %d package(s) could not be removed", errorCount, len(targets))
+	}
+	return nil
+}
+
+func runPackageRemoveInteractive(ctx context.Context, packageName, provider, profile string, keepShell, keepLink bool) error {
 	// Get package name
 	fmt.Printf("Package name: %s\n", packageName)
 
@@ -186,7 +300,7 @@ func runPackageRemoveInteractive(packageName, provider, profile string, keepShel
 	if len(matchingPackages) == 1 {
 		pkg := matchingPackages[0]
 		fmt.Printf("Found package: %s (provider: %s, profile: %s)\n", pkg.Name, pkg.Provider, pkg.Profile)
-		return runPackageRemove(packageName, pkg.Provider, pkg.Profile, keepShell, keepLink)
+		return runPackageRemove(ctx, packageName, pkg.Provider, pkg.Profile, keepShell, keepLink)
 	}
 
 	// Multiple matches, let user select with UI
@@ -201,5 +315,5 @@ func runPackageRemoveInteractive(packageName, provider, profile string, keepShel
 		return fmt.Errorf("package selection is required")
 	}
 
-	return runPackageRemove(packageName, selectedPkg.Provider, selectedPkg.Profile, keepShell, keepLink)
+	return runPackageRemove(ctx, packageName, selectedPkg.Provider, selectedPkg.Profile, keepShell, keepLink)
 }
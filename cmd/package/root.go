@@ -16,6 +16,7 @@ func NewPackageCmd() *cobra.Command {
 
 	packageCmd.AddCommand(NewPackageAddCmd())
 	packageCmd.AddCommand(NewPackageImportCmd())
+	packageCmd.AddCommand(NewPackageAdoptCmd())
 	packageCmd.AddCommand(NewPackageListCmd())
 	packageCmd.AddCommand(NewPackageShowCmd())
 	packageCmd.AddCommand(shell.NewCmd())
@@ -24,6 +25,14 @@ func NewPackageCmd() *cobra.Command {
 	packageCmd.AddCommand(NewPackageMoveCmd())
 	packageCmd.AddCommand(NewPackageSearchCmd())
 	packageCmd.AddCommand(NewPackageUpgradeCmd())
+	packageCmd.AddCommand(NewPackageOutdatedCmd())
+	packageCmd.AddCommand(NewPackageRefreshCmd())
+	packageCmd.AddCommand(NewPackageHoldCmd())
+	packageCmd.AddCommand(NewPackageUnholdCmd())
+	packageCmd.AddCommand(NewPackageServiceCmd())
+	packageCmd.AddCommand(NewPackageScriptCmd())
+	packageCmd.AddCommand(NewPackageGitCmd())
+	packageCmd.AddCommand(NewPackageRuntimeCmd())
 
 	return packageCmd
 }
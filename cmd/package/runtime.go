@@ -0,0 +1,140 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// NewPackageRuntimeCmd creates the package runtime command
+func NewPackageRuntimeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "runtime",
+		Short: "Manage the global versions of runtime packages",
+		Long:  "Manage the global default versions of runtime packages (mise or asdf) per profile.",
+	}
+
+	cmd.AddCommand(newRuntimeGlobalCmd())
+	cmd.AddCommand(newRuntimeUseCmd())
+
+	return cmd
+}
+
+func newRuntimeGlobalCmd() *cobra.Command {
+	var profile string
+
+	cmd := &cobra.Command{
+		Use:   "global <package-name>",
+		Short: "Make a runtime package the global default",
+		Long: `Make the version of a runtime package (e.g. node@20) the global default of its runtime: 'mise use --global', or
+'asdf set --home'. It is recorded in the profile, replacing the global version of the same runtime, so that
+'al package runtime use' applies it again. The setting applies to the package in every profile unless --profile is given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRuntimeGlobal(cmd.Context(), args[0], profile)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only set the global version in this profile")
+
+	return cmd
+}
+
+func newRuntimeUseCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "use [profile]",
+		Short: "Apply the global versions of a profile",
+		Long:  "Set the global default version of every runtime recorded in a profile (default: the default profile).",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile := ""
+			if len(args) > 0 {
+				profile = args[0]
+			}
+			return runRuntimeUse(cmd.Context(), profile)
+		},
+	}
+
+	return cmd
+}
+
+func runRuntimeGlobal(ctx context.Context, packageName, profile string) error {
+	id := provider.RuntimePackageID(packageName)
+	runtime, _ := provider.RuntimeSpec(id)
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	// Mark the package in its profiles, and unmark the other versions of the runtime there
+	profiles := make(map[string]bool)
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider == "runtime" && pkg.ID == id && (profile == "" || pkg.Profile == profile) {
+			profiles[pkg.Profile] = true
+		}
+	}
+	if len(profiles) == 0 {
+		return fmt.Errorf("runtime package '%s' not found", id)
+	}
+	for i := range packagesConfig.Packages {
+		pkg := &packagesConfig.Packages[i]
+		if pkg.Provider != "runtime" || !profiles[pkg.Profile] {
+			continue
+		}
+		if other, _ := provider.RuntimeSpec(pkg.ID); other == runtime {
+			pkg.Global = pkg.ID == id
+		}
+	}
+	if err := config.SavePackagesConfig(packagesConfig); err != nil {
+		return fmt.Errorf("error saving packages config: %w", err)
+	}
+
+	if err := provider.NewRuntimeProvider().SetGlobal(ctx, id); err != nil {
+		return fmt.Errorf("error setting global version: %w", err)
+	}
+	for name := range profiles {
+		fmt.Printf("Runtime '%s' is now the global %s in profile '%s'\n", id, runtime, name)
+	}
+	return nil
+}
+
+func runRuntimeUse(ctx context.Context, profile string) error {
+	if profile == "" {
+		appConfig, err := config.LoadAppConfig()
+		if err != nil {
+			return fmt.Errorf("error loading app config: %w", err)
+		}
+		profile = appConfig.DefaultProfile
+	}
+	if profile == "" {
+		return fmt.Errorf("profile must be specified or set as default. Use 'al config set --default-profile <profile>' to set the default")
+	}
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	runtimeProvider := provider.NewRuntimeProvider()
+	found := false
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider != "runtime" || pkg.Profile != profile || !pkg.Global {
+			continue
+		}
+		found = true
+		if err := runtimeProvider.SetGlobal(ctx, pkg.ID); err != nil {
+			return fmt.Errorf("error setting global version: %w", err)
+		}
+	}
+	if !found {
+		fmt.Printf("No global runtime versions in profile '%s'\n", profile)
+		return nil
+	}
+
+	fmt.Printf("Applied the global runtime versions of profile '%s'\n", profile)
+	return nil
+}
@@ -0,0 +1,102 @@
+package packagecmd
+
+import (
+	"fmt"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// UpgradeScope limits an upgrade to packages of a profile, stage, or provider
+type UpgradeScope struct {
+	Profile  string
+	Stage    string
+	Provider string
+	// Exclude lists package names (or IDs) to leave out
+	Exclude []string
+}
+
+// AddUpgradeScopeFlags registers --profile, --stage, --provider, and --exclude on an upgrade command.
+// excludeUsage describes what --exclude matches.
+func AddUpgradeScopeFlags(cmd *cobra.Command, scope *UpgradeScope, excludeUsage string) {
+	cmd.Flags().StringVarP(&scope.Profile, "profile", "f", "", "Only upgrade this profile (profile_name, or full profile_name.stage_name)")
+	cmd.Flags().StringVarP(&scope.Stage, "stage", "s", "", "Only upgrade this stage (stage_name)")
+	cmd.Flags().StringVarP(&scope.Provider, "provider", "p", "", "Only upgrade this provider")
+	cmd.Flags().StringSliceVarP(&scope.Exclude, "exclude", "x", nil, excludeUsage)
+}
+
+// IsZero returns true if the scope does not limit anything
+func (s UpgradeScope) IsZero() bool {
+	return s.Profile == "" && s.Stage == "" && s.Provider == "" && len(s.Exclude) == 0
+}
+
+// FilterPackages returns the packages within the scope. --profile is resolved like in 'al package add':
+// combined with --stage, and falling back to the profile without the stage if that does not exist.
+// --stage alone selects every profile of that stage.
+func (s UpgradeScope) FilterPackages(packages []config.PackageConfig) ([]config.PackageConfig, error) {
+	matchProfile, err := s.profileMatcher()
+	if err != nil {
+		return nil, err
+	}
+
+	excluded := make(map[string]bool, len(s.Exclude))
+	for _, name := range s.Exclude {
+		excluded[name] = true
+	}
+
+	var filtered []config.PackageConfig
+	for _, pkg := range packages {
+		if s.Provider != "" && pkg.Provider != s.Provider {
+			continue
+		}
+		if excluded[pkg.Name] || excluded[pkg.ID] {
+			continue
+		}
+		if !matchProfile(pkg.Profile) {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered, nil
+}
+
+// profileMatcher resolves --profile and --stage to a predicate on profile names
+func (s UpgradeScope) profileMatcher() (func(profile string) bool, error) {
+	if s.Profile != "" {
+		fullName, err := buildProfileName(s.Profile, s.Stage, "", "")
+		if err != nil {
+			return nil, err
+		}
+		profileConfig, err := findProfileWithFallback(fullName, s.Stage)
+		if err != nil {
+			return nil, fmt.Errorf("error loading profile: %w", err)
+		}
+		if profileConfig == nil {
+			return nil, fmt.Errorf("profile '%s' does not exist", fullName)
+		}
+		return func(profile string) bool { return profile == profileConfig.Name }, nil
+	}
+
+	if s.Stage != "" {
+		if err := config.ValidateProfileName(s.Stage); err != nil {
+			return nil, err
+		}
+		profilesConfig, err := config.LoadProfilesConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error loading profiles config: %w", err)
+		}
+		stages := make(map[string]string, len(profilesConfig.Profiles))
+		for _, p := range profilesConfig.Profiles {
+			stages[p.Name] = p.Stage
+		}
+		return func(profile string) bool {
+			if stages[profile] == s.Stage {
+				return true
+			}
+			_, stage, err := config.ParseProfileName(profile)
+			return err == nil && stage == s.Stage
+		}, nil
+	}
+
+	return func(string) bool { return true }, nil
+}
@@ -0,0 +1,196 @@
+package packagecmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/kkato1030/al/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+// scriptFlagName returns the package add flag of a script kind (--install-script, --check-script, ...)
+func scriptFlagName(kind string) string {
+	if kind == config.ScriptIsInstalled {
+		return "check-script"
+	}
+	return kind + "-script"
+}
+
+// scriptFlagValues returns the scripts given with package add flags, keyed by kind
+func scriptFlagValues(flags map[string]*string) map[string]string {
+	scripts := make(map[string]string)
+	for kind, value := range flags {
+		if *value != "" {
+			scripts[kind] = *value
+		}
+	}
+	return scripts
+}
+
+// writePackageScripts stores the scripts of a script package and checks that it has an install
+// and an is-installed script
+func writePackageScripts(id string, scripts map[string]string) error {
+	for _, kind := range []string{config.ScriptInstall, config.ScriptIsInstalled} {
+		if _, ok := scripts[kind]; ok {
+			continue
+		}
+		exists, err := config.ScriptExists(id, kind)
+		if err != nil {
+			return fmt.Errorf("error reading %s script: %w", kind, err)
+		}
+		if !exists {
+			return fmt.Errorf("a script package needs --%s", scriptFlagName(kind))
+		}
+	}
+	for _, kind := range config.ScriptKinds {
+		if content, ok := scripts[kind]; ok {
+			if err := config.WriteScript(id, kind, content); err != nil {
+				return fmt.Errorf("error writing %s script: %w", kind, err)
+			}
+		}
+	}
+	return nil
+}
+
+// scriptPackageStates runs the is-installed script of the script packages among packages and returns
+// "installed" or "not installed" by package ID. Packages whose script cannot be run are left out.
+func scriptPackageStates(ctx context.Context, packages []config.PackageConfig) map[string]string {
+	states := make(map[string]string)
+	var scriptProvider *provider.ScriptProvider
+	for _, pkg := range packages {
+		if pkg.Provider != "script" {
+			continue
+		}
+		if _, ok := states[pkg.ID]; ok {
+			continue
+		}
+		if scriptProvider == nil {
+			scriptProvider = provider.NewScriptProvider()
+		}
+		installed, _, err := scriptProvider.IsInstalled(ctx, pkg.ID)
+		if err != nil {
+			if !errors.Is(err, provider.ErrNoCheckScript) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			continue
+		}
+		if installed {
+			states[pkg.ID] = "installed"
+		} else {
+			states[pkg.ID] = "not installed"
+		}
+	}
+	return states
+}
+
+// NewPackageScriptCmd creates the package script command
+func NewPackageScriptCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "script",
+		Short: "Manage the scripts of script packages",
+		Long:  "Show or edit the install, uninstall, upgrade, and is-installed scripts of a script package, stored in ~/.al/scripts.d/<id>/.",
+	}
+
+	cmd.AddCommand(&cobra.Command{
+		Use:   "show <package-name>",
+		Short: "Show the scripts of a script package",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScriptShow(args[0])
+		},
+	})
+	cmd.AddCommand(&cobra.Command{
+		Use:   "edit <package-name> <" + strings.Join(config.ScriptKinds, "|") + ">",
+		Short: "Edit a script of a script package in EDITOR",
+		Long:  "Open a script of a script package in EDITOR (default: vim). The script is created if it does not exist.",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runScriptEdit(args[0], args[1])
+		},
+	})
+
+	return cmd
+}
+
+// resolveScriptPackage resolves a registered script package by name
+func resolveScriptPackage(packageName string) (*config.PackageConfig, error) {
+	pkg, err := ui.ResolvePackageByName(packageName)
+	if err != nil {
+		return nil, err
+	}
+	if pkg.Provider != "script" {
+		return nil, fmt.Errorf("package '%s' is a %s package, not a script package", packageName, pkg.Provider)
+	}
+	return pkg, nil
+}
+
+func runScriptShow(packageName string) error {
+	pkg, err := resolveScriptPackage(packageName)
+	if err != nil {
+		return err
+	}
+
+	for i, kind := range config.ScriptKinds {
+		path, err := config.GetScriptPath(pkg.ID, kind)
+		if err != nil {
+			return err
+		}
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("==> %s (%s) <==\n", kind, path)
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			fmt.Println("(none)")
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(data))
+	}
+	return nil
+}
+
+func runScriptEdit(packageName, kind string) error {
+	if err := config.ValidateScriptKind(kind); err != nil {
+		return err
+	}
+	pkg, err := resolveScriptPackage(packageName)
+	if err != nil {
+		return err
+	}
+
+	exists, err := config.ScriptExists(pkg.ID, kind)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		if err := config.WriteScript(pkg.ID, kind, "#!/bin/sh\n"); err != nil {
+			return err
+		}
+	}
+	path, err := config.GetScriptPath(pkg.ID, kind)
+	if err != nil {
+		return err
+	}
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+	editorCmd := exec.Command(editor, path)
+	editorCmd.Stdin = os.Stdin
+	editorCmd.Stdout = os.Stdout
+	editorCmd.Stderr = os.Stderr
+	if err := editorCmd.Run(); err != nil {
+		return fmt.Errorf("running %s: %w", editor, err)
+	}
+	return nil
+}
@@ -1,9 +1,12 @@
 package packagecmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/kkato1030/al/internal/provider"
 	"github.com/spf13/cobra"
 )
@@ -11,6 +14,7 @@ import (
 // NewPackageSearchCmd creates the package search command
 func NewPackageSearchCmd() *cobra.Command {
 	var providerName string
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
 		Use:   "search <query>",
@@ -22,19 +26,23 @@ func NewPackageSearchCmd() *cobra.Command {
 
 			// If provider is not specified, use interactive mode
 			if providerName == "" {
-				return runPackageSearchInteractive(query)
+				if !outputOpts.IsTable() {
+					return fmt.Errorf("--provider is required with --output or --template")
+				}
+				return runPackageSearchInteractive(cmd.Context(), query)
 			}
 
-			return runPackageSearch(query, providerName)
+			return runPackageSearch(cmd.Context(), query, providerName, outputOpts)
 		},
 	}
 
 	cmd.Flags().StringVarP(&providerName, "provider", "p", "", "Provider name (required)")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
 
 	return cmd
 }
 
-func runPackageSearch(query, providerName string) error {
+func runPackageSearch(ctx context.Context, query, providerName string, outputOpts output.Options) error {
 	// Validate provider exists
 	providerConfig, err := config.GetProvider(providerName)
 	if err != nil {
@@ -45,44 +53,51 @@ func runPackageSearch(query, providerName string) error {
 	}
 
 	// Get provider instance
-	var p provider.Provider
-	switch providerName {
-	case "brew":
-		p = provider.NewBrewProvider()
-	case "mas":
-		p = provider.NewMasProvider()
-	default:
-		return fmt.Errorf("unsupported provider: %s", providerName)
+	p, err := provider.New(providerName)
+	if err != nil {
+		return err
 	}
 
 	// Search for packages
-	results, err := p.SearchPackage(query)
+	results, err := p.SearchPackage(ctx, query)
 	if err != nil {
 		return fmt.Errorf("error searching packages: %w", err)
 	}
 
-	if len(results) == 0 {
-		fmt.Printf("No packages found for query '%s' with provider '%s'\n", query, providerName)
-		return nil
+	if results == nil {
+		results = []provider.SearchResult{}
 	}
 
-	// Display results
-	fmt.Printf("\nFound %d package(s) for query '%s' with provider '%s':\n\n", len(results), query, providerName)
-	for i, result := range results {
-		fmt.Printf("  %d. %s", i+1, result.Name)
-		if result.ID != "" {
-			fmt.Printf(" (ID: %s)", result.ID)
-		}
-		if result.Description != "" {
-			fmt.Printf(" - %s", result.Description)
-		}
-		fmt.Println()
-	}
+	return output.Print(outputOpts, output.Result{
+		Data:    results,
+		Columns: []string{"id", "name", "version", "tap", "homepage", "description"},
+		Table: func(w io.Writer) error {
+			if len(results) == 0 {
+				fmt.Fprintf(w, "No packages found for query '%s' with provider '%s'\n", query, providerName)
+				return nil
+			}
 
-	return nil
+			// Display results
+			fmt.Fprintf(w, "\nFound %d package(s) for query '%s' with provider '%s':\n\n", len(results), query, providerName)
+			for i, result := range results {
+				fmt.Fprintf(w, "  %d. %s", i+1, result.Name)
+				if result.ID != "" {
+					fmt.Fprintf(w, " (ID: %s)", result.ID)
+				}
+				if result.Version != "" {
+					fmt.Fprintf(w, " [%s]", result.Version)
+				}
+				if result.Description != "" {
+					fmt.Fprintf(w, " - %s", result.Description)
+				}
+				fmt.Fprintln(w)
+			}
+			return nil
+		},
+	})
 }
 
-func runPackageSearchInteractive(query string) error {
+func runPackageSearchInteractive(ctx context.Context, query string) error {
 	// Get provider
 	selectedProvider, err := selectProviderUI()
 	if err != nil {
@@ -92,5 +107,5 @@ func runPackageSearchInteractive(query string) error {
 		return fmt.Errorf("provider is required")
 	}
 
-	return runPackageSearch(query, selectedProvider)
+	return runPackageSearch(ctx, query, selectedProvider, output.Options{Format: output.FormatTable})
 }
@@ -0,0 +1,338 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/spf13/cobra"
+)
+
+// serviceEntry is a registered brew formula with its service policy and state
+type serviceEntry struct {
+	Name     string   `json:"name"`
+	ID       string   `json:"id"`
+	Profiles []string `json:"profiles"`
+	Policy   string   `json:"policy,omitempty"`
+	Status   string   `json:"status"`
+	User     string   `json:"user,omitempty"`
+	File     string   `json:"file,omitempty"`
+}
+
+// serviceColumns are the TSV columns of service status output
+var serviceColumns = []string{"name", "id", "profiles", "policy", "status", "user", "file"}
+
+// NewPackageServiceCmd creates the package service command
+func NewPackageServiceCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "service",
+		Short: "Manage brew services of registered formulae",
+		Long: `Start, stop, and check the services of registered brew formulae with 'brew services'.
+start and restart set the package's service policy to run, and stop sets it to off; 'al package service sync'
+starts every service with the policy run and stops every service with the policy off.`,
+	}
+
+	cmd.AddCommand(newServiceActionCmd("start", "Start a formula's service and keep it running on sync", config.ServiceRun))
+	cmd.AddCommand(newServiceActionCmd("stop", "Stop a formula's service and keep it stopped on sync", config.ServiceOff))
+	cmd.AddCommand(newServiceActionCmd("restart", "Restart a formula's service and keep it running on sync", config.ServiceRun))
+	cmd.AddCommand(newServiceStatusCmd())
+	cmd.AddCommand(newServiceSyncCmd())
+
+	return cmd
+}
+
+func newServiceActionCmd(action, short, policy string) *cobra.Command {
+	var profile string
+
+	cmd := &cobra.Command{
+		Use:   action + " <package-name>",
+		Short: short,
+		Long:  fmt.Sprintf("Run 'brew services %s' for a registered formula and set its service policy to %s in every profile (or only in --profile).", action, policy),
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServiceAction(cmd.Context(), action, args[0], profile, policy)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only set the policy of the package in this profile")
+
+	return cmd
+}
+
+func newServiceStatusCmd() *cobra.Command {
+	var profile string
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
+		Use:   "status [package-name]",
+		Short: "Show the service state of registered formulae",
+		Long:  "Show the service policy and the 'brew services' state of a registered formula, or of every registered formula that has a service policy or a service.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var packageName string
+			if len(args) > 0 {
+				packageName = args[0]
+			}
+			return runServiceStatus(cmd.Context(), packageName, profile, outputOpts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only show packages in this profile")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
+}
+
+func newServiceSyncCmd() *cobra.Command {
+	var profile string
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "sync",
+		Short: "Start and stop services to match their policies",
+		Long: `Start the services of registered formulae with the service policy run and stop those with the policy off.
+A formula registered in several profiles is kept running if any of them sets run.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServiceSync(cmd.Context(), profile, dryRun)
+		},
+	}
+
+	cmd.Flags().StringVarP(&profile, "profile", "f", "", "Only sync the services of packages in this profile")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show what would be started and stopped without doing it")
+
+	return cmd
+}
+
+// newServiceProvider returns the brew provider, checking that brew is installed
+func newServiceProvider(ctx context.Context) (*provider.BrewProvider, error) {
+	brew := provider.NewBrewProvider()
+	installed, err := brew.CheckInstalled(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error checking brew installation: %w", err)
+	}
+	if !installed {
+		return nil, fmt.Errorf("brew is not installed. Please install it first using 'al provider add brew'")
+	}
+	return brew, nil
+}
+
+// isBrewFormula returns true if pkg is a brew formula
+func isBrewFormula(pkg config.PackageConfig) bool {
+	return pkg.Provider == "brew" && (strings.HasPrefix(pkg.ID, "formula:") || !strings.Contains(pkg.ID, ":"))
+}
+
+// collectServiceEntries merges the registered brew formulae named packageName (all when empty) in
+// profile (all when empty) into one entry per formula. The policy of a formula registered in
+// several profiles is run if any of them sets run.
+func collectServiceEntries(packages []config.PackageConfig, packageName, profile string) []serviceEntry {
+	index := make(map[string]int)
+	var entries []serviceEntry
+	for _, pkg := range packages {
+		if !isBrewFormula(pkg) {
+			continue
+		}
+		if packageName != "" && pkg.Name != packageName {
+			continue
+		}
+		if profile != "" && pkg.Profile != profile {
+			continue
+		}
+		i, ok := index[pkg.ID]
+		if !ok {
+			i = len(entries)
+			index[pkg.ID] = i
+			entries = append(entries, serviceEntry{Name: pkg.Name, ID: pkg.ID})
+		}
+		entries[i].Profiles = append(entries[i].Profiles, pkg.Profile)
+		if pkg.Service == config.ServiceRun || entries[i].Policy == "" {
+			entries[i].Policy = pkg.Service
+		}
+	}
+
+	for i := range entries {
+		sort.Strings(entries[i].Profiles)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Name < entries[j].Name
+	})
+	return entries
+}
+
+func runServiceAction(ctx context.Context, action, packageName, profile, policy string) error {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	entries := collectServiceEntries(packagesConfig.Packages, packageName, profile)
+	if len(entries) == 0 {
+		return fmt.Errorf("brew formula '%s' not found", packageName)
+	}
+
+	brew, err := newServiceProvider(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		switch action {
+		case "start":
+			err = brew.StartService(ctx, entry.ID)
+		case "stop":
+			err = brew.StopService(ctx, entry.ID)
+		default:
+			err = brew.RestartService(ctx, entry.ID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	changed, err := updatePackageEntries(packageName, profile, "brew", func(pkg *config.PackageConfig) {
+		if isBrewFormula(*pkg) {
+			pkg.Service = policy
+		}
+	})
+	if err != nil {
+		return err
+	}
+	for _, pkg := range changed {
+		if isBrewFormula(pkg) {
+			fmt.Printf("Package '%s' (%s:%s) in profile '%s' is now set to service policy '%s'\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile, policy)
+		}
+	}
+	return nil
+}
+
+func runServiceStatus(ctx context.Context, packageName, profile string, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	entries := collectServiceEntries(packagesConfig.Packages, packageName, profile)
+	if packageName != "" && len(entries) == 0 {
+		return fmt.Errorf("brew formula '%s' not found", packageName)
+	}
+
+	brew, err := newServiceProvider(ctx)
+	if err != nil {
+		return err
+	}
+	services, err := brew.ListServices(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Without a package name, formulae without a policy or a service are left out
+	shown := []serviceEntry{}
+	for _, entry := range entries {
+		service, hasService := services[provider.BrewServiceName(entry.ID)]
+		if packageName == "" && entry.Policy == "" && !hasService {
+			continue
+		}
+		entry.Status = "none"
+		if hasService {
+			entry.Status = service.Status
+			entry.User = service.User
+			entry.File = service.File
+		}
+		shown = append(shown, entry)
+	}
+
+	return output.Print(outputOpts, output.Result{
+		Data:    shown,
+		Columns: serviceColumns,
+		Table: func(w io.Writer) error {
+			if len(shown) == 0 {
+				fmt.Fprintln(w, "No services found")
+				return nil
+			}
+			tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(tw, "NAME\tPOLICY\tSTATUS\tPROFILES")
+			for _, entry := range shown {
+				policy := entry.Policy
+				if policy == "" {
+					policy = "-"
+				}
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", entry.Name, policy, entry.Status, strings.Join(entry.Profiles, ", "))
+			}
+			return tw.Flush()
+		},
+	})
+}
+
+func runServiceSync(ctx context.Context, profile string, dryRun bool) error {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+
+	var entries []serviceEntry
+	for _, entry := range collectServiceEntries(packagesConfig.Packages, "", profile) {
+		if entry.Policy != "" {
+			entries = append(entries, entry)
+		}
+	}
+	if len(entries) == 0 {
+		fmt.Println("No packages have a service policy.")
+		return nil
+	}
+
+	brew, err := newServiceProvider(ctx)
+	if err != nil {
+		return err
+	}
+	services, err := brew.ListServices(ctx)
+	if err != nil {
+		return err
+	}
+
+	changes, errorCount := 0, 0
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("service sync interrupted: %w", err)
+		}
+		running := services[provider.BrewServiceName(entry.ID)].Running()
+		switch {
+		case entry.Policy == config.ServiceRun && !running:
+			changes++
+			fmt.Printf("Starting service %s...\n", entry.Name)
+			if !dryRun {
+				err = brew.StartService(ctx, entry.ID)
+			}
+		case entry.Policy == config.ServiceOff && running:
+			changes++
+			fmt.Printf("Stopping service %s...\n", entry.Name)
+			if !dryRun {
+				err = brew.StopService(ctx, entry.ID)
+			}
+		default:
+			continue
+		}
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			errorCount++
+			err = nil
+		}
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%d of %d service(s) could not be synced", errorCount, changes)
+	}
+	if changes == 0 {
+		fmt.Println("All services match their policies.")
+	}
+	return nil
+}
@@ -1,33 +1,52 @@
 package packagecmd
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/kkato1030/al/internal/query"
 	"github.com/spf13/cobra"
 )
 
+// packageDetail is a registered package with its current state, as shown by 'al package show'
+type packageDetail struct {
+	config.PackageConfig
+	// ServiceStatus is the 'brew services' state of a brew formula that has a service
+	ServiceStatus string `json:"service_status,omitempty"`
+}
+
 // NewPackageShowCmd creates the package show command
 func NewPackageShowCmd() *cobra.Command {
-	var outputFormat string
+	var where string
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
-		Use:   "show <package-name>",
+		Use:   "show [package-name]",
 		Short: "Show package details",
-		Long:  "Show detailed information about a specific package",
-		Args:  cobra.ExactArgs(1),
+		Long:  "Show detailed information about a specific package. With --where, the package name is optional and all matching packages are shown.",
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runPackageShow(args[0], outputFormat)
+			if len(args) == 0 && where == "" {
+				return fmt.Errorf("package name or --where is required")
+			}
+			var packageName string
+			if len(args) > 0 {
+				packageName = args[0]
+			}
+			return runPackageShow(cmd.Context(), packageName, where, outputOpts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "Output format (json)")
+	addWhereFlag(cmd, &where)
+	output.AddFlags(cmd, &outputOpts, output.FormatJSON)
 
 	return cmd
 }
 
-func runPackageShow(packageName, outputFormat string) error {
+func runPackageShow(ctx context.Context, packageName, where string, outputOpts output.Options) error {
 	packagesConfig, err := config.LoadPackagesConfig()
 	if err != nil {
 		return fmt.Errorf("error loading packages config: %w", err)
@@ -36,36 +55,71 @@ func runPackageShow(packageName, outputFormat string) error {
 	// Find all packages with the given name
 	var matchingPackages []config.PackageConfig
 	for _, pkg := range packagesConfig.Packages {
-		if pkg.Name == packageName {
+		if packageName == "" || pkg.Name == packageName {
 			matchingPackages = append(matchingPackages, pkg)
 		}
 	}
 
+	matchingPackages, err = query.FilterPackages(where, matchingPackages)
+	if err != nil {
+		return err
+	}
+
 	if len(matchingPackages) == 0 {
+		if packageName == "" {
+			return fmt.Errorf("no packages match --where '%s'", where)
+		}
 		return fmt.Errorf("package '%s' not found", packageName)
 	}
 
-	switch outputFormat {
-	case "json":
-		return outputJSON(matchingPackages)
-	default:
-		return fmt.Errorf("unsupported output format: %s", outputFormat)
+	details := make([]packageDetail, len(matchingPackages))
+	for i, pkg := range matchingPackages {
+		details[i] = packageDetail{PackageConfig: pkg}
+	}
+	addServiceStatus(ctx, details)
+
+	// If only one package was found by name, output it directly; otherwise output as array.
+	// With --where the output is always an array.
+	var data interface{} = details
+	if len(details) == 1 && where == "" {
+		data = details[0]
 	}
+
+	return output.Print(outputOpts, output.Result{
+		Data:    data,
+		Columns: packageColumns,
+		Table:   output.KeyValueTable(data, packageColumns),
+	})
 }
 
-func outputJSON(packages []config.PackageConfig) error {
-	// If only one package, output it directly; otherwise output as array
-	var data []byte
-	var err error
-	if len(packages) == 1 {
-		data, err = json.MarshalIndent(packages[0], "", "  ")
-	} else {
-		data, err = json.MarshalIndent(packages, "", "  ")
+// addServiceStatus fills the service state of the brew formulae in details. The state is left
+// empty when brew is not available.
+func addServiceStatus(ctx context.Context, details []packageDetail) {
+	hasFormula := false
+	for _, detail := range details {
+		if isBrewFormula(detail.PackageConfig) {
+			hasFormula = true
+			break
+		}
 	}
-	if err != nil {
-		return fmt.Errorf("error marshaling package to JSON: %w", err)
+	if !hasFormula {
+		return
 	}
 
-	fmt.Println(string(data))
-	return nil
+	brew := provider.NewBrewProvider()
+	if installed, err := brew.CheckInstalled(ctx); err != nil || !installed {
+		return
+	}
+	services, err := brew.ListServices(ctx)
+	if err != nil {
+		return
+	}
+	for i, detail := range details {
+		if !isBrewFormula(detail.PackageConfig) {
+			continue
+		}
+		if service, ok := services[provider.BrewServiceName(detail.ID)]; ok {
+			details[i].ServiceStatus = service.Status
+		}
+	}
 }
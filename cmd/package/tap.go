@@ -0,0 +1,108 @@
+package packagecmd
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/provider"
+)
+
+// isBrewTap returns true if pkg is a brew tap
+func isBrewTap(pkg config.PackageConfig) bool {
+	return pkg.Provider == "brew" && strings.HasPrefix(pkg.ID, "tap:")
+}
+
+// findTapRegistration returns the registered brew tap named tap, preferring the one in profile
+func findTapRegistration(packages []config.PackageConfig, tap, profile string) *config.PackageConfig {
+	var found *config.PackageConfig
+	for i, pkg := range packages {
+		if !isBrewTap(pkg) {
+			continue
+		}
+		if !provider.SameBrewTap(strings.TrimPrefix(pkg.ID, "tap:"), tap) {
+			continue
+		}
+		if pkg.Profile == profile {
+			return &packages[i]
+		}
+		if found == nil {
+			found = &packages[i]
+		}
+	}
+	return found
+}
+
+// ensureTapDependency makes sure the tap a brew formula or cask comes from (user/repo for
+// user/repo/tool) is registered, adding it to profile when no profile has it yet. With install,
+// the tap is also tapped, from its registered URL if it has one.
+func ensureTapDependency(ctx context.Context, brew *provider.BrewProvider, packageID, profile string, install bool) error {
+	tap := provider.BrewTapDependency(packageID)
+	if tap == "" {
+		return nil
+	}
+
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading packages config: %w", err)
+	}
+	registration := findTapRegistration(packagesConfig.Packages, tap, profile)
+
+	if install {
+		url := ""
+		if registration != nil {
+			url = registration.URL
+		}
+		if err := brew.EnsureTap(ctx, tap, url); err != nil {
+			return fmt.Errorf("error tapping %s (required by %s): %w", tap, packageID, err)
+		}
+	}
+
+	if registration == nil {
+		pkg := config.PackageConfig{
+			ID:          "tap:" + tap,
+			Name:        tap,
+			Provider:    "brew",
+			Profile:     profile,
+			InstalledAt: time.Now(),
+		}
+		if err := config.AddPackage(pkg); err != nil {
+			return fmt.Errorf("error registering tap %s: %w", tap, err)
+		}
+		fmt.Printf("Registered tap '%s' in profile '%s' (required by %s)\n", tap, profile, packageID)
+	}
+	return nil
+}
+
+// tapDependents returns the registered formulae and casks that would lose their tap if the tap
+// registered as tapPkg were removed: those in the same profile, and, when no other profile
+// registers the tap, those in every profile
+func tapDependents(packages []config.PackageConfig, tapPkg config.PackageConfig) []config.PackageConfig {
+	tap := strings.TrimPrefix(tapPkg.ID, "tap:")
+
+	registeredElsewhere := false
+	for _, pkg := range packages {
+		if isBrewTap(pkg) && pkg.Profile != tapPkg.Profile && provider.SameBrewTap(strings.TrimPrefix(pkg.ID, "tap:"), tap) {
+			registeredElsewhere = true
+			break
+		}
+	}
+
+	var dependents []config.PackageConfig
+	for _, pkg := range packages {
+		if pkg.Provider != "brew" {
+			continue
+		}
+		dependency := provider.BrewTapDependency(pkg.ID)
+		if dependency == "" || !provider.SameBrewTap(dependency, tap) {
+			continue
+		}
+		if registeredElsewhere && pkg.Profile != tapPkg.Profile {
+			continue
+		}
+		dependents = append(dependents, pkg)
+	}
+	return dependents
+}
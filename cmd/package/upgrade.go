@@ -1,130 +1,143 @@
 package packagecmd
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/kkato1030/al/internal/provider"
+	"github.com/kkato1030/al/internal/query"
+	"github.com/kkato1030/al/internal/version"
 	"github.com/spf13/cobra"
 )
 
 // NewPackageUpgradeCmd creates the package upgrade command
 func NewPackageUpgradeCmd() *cobra.Command {
 	var yes bool
+	var where string
+	var scope UpgradeScope
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [package-name]",
 		Short: "Upgrade package(s)",
-		Long:  "Upgrade a specific package or all packages. If package-name is not provided, all packages will be upgraded.",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Upgrade a specific package or all packages. If package-name is not provided, all packages (or all packages matching --where) will be upgraded.
+A package registered in several profiles is upgraded once. Packages of the same provider are upgraded together
+(e.g. one 'brew upgrade' for all formulae), and different providers run concurrently.
+A summary of versions before and after, failures, and elapsed time is printed at the end.
+Use --profile, --stage, --provider, and --exclude to limit the upgrade, e.g. '--profile core' leaves trial profiles alone.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				return runPackageUpgradeAll(yes)
+				return runPackageUpgradeAll(cmd.Context(), yes, where, scope, outputOpts)
 			}
-			return runPackageUpgrade(args[0])
+			return runPackageUpgrade(cmd.Context(), args[0], where, scope, outputOpts)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	addWhereFlag(cmd, &where)
+	AddUpgradeScopeFlags(cmd, &scope, "Package name or ID to leave out (repeatable)")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
 
 	return cmd
 }
 
-// RunPackageUpgradeAll upgrades all packages
-func RunPackageUpgradeAll(yes bool) error {
-	return runPackageUpgradeAll(yes)
+// RunPackageUpgradeAll upgrades all packages within scope
+func RunPackageUpgradeAll(ctx context.Context, yes bool, scope UpgradeScope) error {
+	return runPackageUpgradeAll(ctx, yes, "", scope, output.Options{Format: output.FormatTable})
 }
 
-func runPackageUpgradeAll(yes bool) error {
+// progressWriter returns where messages and provider output go: stdout for table output,
+// stderr when stdout is reserved for the machine-readable report
+func progressWriter(outputOpts output.Options) io.Writer {
+	if outputOpts.IsTable() {
+		return os.Stdout
+	}
+	return os.Stderr
+}
+
+func runPackageUpgradeAll(ctx context.Context, yes bool, where string, scope UpgradeScope, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+	progress := progressWriter(outputOpts)
+
 	// Load packages config
 	packagesConfig, err := config.LoadPackagesConfig()
 	if err != nil {
 		return fmt.Errorf("error loading packages config: %w", err)
 	}
 
-	if len(packagesConfig.Packages) == 0 {
+	targets, err := scope.FilterPackages(packagesConfig.Packages)
+	if err != nil {
+		return err
+	}
+	targets, err = query.FilterPackages(where, targets)
+	if err != nil {
+		return err
+	}
+
+	if len(targets) == 0 && outputOpts.IsTable() {
 		fmt.Println("No packages found.")
 		return nil
 	}
 
+	// Apply upgrade policies and version constraints
+	targets, held := planUpgrades(ctx, targets, false)
+	items := dedupeUpgrades(targets)
+
 	// Ask for confirmation
-	if !yes {
-		fmt.Printf("This will upgrade all %d package(s):\n", len(packagesConfig.Packages))
-		for _, pkg := range packagesConfig.Packages {
-			fmt.Printf("  - %s (%s:%s)", pkg.Name, pkg.Provider, pkg.ID)
-			if pkg.Profile != "" {
-				fmt.Printf(" [profile: %s]", pkg.Profile)
-			}
-			fmt.Println()
+	if !yes && len(items) > 0 {
+		if where != "" {
+			fmt.Fprintf(progress, "This will upgrade %d package(s) matching '%s':\n", len(items), where)
+		} else if !scope.IsZero() {
+			fmt.Fprintf(progress, "This will upgrade %d package(s):\n", len(items))
+		} else {
+			fmt.Fprintf(progress, "This will upgrade all %d package(s):\n", len(items))
 		}
-		fmt.Print("\nDo you want to continue? [y/N]: ")
+		printUpgradeItems(progress, items)
+		if len(held) > 0 {
+			fmt.Fprintf(progress, "%d package(s) are held back and will not be upgraded.\n", len(held))
+		}
+		fmt.Fprint(progress, "\nDo you want to continue? [y/N]: ")
 		var response string
 		fmt.Scanln(&response)
 		if strings.ToLower(response) != "y" && strings.ToLower(response) != "yes" {
-			fmt.Println("Upgrade cancelled.")
+			fmt.Fprintln(progress, "Upgrade cancelled.")
 			return nil
 		}
 	}
-
-	// Group packages by provider for efficient upgrading
-	packagesByProvider := make(map[string][]config.PackageConfig)
-	for _, pkg := range packagesConfig.Packages {
-		packagesByProvider[pkg.Provider] = append(packagesByProvider[pkg.Provider], pkg)
-	}
-
-	// Upgrade packages by provider
-	successCount := 0
-	errorCount := 0
-
-	for providerName, packages := range packagesByProvider {
-		fmt.Printf("\nUpgrading packages for provider: %s\n", providerName)
-
-		// Get provider instance
-		var p provider.Provider
-		switch providerName {
-		case "brew":
-			p = provider.NewBrewProvider()
-		case "mas":
-			p = provider.NewMasProvider()
-		case "manual":
-			p = provider.NewManualProvider()
-		default:
-			fmt.Printf("Warning: unknown provider '%s', skipping packages\n", providerName)
-			errorCount += len(packages)
-			continue
-		}
-
-		// Check if provider is installed
-		installed, err := p.CheckInstalled()
-		if err != nil {
-			fmt.Printf("Error checking provider installation: %v\n", err)
-			errorCount += len(packages)
-			continue
-		}
-		if !installed {
-			fmt.Printf("Provider '%s' is not installed, skipping packages\n", providerName)
-			errorCount += len(packages)
-			continue
-		}
-
-		// Upgrade each package
-		for _, pkg := range packages {
-			fmt.Printf("  Upgrading %s...\n", pkg.Name)
-			if err := p.UpgradePackage(pkg.ID); err != nil {
-				fmt.Printf("  Error upgrading %s: %v\n", pkg.Name, err)
-				errorCount++
-			} else {
-				successCount++
-			}
-		}
+	if err := ctx.Err(); err != nil {
+		return err
 	}
 
-	fmt.Printf("\nUpgrade completed: %d succeeded, %d failed\n", successCount, errorCount)
+	report := executeUpgrades(ctx, items, held, progress)
+	err = output.Print(outputOpts, output.Result{
+		Data: report,
+		Table: func(w io.Writer) error {
+			return printUpgradeReport(w, report)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("upgrade interrupted: %w", err)
+	}
 	return nil
 }
 
-func runPackageUpgrade(packageName string) error {
+func runPackageUpgrade(ctx context.Context, packageName, where string, scope UpgradeScope, outputOpts output.Options) error {
+	if err := outputOpts.Validate(); err != nil {
+		return err
+	}
+	progress := progressWriter(outputOpts)
+
 	// Load packages config
 	packagesConfig, err := config.LoadPackagesConfig()
 	if err != nil {
@@ -139,52 +152,176 @@ func runPackageUpgrade(packageName string) error {
 		}
 	}
 
+	matchingPackages, err = scope.FilterPackages(matchingPackages)
+	if err != nil {
+		return err
+	}
+	matchingPackages, err = query.FilterPackages(where, matchingPackages)
+	if err != nil {
+		return err
+	}
+
 	if len(matchingPackages) == 0 {
 		return fmt.Errorf("package '%s' not found", packageName)
 	}
 
+	// Apply upgrade policies and version constraints; manual packages are upgraded when named
+	matchingPackages, held := planUpgrades(ctx, matchingPackages, true)
+	items := dedupeUpgrades(matchingPackages)
+
 	// If multiple packages with same name, upgrade all of them
-	if len(matchingPackages) > 1 {
-		fmt.Printf("Found %d package(s) with name '%s':\n", len(matchingPackages), packageName)
-		for _, pkg := range matchingPackages {
-			fmt.Printf("  - %s (%s:%s) [profile: %s]\n", pkg.Name, pkg.Provider, pkg.ID, pkg.Profile)
-		}
-		fmt.Println("Upgrading all matching packages...\n")
-	}
-
-	// Upgrade each matching package
-	for _, pkg := range matchingPackages {
-		// Get provider instance
-		var p provider.Provider
-		switch pkg.Provider {
-		case "brew":
-			p = provider.NewBrewProvider()
-		case "mas":
-			p = provider.NewMasProvider()
-		case "manual":
-			p = provider.NewManualProvider()
-		default:
-			fmt.Printf("Warning: unknown provider '%s' for package %s, skipping\n", pkg.Provider, pkg.Name)
-			continue
+	if len(items) > 1 {
+		fmt.Fprintf(progress, "Found %d package(s) with name '%s':\n", len(items), packageName)
+		printUpgradeItems(progress, items)
+		fmt.Fprint(progress, "Upgrading all matching packages...\n\n")
+	}
+
+	report := executeUpgrades(ctx, items, held, progress)
+	err = output.Print(outputOpts, output.Result{
+		Data: report,
+		Table: func(w io.Writer) error {
+			if err := printUpgradeReport(w, report); err != nil {
+				return err
+			}
+			if len(items) == 0 && len(held) > 0 {
+				fmt.Fprintf(w, "Use 'al package unhold %s' to allow upgrades.\n", packageName)
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("upgrade interrupted: %w", err)
+	}
+	if report.Failed > 0 {
+		return fmt.Errorf("error upgrading %s: %d of %d package(s) failed", packageName, report.Failed, len(report.Results))
+	}
+	return nil
+}
+
+// printUpgradeItems lists packages about to be upgraded
+func printUpgradeItems(w io.Writer, items []upgradeItem) {
+	for _, item := range items {
+		fmt.Fprintf(w, "  - %s (%s:%s) [profile: %s]\n", item.Name, item.Provider, item.ID, strings.Join(item.Profiles, ", "))
+	}
+}
+
+// heldPackage is a package left out of an upgrade, with the reason
+type heldPackage struct {
+	Package config.PackageConfig
+	Reason  string
+}
+
+// planUpgrades applies upgrade policies and version constraints to packages. explicit is set
+// when the packages were named on the command line, which is when manual packages are upgraded.
+// Entries of the same package in several profiles share the strictest policy and all constraints.
+func planUpgrades(ctx context.Context, packages []config.PackageConfig, explicit bool) (upgrades []config.PackageConfig, held []heldPackage) {
+	type packageKey struct{ provider, id string }
+	keyOf := func(pkg config.PackageConfig) packageKey {
+		if p, err := provider.New(pkg.Provider); err == nil {
+			return packageKey{pkg.Provider, provider.NormalizeID(p, pkg.ID)}
 		}
+		return packageKey{pkg.Provider, pkg.ID}
+	}
 
-		// Check if provider is installed
-		installed, err := p.CheckInstalled()
+	// Collect the policy and constraints of every registered entry, not only the targets,
+	// so that a hold in one profile also applies to the same package in another profile
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		packagesConfig = &config.PackagesConfig{Packages: packages}
+	}
+	policies := make(map[packageKey]string)
+	constraints := make(map[packageKey][]string)
+	rank := map[string]int{config.UpgradeAuto: 0, config.UpgradeManual: 1, config.UpgradeHold: 2}
+	for _, pkg := range packagesConfig.Packages {
+		key := keyOf(pkg)
+		if rank[pkg.UpgradePolicy()] >= rank[policies[key]] {
+			policies[key] = pkg.UpgradePolicy()
+		}
+		if pkg.Constraint != "" {
+			constraints[key] = append(constraints[key], pkg.Constraint)
+		}
+	}
+
+	// Latest versions are looked up once per provider, only when a constraint needs them
+	latest := make(map[string]map[packageKey]string)
+	latestFor := func(providerName string) (map[packageKey]string, error) {
+		if versions, ok := latest[providerName]; ok {
+			return versions, nil
+		}
+		p, err := provider.New(providerName)
 		if err != nil {
-			fmt.Printf("Error checking provider installation: %v\n", err)
+			return nil, err
+		}
+		outdated, err := p.ListOutdated(ctx)
+		if err != nil {
+			return nil, err
+		}
+		versions := make(map[packageKey]string, len(outdated))
+		for _, pkg := range outdated {
+			versions[packageKey{providerName, provider.NormalizeID(p, pkg.ID)}] = pkg.LatestVersion
+		}
+		latest[providerName] = versions
+		return versions, nil
+	}
+
+	for _, pkg := range packages {
+		if err := provider.CheckHost(pkg.Provider); err != nil {
+			held = append(held, heldPackage{pkg, err.Error()})
 			continue
 		}
-		if !installed {
-			fmt.Printf("Provider '%s' is not installed for package %s, skipping\n", pkg.Provider, pkg.Name)
+		key := keyOf(pkg)
+		switch policies[key] {
+		case config.UpgradeHold:
+			held = append(held, heldPackage{pkg, "held"})
 			continue
+		case config.UpgradeManual:
+			if !explicit {
+				held = append(held, heldPackage{pkg, "manual (upgrade it by name)"})
+				continue
+			}
 		}
 
-		// Upgrade the package
-		fmt.Printf("Upgrading %s (%s:%s)...\n", pkg.Name, pkg.Provider, pkg.ID)
-		if err := p.UpgradePackage(pkg.ID); err != nil {
-			return fmt.Errorf("error upgrading %s: %w", pkg.Name, err)
+		if len(constraints[key]) == 0 {
+			upgrades = append(upgrades, pkg)
+			continue
+		}
+
+		versions, err := latestFor(pkg.Provider)
+		if err != nil {
+			held = append(held, heldPackage{pkg, fmt.Sprintf("constraint %s: cannot check the latest version: %v", strings.Join(constraints[key], "; "), err)})
+			continue
 		}
+		latestVersion, ok := versions[key]
+		if !ok {
+			// Not outdated: nothing to upgrade
+			continue
+		}
+		if reason := checkConstraints(constraints[key], latestVersion); reason != "" {
+			held = append(held, heldPackage{pkg, reason})
+			continue
+		}
+		upgrades = append(upgrades, pkg)
 	}
+	return upgrades, held
+}
 
-	return nil
+// checkConstraints returns why latestVersion is not allowed by constraints, or "" if it is
+func checkConstraints(constraints []string, latestVersion string) string {
+	for _, text := range constraints {
+		c, err := version.ParseConstraint(text)
+		if err != nil {
+			return err.Error()
+		}
+		if latestVersion == "" {
+			return fmt.Sprintf("constraint %s: latest version unknown", text)
+		}
+		if !c.Check(latestVersion) {
+			return fmt.Sprintf("constraint %s excludes %s", text, latestVersion)
+		}
+	}
+	return ""
 }
@@ -0,0 +1,554 @@
+package packagecmd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/oplog"
+	"github.com/kkato1030/al/internal/output"
+	"github.com/kkato1030/al/internal/provider"
+	"github.com/kkato1030/al/internal/ui"
+)
+
+// Upgrade result statuses
+const (
+	upgradeStatusUpgraded  = "upgraded"
+	upgradeStatusUnchanged = "unchanged"
+	upgradeStatusDone      = "done" // succeeded, but the provider cannot report versions
+	upgradeStatusFailed    = "failed"
+	upgradeStatusCancelled = "cancelled" // not done because the upgrade was interrupted
+)
+
+// finishTimeout limits the version check after an interrupted upgrade
+const finishTimeout = 30 * time.Second
+
+// upgradeItem is one package to upgrade: a (provider, ID) pair with the profiles it is registered in
+type upgradeItem struct {
+	Name     string
+	ID       string
+	Provider string
+	Profiles []string
+	// Options are the package's options (PackageConfig.Options), taken from the first profile that sets them
+	Options []string
+}
+
+// upgradeResult is the outcome of upgrading one package
+type upgradeResult struct {
+	Name     string   `json:"name"`
+	ID       string   `json:"id"`
+	Provider string   `json:"provider"`
+	Profiles []string `json:"profiles"`
+	Status   string   `json:"status"`
+	Before   string   `json:"before,omitempty"`
+	After    string   `json:"after,omitempty"`
+	Error    string   `json:"error,omitempty"`
+	// Stderr is the captured error output of a failed upgrade
+	Stderr string `json:"stderr,omitempty"`
+}
+
+// heldResult is a package left out of an upgrade
+type heldResult struct {
+	Name     string `json:"name"`
+	ID       string `json:"id"`
+	Provider string `json:"provider"`
+	Profile  string `json:"profile"`
+	Reason   string `json:"reason"`
+}
+
+// upgradeReport is the summary of an upgrade run
+type upgradeReport struct {
+	Results        []upgradeResult `json:"results"`
+	Held           []heldResult    `json:"held"`
+	Succeeded      int             `json:"succeeded"`
+	Failed         int             `json:"failed"`
+	Cancelled      int             `json:"cancelled"`
+	ElapsedSeconds float64         `json:"elapsed_seconds"`
+	// Log is the ID of the operation log holding the provider output, for 'al logs'
+	Log string `json:"log,omitempty"`
+}
+
+// upgradeTracker records provider output per package in the operation log and, on a terminal,
+// shows a spinner per running package instead of the output itself
+type upgradeTracker struct {
+	logs     *oplog.Run
+	progress *ui.Progress
+	index    map[string]int
+}
+
+func upgradeItemKey(item upgradeItem) string {
+	return item.Provider + "\x00" + item.ID
+}
+
+// newUpgradeTracker starts the operation log and, if progress is a terminal, the progress display
+func newUpgradeTracker(ctx context.Context, items []upgradeItem, progress io.Writer) *upgradeTracker {
+	t := &upgradeTracker{index: make(map[string]int, len(items))}
+	if len(items) == 0 {
+		return t
+	}
+
+	t.logs = oplog.StartOrWarn("upgrade")
+
+	labels := make([]string, len(items))
+	for i, item := range items {
+		t.index[upgradeItemKey(item)] = i
+		labels[i] = fmt.Sprintf("%s (%s)", item.Name, item.Provider)
+	}
+	if ui.CanShowProgress(progress) && validateSudo(ctx, items) {
+		t.progress = ui.NewProgress(progress, labels)
+	}
+	return t
+}
+
+// validateSudo asks for the sudo password before the progress display starts when a provider of
+// items runs through sudo, since a prompt would be hidden under the display. It returns false if
+// sudo could not be validated; the upgrade then runs without the display so that sudo can prompt.
+func validateSudo(ctx context.Context, items []upgradeItem) bool {
+	for _, item := range items {
+		if provider.NeedsSudo(item.Provider) {
+			if err := provider.ValidateSudo(ctx); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				return false
+			}
+			return true
+		}
+	}
+	return true
+}
+
+// writers returns stdout and stderr extended to also write to the logs of items
+func (t *upgradeTracker) writers(items []upgradeItem, stdout, stderr io.Writer) (io.Writer, io.Writer) {
+	outs, errs := []io.Writer{stdout}, []io.Writer{stderr}
+	for _, item := range items {
+		if t.logs == nil {
+			break
+		}
+		if log, err := t.logs.Writer(item.Name); err == nil {
+			outs = append(outs, log)
+			errs = append(errs, log)
+		}
+	}
+	return io.MultiWriter(outs...), io.MultiWriter(errs...)
+}
+
+func (t *upgradeTracker) started(item upgradeItem) {
+	if t.progress != nil {
+		t.progress.Start(t.index[upgradeItemKey(item)])
+	}
+}
+
+func (t *upgradeTracker) finished(item upgradeItem, err error) {
+	if t.progress == nil {
+		return
+	}
+	detail := ""
+	if err != nil {
+		detail = "failed"
+		if errors.Is(err, context.Canceled) {
+			detail = "interrupted"
+		}
+	}
+	t.progress.Finish(t.index[upgradeItemKey(item)], err == nil, detail)
+}
+
+// logResult ends the log of a package with the outcome of its upgrade
+func (t *upgradeTracker) logResult(result upgradeResult) {
+	if t.logs == nil {
+		return
+	}
+	log, err := t.logs.Writer(result.Name)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(log, "\n[al] %s (%s:%s): %s", result.Name, result.Provider, result.ID, result.Status)
+	switch {
+	case result.Status == upgradeStatusUpgraded:
+		fmt.Fprintf(log, " %s → %s", result.Before, result.After)
+	case result.After != "":
+		fmt.Fprintf(log, " %s", result.After)
+	case result.Before != "":
+		fmt.Fprintf(log, " %s", result.Before)
+	}
+	if result.Error != "" {
+		fmt.Fprintf(log, ": %s", result.Error)
+	}
+	fmt.Fprintln(log)
+}
+
+// close stops the progress display and closes the logs
+func (t *upgradeTracker) close() {
+	if t.progress != nil {
+		t.progress.Stop()
+	}
+	t.logs.Close()
+}
+
+// dedupeUpgrades merges registered entries of the same package (same provider and ID) in several
+// profiles into one item, sorted by provider and name
+func dedupeUpgrades(packages []config.PackageConfig) []upgradeItem {
+	index := make(map[string]int)
+	var items []upgradeItem
+	for _, pkg := range packages {
+		id := pkg.ID
+		if p, err := provider.New(pkg.Provider); err == nil {
+			id = provider.NormalizeID(p, pkg.ID)
+		}
+		key := pkg.Provider + "\x00" + id
+		if i, ok := index[key]; ok {
+			items[i].Profiles = append(items[i].Profiles, pkg.Profile)
+			if len(items[i].Options) == 0 {
+				items[i].Options = pkg.Options
+			}
+			continue
+		}
+		index[key] = len(items)
+		items = append(items, upgradeItem{Name: pkg.Name, ID: pkg.ID, Provider: pkg.Provider, Profiles: []string{pkg.Profile}, Options: pkg.Options})
+	}
+
+	for i := range items {
+		sort.Strings(items[i].Profiles)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Provider != items[j].Provider {
+			return items[i].Provider < items[j].Provider
+		}
+		return items[i].Name < items[j].Name
+	})
+	return items
+}
+
+// executeUpgrades upgrades items, running providers concurrently. Provider output is logged per
+// package; on a terminal a spinner per package is shown instead, otherwise the output goes to
+// progress, prefixed with the provider name when more than one provider runs. When ctx is canceled,
+// running commands are interrupted and packages that did not finish are reported as cancelled.
+func executeUpgrades(ctx context.Context, items []upgradeItem, held []heldPackage, progress io.Writer) *upgradeReport {
+	start := time.Now()
+	tracker := newUpgradeTracker(ctx, items, progress)
+
+	byProvider := make(map[string][]upgradeItem)
+	var providerNames []string
+	for _, item := range items {
+		if _, ok := byProvider[item.Provider]; !ok {
+			providerNames = append(providerNames, item.Provider)
+		}
+		byProvider[item.Provider] = append(byProvider[item.Provider], item)
+	}
+	sort.Strings(providerNames)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	results := make([][]upgradeResult, len(providerNames))
+	for i, providerName := range providerNames {
+		stdout, stderr := progress, io.Writer(os.Stderr)
+		var flush []*output.PrefixWriter
+		if tracker.progress != nil {
+			stdout, stderr = io.Discard, io.Discard
+		} else if len(providerNames) > 1 {
+			prefix := "[" + providerName + "] "
+			out := output.NewPrefixWriter(progress, prefix, &mu)
+			errOut := output.NewPrefixWriter(os.Stderr, prefix, &mu)
+			stdout, stderr = out, errOut
+			flush = append(flush, out, errOut)
+		}
+
+		wg.Add(1)
+		go func(i int, providerName string, stdout, stderr io.Writer, flush []*output.PrefixWriter) {
+			defer wg.Done()
+			results[i] = upgradeProviderPackages(ctx, providerName, byProvider[providerName], stdout, stderr, tracker)
+			for _, w := range flush {
+				w.Flush()
+			}
+		}(i, providerName, stdout, stderr, flush)
+	}
+	wg.Wait()
+
+	report := &upgradeReport{Results: []upgradeResult{}, Held: []heldResult{}}
+	if tracker.logs != nil {
+		report.Log = tracker.logs.ID
+	}
+	for _, providerResults := range results {
+		for _, result := range providerResults {
+			tracker.logResult(result)
+			switch result.Status {
+			case upgradeStatusFailed:
+				report.Failed++
+			case upgradeStatusCancelled:
+				report.Cancelled++
+			default:
+				report.Succeeded++
+			}
+			report.Results = append(report.Results, result)
+		}
+	}
+	for _, h := range held {
+		report.Held = append(report.Held, heldResult{
+			Name:     h.Package.Name,
+			ID:       h.Package.ID,
+			Provider: h.Package.Provider,
+			Profile:  h.Package.Profile,
+			Reason:   h.Reason,
+		})
+	}
+	tracker.close()
+	report.ElapsedSeconds = time.Since(start).Round(100 * time.Millisecond).Seconds()
+	return report
+}
+
+// upgradeProviderPackages upgrades the packages of one provider: in one batch if the provider
+// supports it, falling back to one by one when the batch fails so that failures can be attributed
+func upgradeProviderPackages(ctx context.Context, providerName string, items []upgradeItem, stdout, stderr io.Writer, tracker *upgradeTracker) []upgradeResult {
+	results := make([]upgradeResult, len(items))
+	for i, item := range items {
+		results[i] = upgradeResult{Name: item.Name, ID: item.ID, Provider: item.Provider, Profiles: item.Profiles}
+	}
+	failAll := func(err error) []upgradeResult {
+		status := upgradeStatusFailed
+		if ctx.Err() != nil {
+			status = upgradeStatusCancelled
+		}
+		for i := range results {
+			results[i].Status = status
+			results[i].Error = err.Error()
+			tracker.finished(items[i], err)
+		}
+		return results
+	}
+
+	if err := ctx.Err(); err != nil {
+		return failAll(err)
+	}
+	p, err := provider.New(providerName)
+	if err != nil {
+		return failAll(err)
+	}
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return failAll(fmt.Errorf("error checking provider installation: %w", err))
+	}
+	if !installed {
+		return failAll(fmt.Errorf("provider '%s' is not installed", providerName))
+	}
+
+	// run calls f for items with the provider's output redirected and logged, and returns f's
+	// captured stderr
+	setter, canRedirect := p.(provider.OutputSetter)
+	run := func(items []upgradeItem, f func() error) (string, error) {
+		var captured bytes.Buffer
+		out, errOut := tracker.writers(items, stdout, io.MultiWriter(stderr, &captured))
+		if canRedirect {
+			setter.SetOutput(out, errOut)
+		}
+		for _, item := range items {
+			tracker.started(item)
+		}
+		err := f()
+		return strings.TrimSpace(captured.String()), err
+	}
+
+	before := installedVersions(ctx, p)
+
+	// interrupted marks packages whose upgrade was running when ctx was canceled; whether they
+	// finished is decided by comparing versions afterwards
+	interrupted := make([]bool, len(items))
+
+	// Packages with options are upgraded one by one, since a batch passes the same arguments to all
+	done := make([]bool, len(items))
+	var batch []upgradeItem
+	var batchIndexes []int
+	for i, item := range items {
+		if len(item.Options) == 0 {
+			batch = append(batch, item)
+			batchIndexes = append(batchIndexes, i)
+		}
+	}
+	if batcher, ok := p.(provider.BatchUpgrader); ok && len(batch) > 1 {
+		ids := make([]string, len(batch))
+		for i, item := range batch {
+			ids[i] = item.ID
+		}
+		_, err := run(batch, func() error { return batcher.UpgradePackages(ctx, ids) })
+		switch {
+		case err == nil:
+			for i, item := range batch {
+				done[batchIndexes[i]] = true
+				tracker.finished(item, nil)
+			}
+		case ctx.Err() != nil:
+			for i, item := range batch {
+				done[batchIndexes[i]] = true
+				interrupted[batchIndexes[i]] = true
+				tracker.finished(item, ctx.Err())
+			}
+		default:
+			out, _ := tracker.writers(batch, stdout, stderr)
+			fmt.Fprintf(out, "Batch upgrade failed (%v); upgrading packages one by one\n", err)
+		}
+	}
+
+	for i, item := range items {
+		if done[i] {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			results[i].Status = upgradeStatusCancelled
+			results[i].Error = err.Error()
+			tracker.finished(item, err)
+			continue
+		}
+		// Providers that install the registered version report the version they installed
+		version := ""
+		captured, err := run([]upgradeItem{item}, func() error {
+			if upgrader, ok := p.(provider.VersionUpgrader); ok && len(item.Options) == 0 {
+				var err error
+				version, err = upgrader.UpgradePackageVersion(ctx, item.ID)
+				return err
+			}
+			return provider.UpgradePackage(ctx, p, item.ID, item.Options)
+		})
+		if ctx.Err() != nil {
+			tracker.finished(item, ctx.Err())
+		} else {
+			tracker.finished(item, err)
+		}
+		switch {
+		case err == nil:
+			if version != "" && ctx.Err() == nil {
+				recordUpgradedVersion(item, version, stdout)
+			}
+		case ctx.Err() != nil:
+			interrupted[i] = true
+		default:
+			results[i].Status = upgradeStatusFailed
+			results[i].Error = err.Error()
+			results[i].Stderr = captured
+		}
+	}
+
+	// After an interruption, versions are still checked (briefly) to record what finished
+	afterCtx := ctx
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		afterCtx, cancel = context.WithTimeout(context.WithoutCancel(ctx), finishTimeout)
+		defer cancel()
+	}
+	after := installedVersions(afterCtx, p)
+
+	for i, item := range items {
+		id := provider.NormalizeID(p, item.ID)
+		beforeVersion, hasBefore := before[id]
+		results[i].Before = beforeVersion
+		if results[i].Status != "" {
+			continue
+		}
+		afterVersion, hasAfter := after[id]
+		results[i].After = afterVersion
+		switch {
+		case interrupted[i] && (!hasBefore || !hasAfter || beforeVersion == afterVersion):
+			results[i].Status = upgradeStatusCancelled
+			results[i].Error = context.Canceled.Error()
+		case !hasBefore || !hasAfter:
+			results[i].Status = upgradeStatusDone
+		case beforeVersion != afterVersion:
+			results[i].Status = upgradeStatusUpgraded
+		default:
+			results[i].Status = upgradeStatusUnchanged
+		}
+	}
+	return results
+}
+
+// recordUpgradedVersion records the version installed by an upgrade in the upgraded profiles
+func recordUpgradedVersion(item upgradeItem, version string, w io.Writer) {
+	if err := config.UpdatePackageVersion(item.ID, item.Provider, version, item.Profiles); err != nil {
+		fmt.Fprintf(w, "Warning: failed to record version %s of %s: %v\n", version, item.Name, err)
+	}
+}
+
+// installedVersions returns installed versions by normalized package ID, or an empty map if the
+// provider cannot list them
+func installedVersions(ctx context.Context, p provider.Provider) map[string]string {
+	versions := make(map[string]string)
+	installed, err := p.ListInstalled(ctx)
+	if err != nil {
+		return versions
+	}
+	for _, pkg := range installed {
+		versions[provider.NormalizeID(p, pkg.ID)] = pkg.Version
+	}
+	return versions
+}
+
+// printUpgradeReport writes the human-readable upgrade summary
+func printUpgradeReport(w io.Writer, report *upgradeReport) error {
+	if len(report.Results) == 0 {
+		fmt.Fprintln(w, "No packages to upgrade.")
+		printHeldResults(w, report.Held)
+		return nil
+	}
+
+	fmt.Fprintln(w, "\nUpgrade summary:")
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "  NAME\tPROVIDER\tVERSION\tSTATUS")
+	for _, result := range report.Results {
+		versions := result.After
+		switch {
+		case result.Status == upgradeStatusUpgraded:
+			versions = fmt.Sprintf("%s → %s", result.Before, result.After)
+		case versions == "":
+			versions = result.Before
+		}
+		fmt.Fprintf(tw, "  %s\t%s\t%s\t%s\n", result.Name, result.Provider, versions, result.Status)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	var failures []upgradeResult
+	for _, result := range report.Results {
+		if result.Status == upgradeStatusFailed {
+			failures = append(failures, result)
+		}
+	}
+	if len(failures) > 0 {
+		fmt.Fprintln(w, "\nFailures:")
+		for _, result := range failures {
+			fmt.Fprintf(w, "  - %s (%s:%s): %s\n", result.Name, result.Provider, result.ID, result.Error)
+			if result.Stderr != "" {
+				for _, line := range strings.Split(result.Stderr, "\n") {
+					fmt.Fprintf(w, "      %s\n", line)
+				}
+			}
+		}
+	}
+
+	printHeldResults(w, report.Held)
+	if report.Cancelled > 0 {
+		fmt.Fprintf(w, "\nUpgrade interrupted: %d succeeded, %d failed, %d cancelled, %d held (%.1fs)\n", report.Succeeded, report.Failed, report.Cancelled, len(report.Held), report.ElapsedSeconds)
+	} else {
+		fmt.Fprintf(w, "\nUpgrade completed: %d succeeded, %d failed, %d held (%.1fs)\n", report.Succeeded, report.Failed, len(report.Held), report.ElapsedSeconds)
+	}
+	if report.Log != "" {
+		fmt.Fprintf(w, "Provider output: al logs %s\n", report.Log)
+	}
+	return nil
+}
+
+// printHeldResults lists packages that were left out of an upgrade
+func printHeldResults(w io.Writer, held []heldResult) {
+	if len(held) == 0 {
+		return
+	}
+	fmt.Fprintln(w, "\nHeld back:")
+	for _, h := range held {
+		fmt.Fprintf(w, "  - %s (%s:%s) [profile: %s]: %s\n", h.Name, h.Provider, h.ID, h.Profile, h.Reason)
+	}
+}
@@ -0,0 +1,10 @@
+package packagecmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// addWhereFlag registers --where, the filter expression shared by list, show, upgrade, and remove
+func addWhereFlag(cmd *cobra.Command, where *string) {
+	cmd.Flags().StringVarP(where, "where", "w", "", "Filter packages with an expression, e.g. 'provider=brew and id^=cask: and stage=trial and installed_at<30d'")
+}
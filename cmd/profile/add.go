@@ -2,12 +2,16 @@ package profile
 
 import (
 	"bufio"
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	packagecmd "github.com/kkato1030/al/cmd/package"
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/provider"
 	"github.com/kkato1030/al/internal/ui"
 	"github.com/spf13/cobra"
 )
@@ -19,6 +23,9 @@ func NewProfileAddCmd() *cobra.Command {
 	var promoteTo string
 	var packageDuplication string
 	var templateName string
+	var noPackages bool
+	var templateVars []string
+	var dryRun bool
 
 	cmd := &cobra.Command{
 		Use:   "add [profile-name]",
@@ -33,12 +40,16 @@ func NewProfileAddCmd() *cobra.Command {
 
 			// If template is specified, use template mode
 			if templateName != "" {
-				return runProfileAddFromTemplate(name, templateName, description)
+				vars, err := parseTemplateVars(templateVars)
+				if err != nil {
+					return err
+				}
+				return runProfileAddFromTemplate(cmd.Context(), name, templateName, description, vars, noPackages, dryRun)
 			}
 
 			// If no arguments provided or flags are not set, use interactive mode
 			if len(args) == 0 || (description == "" && extends == "" && promoteTo == "" && packageDuplication == "") {
-				return runProfileAddInteractive(name, description, extends, promoteTo, packageDuplication)
+				return runProfileAddInteractive(cmd.Context(), name, description, extends, promoteTo, packageDuplication)
 			}
 
 			return runProfileAdd(name, description, extends, promoteTo, packageDuplication)
@@ -50,6 +61,9 @@ func NewProfileAddCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&promoteTo, "promote-to", "p", "", "Target location for promotion")
 	cmd.Flags().StringVar(&packageDuplication, "package-duplication", "", "Package duplication policy: forbid, allow, or warn (default: warn)")
 	cmd.Flags().StringVarP(&templateName, "template", "t", "", "Template name to use for creating profiles")
+	cmd.Flags().BoolVar(&noPackages, "no-packages", false, "Create profiles only; do not register the template's starter packages")
+	cmd.Flags().StringArrayVar(&templateVars, "var", nil, "Template variable as key=value (repeatable), available as {{.Vars.key}}")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show the profiles rendered from the template without saving them")
 
 	return cmd
 }
@@ -137,7 +151,7 @@ func runProfileAdd(name, description, extendsStr, promoteTo, packageDuplication
 }
 
 // runProfileAddFromTemplate creates profiles from a template
-func runProfileAddFromTemplate(profileName, templateName, description string) error {
+func runProfileAddFromTemplate(ctx context.Context, profileName, templateName, description string, vars map[string]string, noPackages, dryRun bool) error {
 	// Get template
 	template, err := config.GetTemplate(templateName)
 	if err != nil {
@@ -158,95 +172,117 @@ func runProfileAddFromTemplate(profileName, templateName, description string) er
 	}
 
 	// Apply template
-	profiles, err := config.ApplyTemplate(template, profileName)
+	profiles, err := config.ApplyTemplate(template, profileName, vars)
 	if err != nil {
 		return fmt.Errorf("error applying template: %w", err)
 	}
 
+	for i := range profiles {
+		// Set description if provided
+		if description != "" {
+			profiles[i].Description = description
+		}
+		// Set default package_duplication if not set
+		if profiles[i].PackageDuplication == "" {
+			profiles[i].PackageDuplication = "warn"
+		}
+	}
+
+	// Validate the whole profile family before anything is saved
+	if err := config.ValidateProfileFamily(profiles); err != nil {
+		return fmt.Errorf("invalid profiles from template '%s': %w", templateName, err)
+	}
+
 	// Sort profiles by dependencies: profiles that are extended by others should be created first
 	profiles = sortProfilesByDependencies(profiles)
 
-	// Validate and save each profile
-	for _, profile := range profiles {
-		// Validate profile name
-		if err := config.ValidateProfileName(profile.Name); err != nil {
-			return fmt.Errorf("invalid profile name '%s': %w", profile.Name, err)
+	if dryRun {
+		data, err := json.MarshalIndent(profiles, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling profiles: %w", err)
 		}
+		fmt.Println(string(data))
+		return nil
+	}
 
-		// Set description if provided
-		if description != "" {
-			profile.Description = description
+	// Save each profile
+	for _, profile := range profiles {
+		if err := config.AddOrUpdateProfile(profile.ProfileConfig); err != nil {
+			return fmt.Errorf("error saving profile '%s': %w", profile.Name, err)
 		}
 
-		// Validate stage
-		if profile.Stage != "" {
-			if err := validateStage(profile.Stage); err != nil {
-				return fmt.Errorf("invalid stage in template: %w", err)
-			}
-		}
+		fmt.Printf("Profile '%s' has been successfully added\n", profile.Name)
+	}
 
-		// Validate extends profile names
-		for _, ext := range profile.Extends {
-			if err := config.ValidateProfileName(ext); err != nil {
-				return fmt.Errorf("invalid extended profile name '%s': %w", ext, err)
-			}
-		}
+	if noPackages {
+		return nil
+	}
 
-		// Validate promoteTo if provided
-		if profile.PromoteTo != "" {
-			if err := config.ValidateProfileName(profile.PromoteTo); err != nil {
-				return fmt.Errorf("invalid promote_to profile name '%s': %w", profile.PromoteTo, err)
-			}
-		}
+	return addTemplatePackages(ctx, profiles)
+}
 
-		// Validate that extended profiles exist (if any)
-		// Note: We validate after sorting, so dependencies should be created first
-		if len(profile.Extends) > 0 {
-			profilesConfig, err := config.LoadProfilesConfig()
+// addTemplatePackages registers (and installs) the starter packages of the applied template profiles,
+// and writes their shell.d snippets. Failures are reported per package so that one broken entry
+// does not leave the rest of the profile family empty.
+func addTemplatePackages(ctx context.Context, profiles []config.TemplateProfile) error {
+	failed := 0
+	for _, profile := range profiles {
+		for _, pkg := range profile.Packages {
+			packageID, err := resolveTemplatePackageID(ctx, pkg)
 			if err != nil {
-				return fmt.Errorf("error loading profiles config: %w", err)
+				fmt.Printf("Warning: skipping package '%s' in profile '%s': %v\n", pkg.Name, profile.Name, err)
+				failed++
+				continue
 			}
 
-			for _, extendName := range profile.Extends {
-				// Check if the extended profile is in the profiles we're about to create
-				foundInNew := false
-				for _, newProfile := range profiles {
-					if newProfile.Name == extendName {
-						foundInNew = true
-						break
-					}
-				}
+			if err := packagecmd.RunPackageAdd(ctx, pkg.Name, pkg.Provider, profile.Name, pkg.Version, pkg.Description, packageID); err != nil {
+				fmt.Printf("Warning: failed to add package '%s' to profile '%s': %v\n", pkg.Name, profile.Name, err)
+				failed++
+				continue
+			}
 
-				if !foundInNew {
-					// Check if it exists in existing profiles
-					found := false
-					for _, p := range profilesConfig.Profiles {
-						if p.Name == extendName {
-							found = true
-							break
-						}
-					}
-					if !found {
-						return fmt.Errorf("profile '%s' specified in extends does not exist", extendName)
-					}
+			for shell, content := range pkg.Shell {
+				if err := config.WriteShellSnippet(packageID, pkg.Provider, "."+shell, content); err != nil {
+					fmt.Printf("Warning: failed to write %s snippet for package '%s': %v\n", shell, pkg.Name, err)
 				}
 			}
 		}
+	}
 
-		// Set default package_duplication if not set
-		if profile.PackageDuplication == "" {
-			profile.PackageDuplication = "warn"
-		}
+	if failed > 0 {
+		return fmt.Errorf("%d template package(s) could not be added", failed)
+	}
+	return nil
+}
 
-		// Save profile
-		if err := config.AddOrUpdateProfile(profile); err != nil {
-			return fmt.Errorf("error saving profile '%s': %w", profile.Name, err)
-		}
+// resolveTemplatePackageID returns the package ID for a template package, detecting it for brew when not set
+func resolveTemplatePackageID(ctx context.Context, pkg config.TemplatePackage) (string, error) {
+	if pkg.ID != "" {
+		return pkg.ID, nil
+	}
 
-		fmt.Printf("Profile '%s' has been successfully added\n", profile.Name)
+	switch pkg.Provider {
+	case "brew":
+		return provider.NewBrewProvider().GeneratePackageID(ctx, pkg.Name)
+	case "mas":
+		return "", fmt.Errorf("id is required for mas packages")
+	default:
+		return pkg.Name, nil
 	}
+}
 
-	return nil
+// parseTemplateVars parses --var key=value flags into a map
+func parseTemplateVars(values []string) (map[string]string, error) {
+	vars := make(map[string]string)
+	for _, v := range values {
+		key, value, ok := strings.Cut(v, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid --var '%s' (expected key=value)", v)
+		}
+		vars[key] = value
+	}
+	return vars, nil
 }
 
 // validateStage validates that stage is either "stable" or "trial"
@@ -259,7 +295,7 @@ func validateStage(stage string) error {
 }
 
 // sortProfilesByDependencies sorts profiles so that profiles that are extended by others come first
-func sortProfilesByDependencies(profiles []config.ProfileConfig) []config.ProfileConfig {
+func sortProfilesByDependencies(profiles []config.TemplateProfile) []config.TemplateProfile {
 	// Create a map of profile names to their indices
 	nameToIndex := make(map[string]int)
 	for i, p := range profiles {
@@ -277,7 +313,7 @@ func sortProfilesByDependencies(profiles []config.ProfileConfig) []config.Profil
 	}
 
 	// Topological sort: profiles with no dependencies or dependencies that are not in the list come first
-	sorted := make([]config.ProfileConfig, 0, len(profiles))
+	sorted := make([]config.TemplateProfile, 0, len(profiles))
 	added := make(map[int]bool)
 
 	// Add profiles that don't extend anything in the list first
@@ -338,7 +374,7 @@ func sortProfilesByDependencies(profiles []config.ProfileConfig) []config.Profil
 	return sorted
 }
 
-func runProfileAddInteractive(name, description, extends, promoteTo, packageDuplication string) error {
+func runProfileAddInteractive(ctx context.Context, name, description, extends, promoteTo, packageDuplication string) error {
 	// First, ask if user wants to use a template
 	templates, err := config.GetAllTemplates()
 	if err != nil {
@@ -381,7 +417,7 @@ func runProfileAddInteractive(name, description, extends, promoteTo, packageDupl
 				fmt.Printf("Description: %s\n", description)
 			}
 
-			return runProfileAddFromTemplate(name, selectedTemplate, description)
+			return runProfileAddFromTemplate(ctx, name, selectedTemplate, description, nil, false, false)
 		}
 	}
 
@@ -2,40 +2,63 @@ package profile
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/spf13/cobra"
 )
 
+// profileColumns are the TSV columns of profile output
+var profileColumns = []string{"name", "stage", "extends", "promote_to", "package_duplication", "description"}
+
 // NewProfileListCmd creates the profile list command
 func NewProfileListCmd() *cobra.Command {
-	return &cobra.Command{
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all profiles",
 		Long:  "List all configured profiles",
-		RunE:  runProfileList,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileList(outputOpts)
+		},
 	}
+
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
 }
 
-func runProfileList(cmd *cobra.Command, args []string) error {
+func runProfileList(outputOpts output.Options) error {
 	profilesConfig, err := config.LoadProfilesConfig()
 	if err != nil {
 		return fmt.Errorf("error loading profiles config: %w", err)
 	}
 
-	if len(profilesConfig.Profiles) == 0 {
-		fmt.Println("No profiles configured")
-		return nil
+	profiles := profilesConfig.Profiles
+	if profiles == nil {
+		profiles = []config.ProfileConfig{}
 	}
 
-	fmt.Println("Configured profiles:")
-	for _, p := range profilesConfig.Profiles {
-		if p.Description != "" {
-			fmt.Printf("  - %s (description: %s)\n", p.Name, p.Description)
-		} else {
-			fmt.Printf("  - %s\n", p.Name)
-		}
-	}
+	return output.Print(outputOpts, output.Result{
+		Data:    profiles,
+		Columns: profileColumns,
+		Table: func(w io.Writer) error {
+			if len(profiles) == 0 {
+				fmt.Fprintln(w, "No profiles configured")
+				return nil
+			}
 
-	return nil
+			fmt.Fprintln(w, "Configured profiles:")
+			for _, p := range profiles {
+				if p.Description != "" {
+					fmt.Fprintf(w, "  - %s (description: %s)\n", p.Name, p.Description)
+				} else {
+					fmt.Fprintf(w, "  - %s\n", p.Name)
+				}
+			}
+			return nil
+		},
+	})
 }
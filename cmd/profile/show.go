@@ -1,16 +1,16 @@
 package profile
 
 import (
-	"encoding/json"
 	"fmt"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/spf13/cobra"
 )
 
 // NewProfileShowCmd creates the profile show command
 func NewProfileShowCmd() *cobra.Command {
-	var outputFormat string
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
 		Use:   "show [profile-name]",
@@ -18,16 +18,16 @@ func NewProfileShowCmd() *cobra.Command {
 		Long:  "Show detailed information about a specific profile",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runProfileShow(args[0], outputFormat)
+			return runProfileShow(args[0], outputOpts)
 		},
 	}
 
-	cmd.Flags().StringVarP(&outputFormat, "output", "o", "json", "Output format (json)")
+	output.AddFlags(cmd, &outputOpts, output.FormatJSON)
 
 	return cmd
 }
 
-func runProfileShow(profileName, outputFormat string) error {
+func runProfileShow(profileName string, outputOpts output.Options) error {
 	profile, err := config.GetProfile(profileName)
 	if err != nil {
 		return fmt.Errorf("error loading profile: %w", err)
@@ -37,20 +37,9 @@ func runProfileShow(profileName, outputFormat string) error {
 		return fmt.Errorf("profile '%s' not found", profileName)
 	}
 
-	switch outputFormat {
-	case "json":
-		return outputJSON(profile)
-	default:
-		return fmt.Errorf("unsupported output format: %s", outputFormat)
-	}
-}
-
-func outputJSON(profile *config.ProfileConfig) error {
-	data, err := json.MarshalIndent(profile, "", "  ")
-	if err != nil {
-		return fmt.Errorf("error marshaling profile to JSON: %w", err)
-	}
-
-	fmt.Println(string(data))
-	return nil
+	return output.Print(outputOpts, output.Result{
+		Data:    profile,
+		Columns: profileColumns,
+		Table:   output.KeyValueTable(profile, profileColumns),
+	})
 }
@@ -3,8 +3,11 @@ package profile
 import (
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/spf13/cobra"
 )
 
@@ -18,12 +21,25 @@ func NewProfileTemplateCmd() *cobra.Command {
 
 	templateCmd.AddCommand(NewProfileTemplateListCmd())
 	templateCmd.AddCommand(NewProfileTemplateShowCmd())
+	templateCmd.AddCommand(NewProfileTemplateAddCmd())
+	templateCmd.AddCommand(NewProfileTemplateEditCmd())
+	templateCmd.AddCommand(NewProfileTemplateRemoveCmd())
+	templateCmd.AddCommand(NewProfileTemplateExportCmd())
+	templateCmd.AddCommand(NewProfileTemplateImportCmd())
 
 	return templateCmd
 }
 
+// templateEntry is a template in list output, marked with whether it is a default template
+type templateEntry struct {
+	config.ProfileTemplate
+	Default bool `json:"default"`
+}
+
 // NewProfileTemplateListCmd creates the template list command
 func NewProfileTemplateListCmd() *cobra.Command {
+	var outputOpts output.Options
+
 	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all available templates",
@@ -34,46 +50,56 @@ func NewProfileTemplateListCmd() *cobra.Command {
 				return fmt.Errorf("error loading templates: %w", err)
 			}
 
-			if len(templates) == 0 {
-				fmt.Println("No templates available")
-				return nil
+			entries := make([]templateEntry, len(templates))
+			for i, tmpl := range templates {
+				entries[i] = templateEntry{ProfileTemplate: tmpl, Default: config.IsDefaultTemplate(tmpl.Name)}
 			}
 
-			fmt.Println("Available templates:")
-			fmt.Println()
+			return output.Print(outputOpts, output.Result{
+				Data:    entries,
+				Columns: []string{"name", "default", "description"},
+				Table: func(w io.Writer) error {
+					printTemplateList(w, entries)
+					return nil
+				},
+			})
+		},
+	}
 
-			// Get default template names
-			defaultTemplates := config.GetDefaultTemplates()
-			defaultNames := make(map[string]bool)
-			for _, dt := range defaultTemplates {
-				defaultNames[dt.Name] = true
-			}
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
 
-			for _, tmpl := range templates {
-				prefix := "  "
-				if defaultNames[tmpl.Name] {
-					prefix = "* "
-				}
-				fmt.Printf("%s%s", prefix, tmpl.Name)
-				if len(tmpl.Profiles) > 0 {
-					fmt.Printf(" (creates %d profile(s))", len(tmpl.Profiles))
-				}
-				fmt.Println()
-			}
+	return cmd
+}
 
-			fmt.Println()
-			fmt.Println("* = default template")
+func printTemplateList(w io.Writer, entries []templateEntry) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No templates available")
+		return
+	}
 
-			return nil
-		},
+	fmt.Fprintln(w, "Available templates:")
+	fmt.Fprintln(w)
+
+	for _, entry := range entries {
+		prefix := "  "
+		if entry.Default {
+			prefix = "* "
+		}
+		fmt.Fprintf(w, "%s%s", prefix, entry.Name)
+		if len(entry.Profiles) > 0 {
+			fmt.Fprintf(w, " (creates %d profile(s))", len(entry.Profiles))
+		}
+		fmt.Fprintln(w)
 	}
 
-	return cmd
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "* = default template")
 }
 
 // NewProfileTemplateShowCmd creates the template show command
 func NewProfileTemplateShowCmd() *cobra.Command {
 	var jsonOutput bool
+	var outputOpts output.Options
 
 	cmd := &cobra.Command{
 		Use:   "show [template-name]",
@@ -88,46 +114,193 @@ func NewProfileTemplateShowCmd() *cobra.Command {
 				return fmt.Errorf("error getting template: %w", err)
 			}
 
+			// --json is kept as a shorthand for --output json
 			if jsonOutput {
-				// Output as JSON
-				data, err := json.MarshalIndent(template, "", "  ")
+				outputOpts.Format = output.FormatJSON
+			}
+
+			return output.Print(outputOpts, output.Result{
+				Data:    template,
+				Columns: []string{"name", "description"},
+				Table: func(w io.Writer) error {
+					printTemplateDetails(w, template)
+					return nil
+				},
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON (same as --output json)")
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
+}
+
+func printTemplateDetails(w io.Writer, template *config.ProfileTemplate) {
+	fmt.Fprintf(w, "Template: %s\n", template.Name)
+	if template.Description != "" {
+		fmt.Fprintf(w, "Description: %s\n", template.Description)
+	}
+	fmt.Fprintf(w, "Profiles to create: %d\n\n", len(template.Profiles))
+
+	for i, profile := range template.Profiles {
+		fmt.Fprintf(w, "Profile %d:\n", i+1)
+		fmt.Fprintf(w, "  Name: %s\n", profile.Name)
+		if profile.Stage != "" {
+			fmt.Fprintf(w, "  Stage: %s\n", profile.Stage)
+		}
+		if profile.Description != "" {
+			fmt.Fprintf(w, "  Description: %s\n", profile.Description)
+		}
+		if len(profile.Extends) > 0 {
+			fmt.Fprintf(w, "  Extends: %v\n", profile.Extends)
+		}
+		if profile.PromoteTo != "" {
+			fmt.Fprintf(w, "  Promote to: %s\n", profile.PromoteTo)
+		}
+		if profile.PackageDuplication != "" {
+			fmt.Fprintf(w, "  Package duplication: %s\n", profile.PackageDuplication)
+		}
+		if len(profile.Packages) > 0 {
+			fmt.Fprintln(w, "  Packages:")
+			for _, pkg := range profile.Packages {
+				fmt.Fprintf(w, "    - %s (%s", pkg.Name, pkg.Provider)
+				if pkg.ID != "" {
+					fmt.Fprintf(w, ":%s", pkg.ID)
+				}
+				fmt.Fprint(w, ")")
+				if len(pkg.Shell) > 0 {
+					fmt.Fprint(w, " [shell]")
+				}
+				fmt.Fprintln(w)
+			}
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+// NewProfileTemplateRemoveCmd creates the template remove command
+func NewProfileTemplateRemoveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "remove <template-name>",
+		Short: "Remove a user-defined template",
+		Long:  "Remove a user-defined profile template. Default templates cannot be removed.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := config.RemoveTemplate(args[0]); err != nil {
+				return fmt.Errorf("error removing template: %w", err)
+			}
+			fmt.Printf("Template '%s' has been successfully removed\n", args[0])
+			return nil
+		},
+	}
+}
+
+// NewProfileTemplateExportCmd creates the template export command
+func NewProfileTemplateExportCmd() *cobra.Command {
+	var outputPath string
+
+	cmd := &cobra.Command{
+		Use:   "export [template-name...]",
+		Short: "Export templates as JSON",
+		Long:  "Export templates in templates.json format. If no template name is given, all user-defined templates are exported.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var templates []config.ProfileTemplate
+			if len(args) == 0 {
+				userConfig, err := config.LoadTemplatesConfig()
 				if err != nil {
-					return fmt.Errorf("error marshaling template: %w", err)
+					return fmt.Errorf("error loading templates config: %w", err)
 				}
+				templates = userConfig.Templates
+			} else {
+				for _, name := range args {
+					template, err := config.GetTemplate(name)
+					if err != nil {
+						return fmt.Errorf("error getting template: %w", err)
+					}
+					templates = append(templates, *template)
+				}
+			}
+
+			data, err := json.MarshalIndent(config.TemplatesConfig{Templates: templates}, "", "  ")
+			if err != nil {
+				return fmt.Errorf("error marshaling templates: %w", err)
+			}
+
+			if outputPath == "" {
 				fmt.Println(string(data))
 				return nil
 			}
+			if err := os.WriteFile(outputPath, append(data, '\n'), 0644); err != nil {
+				return fmt.Errorf("error writing %s: %w", outputPath, err)
+			}
+			fmt.Printf("Exported %d template(s) to %s\n", len(templates), outputPath)
+			return nil
+		},
+	}
 
-			// Output as human-readable format
-			fmt.Printf("Template: %s\n", template.Name)
-			fmt.Printf("Profiles to create: %d\n\n", len(template.Profiles))
+	cmd.Flags().StringVar(&outputPath, "file", "", "Write to file instead of stdout")
 
-			for i, profile := range template.Profiles {
-				fmt.Printf("Profile %d:\n", i+1)
-				fmt.Printf("  Name: %s\n", profile.Name)
-				if profile.Stage != "" {
-					fmt.Printf("  Stage: %s\n", profile.Stage)
-				}
-				if profile.Description != "" {
-					fmt.Printf("  Description: %s\n", profile.Description)
+	return cmd
+}
+
+// NewProfileTemplateImportCmd creates the template import command
+func NewProfileTemplateImportCmd() *cobra.Command {
+	var overwrite bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Import templates from a JSON file",
+		Long:  "Import templates from a JSON file (a single template or the export format). Existing templates with the same name are skipped unless --overwrite is given.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			templates, err := config.LoadTemplatesFile(args[0])
+			if err != nil {
+				return fmt.Errorf("error loading templates: %w", err)
+			}
+
+			// Validate everything first so that a bad file imports nothing
+			for i := range templates {
+				if err := config.ValidateTemplate(&templates[i]); err != nil {
+					return fmt.Errorf("invalid template: %w", err)
 				}
-				if len(profile.Extends) > 0 {
-					fmt.Printf("  Extends: %s\n", fmt.Sprintf("%v", profile.Extends))
+				if config.IsDefaultTemplate(templates[i].Name) {
+					return fmt.Errorf("cannot override default template '%s'", templates[i].Name)
 				}
-				if profile.PromoteTo != "" {
-					fmt.Printf("  Promote to: %s\n", profile.PromoteTo)
+			}
+
+			userConfig, err := config.LoadTemplatesConfig()
+			if err != nil {
+				return fmt.Errorf("error loading templates config: %w", err)
+			}
+			existing := make(map[string]bool)
+			for _, t := range userConfig.Templates {
+				existing[t.Name] = true
+			}
+
+			imported, skipped := 0, 0
+			for _, template := range templates {
+				if existing[template.Name] && !overwrite {
+					fmt.Printf("Skipped template '%s' (already exists, use --overwrite to replace)\n", template.Name)
+					skipped++
+					continue
 				}
-				if profile.PackageDuplication != "" {
-					fmt.Printf("  Package duplication: %s\n", profile.PackageDuplication)
+				if err := config.AddOrUpdateTemplate(template); err != nil {
+					return fmt.Errorf("error saving template '%s': %w", template.Name, err)
 				}
-				fmt.Println()
+				imported++
 			}
 
+			fmt.Printf("Imported %d template(s)", imported)
+			if skipped > 0 {
+				fmt.Printf(". Skipped %d", skipped)
+			}
+			fmt.Println()
 			return nil
 		},
 	}
 
-	cmd.Flags().BoolVarP(&jsonOutput, "json", "j", false, "Output as JSON")
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite existing templates with the same name")
 
 	return cmd
 }
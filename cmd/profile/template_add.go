@@ -0,0 +1,283 @@
+package profile
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/spf13/cobra"
+)
+
+// NewProfileTemplateAddCmd creates the template add command
+func NewProfileTemplateAddCmd() *cobra.Command {
+	var filePath string
+	var overwrite bool
+
+	cmd := &cobra.Command{
+		Use:   "add [template-name]",
+		Short: "Add a user-defined template",
+		Long:  "Add a user-defined profile template. Use --file to load it from a JSON file; otherwise an interactive builder is used.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var name string
+			if len(args) > 0 {
+				name = args[0]
+			}
+
+			if filePath != "" {
+				return runProfileTemplateAddFromFile(name, filePath, overwrite)
+			}
+			return runProfileTemplateAddInteractive(name, overwrite)
+		},
+	}
+
+	cmd.Flags().StringVarP(&filePath, "file", "F", "", "Load the template from a JSON file")
+	cmd.Flags().BoolVar(&overwrite, "overwrite", false, "Overwrite an existing user-defined template with the same name")
+
+	return cmd
+}
+
+// NewProfileTemplateEditCmd creates the template edit command
+func NewProfileTemplateEditCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "edit <template-name>",
+		Short: "Edit a user-defined template in EDITOR",
+		Long:  "Open a user-defined template as JSON in EDITOR (default: vim). The template is validated before it is saved.",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProfileTemplateEdit(args[0])
+		},
+	}
+}
+
+func runProfileTemplateAddFromFile(name, filePath string, overwrite bool) error {
+	templates, err := config.LoadTemplatesFile(filePath)
+	if err != nil {
+		return fmt.Errorf("error loading template file: %w", err)
+	}
+	if len(templates) != 1 {
+		return fmt.Errorf("%s contains %d templates; use 'al profile template import' for multiple templates", filePath, len(templates))
+	}
+
+	template := templates[0]
+	if name != "" {
+		template.Name = name
+	}
+
+	return saveNewTemplate(template, overwrite)
+}
+
+func runProfileTemplateAddInteractive(name string, overwrite bool) error {
+	scanner := bufio.NewScanner(os.Stdin)
+
+	// Get template name
+	if name == "" {
+		fmt.Print("Template name: ")
+		if !scanner.Scan() {
+			return fmt.Errorf("failed to read input")
+		}
+		name = strings.TrimSpace(scanner.Text())
+		if name == "" {
+			return fmt.Errorf("template name is required")
+		}
+	} else {
+		fmt.Printf("Template name: %s\n", name)
+	}
+
+	template := config.ProfileTemplate{Name: name}
+
+	fmt.Print("Description (optional, press Enter to skip): ")
+	if !scanner.Scan() {
+		return fmt.Errorf("failed to read input")
+	}
+	template.Description = strings.TrimSpace(scanner.Text())
+
+	fmt.Println()
+	fmt.Println("Define profiles. Use {{.ProfileName}} for the name given to 'al profile add'.")
+	for {
+		defaultName := ""
+		if len(template.Profiles) == 0 {
+			defaultName = "{{.ProfileName}}"
+			fmt.Printf("Profile name [%s]: ", defaultName)
+		} else {
+			fmt.Print("Profile name (press Enter to finish): ")
+		}
+		if !scanner.Scan() {
+			return fmt.Errorf("failed to read input")
+		}
+		profileName := strings.TrimSpace(scanner.Text())
+		if profileName == "" {
+			if defaultName == "" {
+				break
+			}
+			profileName = defaultName
+		}
+
+		profile := config.TemplateProfile{ProfileConfig: config.ProfileConfig{Name: profileName}}
+
+		fmt.Print("  Stage (stable/trial, optional): ")
+		if !scanner.Scan() {
+			return fmt.Errorf("failed to read input")
+		}
+		profile.Stage = strings.TrimSpace(scanner.Text())
+
+		fmt.Print("  Extends (comma-separated, optional): ")
+		if !scanner.Scan() {
+			return fmt.Errorf("failed to read input")
+		}
+		if extends := strings.TrimSpace(scanner.Text()); extends != "" {
+			for _, e := range strings.Split(extends, ",") {
+				if e = strings.TrimSpace(e); e != "" {
+					profile.Extends = append(profile.Extends, e)
+				}
+			}
+		}
+
+		fmt.Print("  Promote to (optional): ")
+		if !scanner.Scan() {
+			return fmt.Errorf("failed to read input")
+		}
+		profile.PromoteTo = strings.TrimSpace(scanner.Text())
+
+		fmt.Println("  Starter packages as '<provider> <name> [id]' (press Enter to finish):")
+		for {
+			fmt.Print("  Package: ")
+			if !scanner.Scan() {
+				return fmt.Errorf("failed to read input")
+			}
+			fields := strings.Fields(scanner.Text())
+			if len(fields) == 0 {
+				break
+			}
+			if len(fields) < 2 {
+				fmt.Println("  Please enter '<provider> <name> [id]'")
+				continue
+			}
+			pkg := config.TemplatePackage{Provider: fields[0], Name: fields[1]}
+			if len(fields) > 2 {
+				pkg.ID = fields[2]
+			}
+			profile.Packages = append(profile.Packages, pkg)
+		}
+
+		template.Profiles = append(template.Profiles, profile)
+	}
+
+	return saveNewTemplate(template, overwrite)
+}
+
+// saveNewTemplate validates and saves a template, refusing to replace an existing one unless overwrite is set
+func saveNewTemplate(template config.ProfileTemplate, overwrite bool) error {
+	if err := config.ValidateTemplate(&template); err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+
+	if !overwrite {
+		userConfig, err := config.LoadTemplatesConfig()
+		if err != nil {
+			return fmt.Errorf("error loading templates config: %w", err)
+		}
+		for _, t := range userConfig.Templates {
+			if t.Name == template.Name {
+				return fmt.Errorf("template '%s' already exists (use --overwrite to replace it)", template.Name)
+			}
+		}
+	}
+
+	if err := config.AddOrUpdateTemplate(template); err != nil {
+		return fmt.Errorf("error saving template: %w", err)
+	}
+
+	fmt.Printf("Template '%s' has been successfully saved\n", template.Name)
+	return nil
+}
+
+func runProfileTemplateEdit(name string) error {
+	if config.IsDefaultTemplate(name) {
+		return fmt.Errorf("cannot edit default template '%s'. Export it and add it under a new name instead", name)
+	}
+
+	template, err := config.GetTemplate(name)
+	if err != nil {
+		return fmt.Errorf("error getting template: %w", err)
+	}
+
+	data, err := json.MarshalIndent(template, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshaling template: %w", err)
+	}
+
+	tmpFile, err := os.CreateTemp("", "al-template-*.json")
+	if err != nil {
+		return fmt.Errorf("error creating temp file: %w", err)
+	}
+	tmpPath := tmpFile.Name()
+	defer os.Remove(tmpPath)
+	if _, err := tmpFile.Write(append(data, '\n')); err != nil {
+		tmpFile.Close()
+		return fmt.Errorf("error writing temp file: %w", err)
+	}
+	tmpFile.Close()
+
+	editor := os.Getenv("EDITOR")
+	if editor == "" {
+		editor = "vim"
+	}
+
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		editorCmd := exec.Command(editor, tmpPath)
+		editorCmd.Stdin = os.Stdin
+		editorCmd.Stdout = os.Stdout
+		editorCmd.Stderr = os.Stderr
+		if err := editorCmd.Run(); err != nil {
+			return fmt.Errorf("running %s: %w", editor, err)
+		}
+
+		edited, err := parseEditedTemplate(tmpPath)
+		if err == nil {
+			if edited.Name != name {
+				if _, err := config.GetTemplate(edited.Name); err == nil {
+					return fmt.Errorf("cannot rename template to '%s': template already exists", edited.Name)
+				}
+			}
+			if err := config.AddOrUpdateTemplate(*edited); err != nil {
+				return fmt.Errorf("error saving template: %w", err)
+			}
+			if edited.Name != name {
+				if err := config.RemoveTemplate(name); err != nil {
+					return fmt.Errorf("error removing old template '%s': %w", name, err)
+				}
+			}
+			fmt.Printf("Template '%s' has been successfully updated\n", edited.Name)
+			return nil
+		}
+
+		fmt.Printf("Invalid template: %v\n", err)
+		fmt.Print("Re-open the editor? [Y/n]: ")
+		response, _ := reader.ReadString('\n')
+		response = strings.TrimSpace(strings.ToLower(response))
+		if response == "n" || response == "no" {
+			return fmt.Errorf("edit cancelled; template '%s' was not changed", name)
+		}
+	}
+}
+
+// parseEditedTemplate parses and validates a single template from an edited file
+func parseEditedTemplate(path string) (*config.ProfileTemplate, error) {
+	templates, err := config.LoadTemplatesFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(templates) != 1 {
+		return nil, fmt.Errorf("expected exactly one template, found %d", len(templates))
+	}
+	if err := config.ValidateTemplate(&templates[0]); err != nil {
+		return nil, err
+	}
+	return &templates[0], nil
+}
@@ -9,32 +9,44 @@ import (
 
 // NewProviderAddCmd creates the provider add command
 func NewProviderAddCmd() *cobra.Command {
-	return &cobra.Command{
+	var backend string
+
+	cmd := &cobra.Command{
 		Use:   "add <provider-name>",
 		Short: "Add a provider",
-		Long:  "Add and install a package manager provider",
+		Long:  "Add and install a package manager provider. Providers that do not run on this operating system (e.g. mas on Linux, apt on macOS) cannot be added. Use --backend to choose the tool of a provider that supports several (pytool: pipx or uv; runtime: mise or asdf; vscode: the editor CLI, e.g. code, cursor, or codium).",
 		Args:  cobra.ExactArgs(1),
-		RunE:  runProviderAdd,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProviderAdd(cmd, args[0], backend)
+		},
 	}
+
+	cmd.Flags().StringVar(&backend, "backend", "", "Tool the provider runs (pytool: pipx or uv; runtime: mise or asdf; vscode: code, cursor, codium, ...)")
+
+	return cmd
 }
 
-func runProviderAdd(cmd *cobra.Command, args []string) error {
-	providerName := args[0]
-	var p provider.Provider
+func runProviderAdd(cmd *cobra.Command, providerName, backend string) error {
+	p, err := provider.New(providerName)
+	if err != nil {
+		return fmt.Errorf("unknown provider: %s\nAvailable providers: %s", providerName, provider.AvailableNames())
+	}
+	if err := provider.CheckHost(providerName); err != nil {
+		return err
+	}
 
-	switch providerName {
-	case "brew":
-		p = provider.NewBrewProvider()
-	case "mas":
-		p = provider.NewMasProvider()
-	case "manual":
-		p = provider.NewManualProvider()
-	default:
-		return fmt.Errorf("unknown provider: %s\nAvailable providers: brew, mas, manual", providerName)
+	if backend != "" {
+		selector, ok := p.(provider.BackendSelector)
+		if !ok {
+			return fmt.Errorf("provider %s does not support --backend", providerName)
+		}
+		if err := selector.SetBackend(backend); err != nil {
+			return err
+		}
 	}
 
 	// Check if already installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(cmd.Context())
 	if err != nil {
 		return fmt.Errorf("error checking installation: %w", err)
 	}
@@ -42,7 +54,7 @@ func runProviderAdd(cmd *cobra.Command, args []string) error {
 	if installed {
 		fmt.Printf("%s is already installed\n", providerName)
 		// Still set up config in case it's not configured
-		if err := p.SetupConfig(); err != nil {
+		if err := p.SetupConfig(cmd.Context()); err != nil {
 			fmt.Printf("Warning: failed to set up config: %v\n", err)
 		}
 		return nil
@@ -50,13 +62,13 @@ func runProviderAdd(cmd *cobra.Command, args []string) error {
 
 	// Install the provider
 	fmt.Printf("Installing %s...\n", providerName)
-	if err := p.Install(); err != nil {
+	if err := p.Install(cmd.Context()); err != nil {
 		return fmt.Errorf("error installing %s: %w", providerName, err)
 	}
 
 	// Set up config
 	fmt.Printf("Setting up configuration for %s...\n", providerName)
-	if err := p.SetupConfig(); err != nil {
+	if err := p.SetupConfig(cmd.Context()); err != nil {
 		return fmt.Errorf("error setting up config: %w", err)
 	}
 
@@ -2,43 +2,66 @@ package provider
 
 import (
 	"fmt"
+	"io"
 
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/output"
 	"github.com/spf13/cobra"
 )
 
 // NewProviderListCmd creates the provider list command
 func NewProviderListCmd() *cobra.Command {
-	return &cobra.Command{
+	var outputOpts output.Options
+
+	cmd := &cobra.Command{
 		Use:   "list",
 		Short: "List all providers",
 		Long:  "List all installed package manager providers",
-		RunE:  runProviderList,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runProviderList(outputOpts)
+		},
 	}
+
+	output.AddFlags(cmd, &outputOpts, output.FormatTable)
+
+	return cmd
 }
 
-func runProviderList(cmd *cobra.Command, args []string) error {
-	config, err := config.LoadProvidersConfig()
+func runProviderList(outputOpts output.Options) error {
+	providersConfig, err := config.LoadProvidersConfig()
 	if err != nil {
 		return fmt.Errorf("error loading providers config: %w", err)
 	}
 
-	if len(config.Providers) == 0 {
-		fmt.Println("No providers installed")
-		return nil
+	providers := providersConfig.Providers
+	if providers == nil {
+		providers = []config.ProviderConfig{}
 	}
 
-	fmt.Println("Installed providers:")
-	for _, p := range config.Providers {
-		fmt.Printf("  - %s", p.Name)
-		if p.Version != "" {
-			fmt.Printf(" (version: %s)", p.Version)
-		}
-		if !p.InstalledAt.IsZero() {
-			fmt.Printf(" (installed at: %s)", p.InstalledAt.Format("2006-01-02 15:04:05"))
-		}
-		fmt.Println()
-	}
+	return output.Print(outputOpts, output.Result{
+		Data:    providers,
+		Columns: []string{"name", "version", "installed_at"},
+		Table: func(w io.Writer) error {
+			if len(providers) == 0 {
+				fmt.Fprintln(w, "No providers installed")
+				return nil
+			}
 
-	return nil
+			fmt.Fprintln(w, "Installed providers:")
+			for _, p := range providers {
+				fmt.Fprintf(w, "  - %s", p.Name)
+				if p.Version != "" {
+					fmt.Fprintf(w, " (version: %s)", p.Version)
+				}
+				if p.Backend != "" {
+					fmt.Fprintf(w, " (backend: %s)", p.Backend)
+				}
+				if !p.InstalledAt.IsZero() {
+					fmt.Fprintf(w, " (installed at: %s)", p.InstalledAt.Format("2006-01-02 15:04:05"))
+				}
+				fmt.Fprintln(w)
+			}
+			return nil
+		},
+	})
 }
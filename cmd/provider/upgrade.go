@@ -1,10 +1,13 @@
 package provider
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
+	packagecmd "github.com/kkato1030/al/cmd/package"
 	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/oplog"
 	"github.com/kkato1030/al/internal/provider"
 	"github.com/spf13/cobra"
 )
@@ -12,46 +15,60 @@ import (
 // NewProviderUpgradeCmd creates the provider upgrade command
 func NewProviderUpgradeCmd() *cobra.Command {
 	var yes bool
+	var scope packagecmd.UpgradeScope
 
 	cmd := &cobra.Command{
 		Use:   "upgrade [provider-name]",
 		Short: "Upgrade provider(s)",
-		Long:  "Upgrade a specific provider or all providers. If provider-name is not provided, all providers will be upgraded.",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Upgrade a specific provider or all providers. If provider-name is not provided, all providers will be upgraded.
+With --profile or --stage, only providers used by packages of that profile or stage are upgraded.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
-				return runProviderUpgradeAll(yes)
+				return runProviderUpgradeAll(cmd.Context(), yes, scope)
 			}
-			return runProviderUpgrade(args[0])
+			logs := oplog.StartOrWarn("provider-upgrade")
+			defer logs.Close()
+			return runProviderUpgrade(cmd.Context(), args[0], logs)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	packagecmd.AddUpgradeScopeFlags(cmd, &scope, "Provider name to leave out (repeatable)")
 
 	return cmd
 }
 
-// RunProviderUpgradeAll upgrades all providers
-func RunProviderUpgradeAll(yes bool) error {
-	return runProviderUpgradeAll(yes)
+// RunProviderUpgradeAll upgrades all providers within scope
+func RunProviderUpgradeAll(ctx context.Context, yes bool, scope packagecmd.UpgradeScope) error {
+	return runProviderUpgradeAll(ctx, yes, scope)
 }
 
-func runProviderUpgradeAll(yes bool) error {
+func runProviderUpgradeAll(ctx context.Context, yes bool, scope packagecmd.UpgradeScope) error {
 	// Load providers config
 	providersConfig, err := config.LoadProvidersConfig()
 	if err != nil {
 		return fmt.Errorf("error loading providers config: %w", err)
 	}
 
-	if len(providersConfig.Providers) == 0 {
+	providers, err := providersInScope(providersConfig.Providers, scope)
+	if err != nil {
+		return err
+	}
+
+	if len(providers) == 0 {
 		fmt.Println("No providers found.")
 		return nil
 	}
 
 	// Ask for confirmation
 	if !yes {
-		fmt.Printf("This will upgrade all %d provider(s):\n", len(providersConfig.Providers))
-		for _, p := range providersConfig.Providers {
+		if scope.IsZero() {
+			fmt.Printf("This will upgrade all %d provider(s):\n", len(providers))
+		} else {
+			fmt.Printf("This will upgrade %d provider(s):\n", len(providers))
+		}
+		for _, p := range providers {
 			fmt.Printf("  - %s", p.Name)
 			if p.Version != "" {
 				fmt.Printf(" (current version: %s)", p.Version)
@@ -68,9 +85,14 @@ func runProviderUpgradeAll(yes bool) error {
 	}
 
 	// Upgrade each provider
-	for _, providerConfig := range providersConfig.Providers {
+	logs := oplog.StartOrWarn("provider-upgrade")
+	defer logs.Close()
+	for i, providerConfig := range providers {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("provider upgrade interrupted after %d of %d provider(s): %w", i, len(providers), err)
+		}
 		fmt.Printf("\nUpgrading provider: %s\n", providerConfig.Name)
-		if err := runProviderUpgrade(providerConfig.Name); err != nil {
+		if err := runProviderUpgrade(ctx, providerConfig.Name, logs); err != nil {
 			fmt.Printf("Error upgrading %s: %v\n", providerConfig.Name, err)
 			continue
 		}
@@ -80,7 +102,48 @@ func runProviderUpgradeAll(yes bool) error {
 	return nil
 }
 
-func runProviderUpgrade(providerName string) error {
+// providersInScope returns the providers selected by scope: --provider and --exclude match provider
+// names, and --profile/--stage keep the providers used by packages of that profile or stage
+func providersInScope(providers []config.ProviderConfig, scope packagecmd.UpgradeScope) ([]config.ProviderConfig, error) {
+	var used map[string]bool
+	if scope.Profile != "" || scope.Stage != "" {
+		packagesConfig, err := config.LoadPackagesConfig()
+		if err != nil {
+			return nil, fmt.Errorf("error loading packages config: %w", err)
+		}
+		packageScope := packagecmd.UpgradeScope{Profile: scope.Profile, Stage: scope.Stage}
+		packages, err := packageScope.FilterPackages(packagesConfig.Packages)
+		if err != nil {
+			return nil, err
+		}
+		used = make(map[string]bool)
+		for _, pkg := range packages {
+			used[pkg.Provider] = true
+		}
+	}
+
+	excluded := make(map[string]bool, len(scope.Exclude))
+	for _, name := range scope.Exclude {
+		excluded[name] = true
+	}
+
+	var selected []config.ProviderConfig
+	for _, p := range providers {
+		if scope.Provider != "" && p.Name != scope.Provider {
+			continue
+		}
+		if excluded[p.Name] {
+			continue
+		}
+		if used != nil && !used[p.Name] {
+			continue
+		}
+		selected = append(selected, p)
+	}
+	return selected, nil
+}
+
+func runProviderUpgrade(ctx context.Context, providerName string, logs *oplog.Run) error {
 	// Validate provider exists
 	providerConfig, err := config.GetProvider(providerName)
 	if err != nil {
@@ -91,18 +154,13 @@ func runProviderUpgrade(providerName string) error {
 	}
 
 	// Get provider instance
-	var p provider.Provider
-	switch providerName {
-	case "brew":
-		p = provider.NewBrewProvider()
-	case "mas":
-		p = provider.NewMasProvider()
-	default:
-		return fmt.Errorf("unknown provider: %s\nAvailable providers: brew, mas", providerName)
+	p, err := provider.New(providerName)
+	if err != nil {
+		return fmt.Errorf("unknown provider: %s\nAvailable providers: %s", providerName, provider.AvailableNames())
 	}
 
 	// Check if provider is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("error checking installation: %w", err)
 	}
@@ -111,7 +169,8 @@ func runProviderUpgrade(providerName string) error {
 	}
 
 	// Upgrade the provider
-	if err := p.Upgrade(); err != nil {
+	logs.Attach(p, providerName)
+	if err := p.Upgrade(ctx); err != nil {
 		return fmt.Errorf("error upgrading %s: %w", providerName, err)
 	}
 
@@ -46,10 +46,13 @@ Use "{{.CommandPath}} [command] --help" for more information about a command.{{e
 `
 	rootCmd.SetHelpTemplate(helpTemplate)
 
+	rootCmd.PersistentFlags().Bool("verbose", false, "Show verbose output (e.g. the command an alias resolves to)")
+
 	rootCmd.AddCommand(NewVersionCmd())
 	rootCmd.AddCommand(NewUpdateCmd())
 	rootCmd.AddCommand(NewUpgradeCmd())
 	rootCmd.AddCommand(NewActivateCmd())
+	rootCmd.AddCommand(NewLogsCmd())
 	rootCmd.AddCommand(configcmd.NewConfigCmd())
 	rootCmd.AddCommand(linkcmd.NewLinkCmd())
 	rootCmd.AddCommand(provider.NewProviderCmd())
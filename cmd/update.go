@@ -1,12 +1,8 @@
 package cmd
 
 import (
-	"archive/tar"
-	"compress/gzip"
-	"encoding/json"
+	"context"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -14,26 +10,15 @@ import (
 	"strings"
 	"syscall"
 
+	"github.com/kkato1030/al/internal/download"
 	"github.com/spf13/cobra"
 )
 
 const (
 	githubOwner = "kkato1030"
 	githubRepo  = "al"
-	githubAPI   = "https://api.github.com"
 )
 
-type Release struct {
-	TagName string `json:"tag_name"`
-	Name    string `json:"name"`
-	Assets  []Asset `json:"assets"`
-}
-
-type Asset struct {
-	Name               string `json:"name"`
-	BrowserDownloadURL string `json:"browser_download_url"`
-}
-
 // NewUpdateCmd creates the update command
 func NewUpdateCmd() *cobra.Command {
 	return &cobra.Command{
@@ -41,12 +26,12 @@ func NewUpdateCmd() *cobra.Command {
 		Short: "Check for updates and update al to the latest version",
 		Long:  "Check for the latest version of al and update if a newer version is available.",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUpdate()
+			return runUpdate(cmd.Context())
 		},
 	}
 }
 
-func runUpdate() error {
+func runUpdate(ctx context.Context) error {
 	fmt.Println("Checking for updates...")
 
 	// Get current version
@@ -56,7 +41,7 @@ func runUpdate() error {
 	}
 
 	// Get latest release
-	latestRelease, err := getLatestRelease()
+	latestRelease, err := download.LatestRelease(ctx, download.GitHubAPI, githubOwner, githubRepo)
 	if err != nil {
 		return fmt.Errorf("failed to get latest release: %w", err)
 	}
@@ -83,28 +68,7 @@ func runUpdate() error {
 	}
 
 	// Perform update
-	return performUpdate(latestRelease)
-}
-
-func getLatestRelease() (*Release, error) {
-	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", githubAPI, githubOwner, githubRepo)
-
-	resp, err := http.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("failed to get latest release: status %d", resp.StatusCode)
-	}
-
-	var release Release
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, err
-	}
-
-	return &release, nil
+	return performUpdate(ctx, latestRelease)
 }
 
 func isNewerVersion(latest, current string) bool {
@@ -144,7 +108,7 @@ func isNewerVersion(latest, current string) bool {
 	return false
 }
 
-func performUpdate(release *Release) error {
+func performUpdate(ctx context.Context, release *download.Release) error {
 	// Get current binary path
 	currentBinary, err := os.Executable()
 	if err != nil {
@@ -198,13 +162,13 @@ func performUpdate(release *Release) error {
 
 	// Download the archive
 	archivePath := filepath.Join(tmpDir, assetName)
-	if err := downloadFile(downloadURL, archivePath); err != nil {
+	if err := download.File(ctx, downloadURL, archivePath); err != nil {
 		return fmt.Errorf("failed to download: %w", err)
 	}
 
 	// Extract the archive
 	binaryPath := filepath.Join(tmpDir, "al")
-	if err := extractTarGz(archivePath, tmpDir); err != nil {
+	if err := download.Extract(archivePath, "al", binaryPath); err != nil {
 		return fmt.Errorf("failed to extract: %w", err)
 	}
 
@@ -267,67 +231,3 @@ func performUpdate(release *Release) error {
 	fmt.Printf("Successfully updated to version %s!\n", strings.TrimPrefix(release.TagName, "v"))
 	return nil
 }
-
-func downloadFile(url, dest string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("failed to download: status %d", resp.StatusCode)
-	}
-
-	out, err := os.Create(dest)
-	if err != nil {
-		return err
-	}
-	defer out.Close()
-
-	_, err = io.Copy(out, resp.Body)
-	return err
-}
-
-func extractTarGz(archivePath, destDir string) error {
-	file, err := os.Open(archivePath)
-	if err != nil {
-		return err
-	}
-	defer file.Close()
-
-	gzr, err := gzip.NewReader(file)
-	if err != nil {
-		return err
-	}
-	defer gzr.Close()
-
-	tr := tar.NewReader(gzr)
-
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
-
-		// Only extract the binary file
-		if header.Name == "al" {
-			target := filepath.Join(destDir, header.Name)
-			outFile, err := os.Create(target)
-			if err != nil {
-				return err
-			}
-
-			if _, err := io.Copy(outFile, tr); err != nil {
-				outFile.Close()
-				return err
-			}
-			outFile.Close()
-		}
-	}
-
-	return nil
-}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -12,28 +13,35 @@ import (
 // NewUpgradeCmd creates the upgrade command
 func NewUpgradeCmd() *cobra.Command {
 	var yes bool
+	var scope packagecmd.UpgradeScope
 
 	cmd := &cobra.Command{
 		Use:   "upgrade",
 		Short: "Upgrade all providers and packages",
-		Long:  "Upgrade all providers and packages. This is equivalent to running 'al provider upgrade' followed by 'al package upgrade'.",
+		Long: `Upgrade all providers and packages. This is equivalent to running 'al provider upgrade' followed by 'al package upgrade'.
+--profile, --stage, and --provider limit both steps; --exclude leaves out packages.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runUpgrade(yes)
+			return runUpgrade(cmd.Context(), yes, scope)
 		},
 	}
 
 	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompt")
+	packagecmd.AddUpgradeScopeFlags(cmd, &scope, "Package name or ID to leave out (repeatable)")
 
 	return cmd
 }
 
-func runUpgrade(yes bool) error {
+func runUpgrade(ctx context.Context, yes bool, scope packagecmd.UpgradeScope) error {
 	// Ask for confirmation
 	if !yes {
-		fmt.Println("This will upgrade all providers and packages.")
+		if scope.IsZero() {
+			fmt.Println("This will upgrade all providers and packages.")
+		} else {
+			fmt.Println("This will upgrade the selected providers and packages.")
+		}
 		fmt.Println("This is equivalent to:")
-		fmt.Println("  1. al provider upgrade")
-		fmt.Println("  2. al package upgrade")
+		fmt.Printf("  1. al provider upgrade%s\n", scopeArgs(scope, false))
+		fmt.Printf("  2. al package upgrade%s\n", scopeArgs(scope, true))
 		fmt.Print("\nDo you want to continue? [y/N]: ")
 		var response string
 		fmt.Scanln(&response)
@@ -45,17 +53,46 @@ func runUpgrade(yes bool) error {
 
 	// Upgrade all providers
 	fmt.Println()
-	if err := providercmd.RunProviderUpgradeAll(true); err != nil {
+	// --exclude names packages, so it is not passed to the provider upgrade
+	providerScope := scope
+	providerScope.Exclude = nil
+	if err := providercmd.RunProviderUpgradeAll(ctx, true, providerScope); err != nil {
 		fmt.Printf("\nError upgrading providers: %v\n", err)
 		// Continue to package upgrade even if provider upgrade fails
 	}
+	if err := ctx.Err(); err != nil {
+		return fmt.Errorf("upgrade interrupted: %w", err)
+	}
 
 	// Upgrade all packages
 	fmt.Println()
-	if err := packagecmd.RunPackageUpgradeAll(true); err != nil {
+	if err := packagecmd.RunPackageUpgradeAll(ctx, true, scope); err != nil {
 		return fmt.Errorf("error upgrading packages: %w", err)
 	}
 
 	fmt.Println("\n✓ All upgrades completed")
 	return nil
 }
+
+// scopeArgs returns the scope as command-line flags, for the confirmation message
+func scopeArgs(scope packagecmd.UpgradeScope, withExclude bool) string {
+	var args []string
+	if scope.Profile != "" {
+		args = append(args, "--profile "+scope.Profile)
+	}
+	if scope.Stage != "" {
+		args = append(args, "--stage "+scope.Stage)
+	}
+	if scope.Provider != "" {
+		args = append(args, "--provider "+scope.Provider)
+	}
+	if withExclude {
+		for _, name := range scope.Exclude {
+			args = append(args, "--exclude "+name)
+		}
+	}
+	if len(args) == 0 {
+		return ""
+	}
+	return " " + strings.Join(args, " ")
+}
@@ -0,0 +1,77 @@
+package brewfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// IsNpmFile reports whether path is an npm global package list (a .json file) rather than a Brewfile
+func IsNpmFile(path string) bool {
+	return strings.HasSuffix(strings.ToLower(path), ".json")
+}
+
+// ParseNpmFile parses a package.json-style list of global npm packages: the "dependencies" object
+// of a package.json ({"typescript": "^5.0.0"}) or of `npm ls -g --json` ({"typescript": {"version": "5.4.2"}}).
+// A version range becomes part of the ID (typescript@^5.0.0); installed versions from npm ls and
+// "*" / "latest" are left out, so that the package follows the latest version.
+func ParseNpmFile(path string) (*ParseResult, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var file struct {
+		Dependencies map[string]json.RawMessage `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("invalid npm package list: %w", err)
+	}
+
+	names := make([]string, 0, len(file.Dependencies))
+	for name := range file.Dependencies {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var entries []Entry
+	var skipped []SkippedLine
+	for _, name := range names {
+		raw := file.Dependencies[name]
+		var spec string
+		if err := json.Unmarshal(raw, &spec); err != nil {
+			// npm ls -g --json lists installed packages as objects
+			var installed struct {
+				Version string `json:"version"`
+			}
+			if err := json.Unmarshal(raw, &installed); err != nil {
+				skipped = append(skipped, SkippedLine{Line: name, Reason: "invalid dependency"})
+				continue
+			}
+			spec = ""
+		}
+		if name == "npm" {
+			// npm itself is the provider
+			skipped = append(skipped, SkippedLine{Line: name, Reason: "npm"})
+			continue
+		}
+
+		id := name
+		if spec != "" && spec != "*" && spec != "latest" {
+			id = name + "@" + spec
+		}
+		entries = append(entries, Entry{Provider: "npm", ID: id, Name: name})
+	}
+
+	return &ParseResult{Entries: entries, Skipped: skipped}, nil
+}
+
+// ParseImportFile parses a file given to import: an npm global package list when IsNpmFile, a Brewfile otherwise
+func ParseImportFile(path string) (*ParseResult, error) {
+	if IsNpmFile(path) {
+		return ParseNpmFile(path)
+	}
+	return ParseFile(path)
+}
@@ -10,14 +10,17 @@ import (
 
 // Entry represents a single parsed Brewfile entry (tap, brew, cask, or mas).
 type Entry struct {
-	Provider string // "brew" or "mas"
-	ID       string // e.g. "formula:ruby", "cask:firefox", "tap:user/repo", "1234567890"
-	Name     string // display name (for mas: app name; for brew: same as package part of ID)
+	Provider string   // "brew", "mas", "npm", "cargo", "go", "vscode", or "flatpak"
+	ID       string   // e.g. "formula:ruby", "cask:firefox", "tap:user/repo", "1234567890"
+	Name     string   // display name (for mas: app name; for brew: same as package part of ID)
+	URL      string   // remote of a tap with a custom URL: tap "user/repo", "https://..."
+	Options  []string // brew install/upgrade options from args:, greedy:, and cask_args (e.g. "--HEAD")
+	Service  string   // "run" for a formula with restart_service: or start_service:
 }
 
 // SkippedLine records a line that was skipped (unsupported or parse error).
 type SkippedLine struct {
-	LineNum int
+	LineNum int // 0 for entries of an npm package list
 	Line    string
 	Reason  string
 }
@@ -29,8 +32,8 @@ type ParseResult struct {
 }
 
 // Line patterns (simplified; we don't run Ruby).
-// tap "user/repo" or tap 'user/repo'
-var tapRegex = regexp.MustCompile(`^\s*tap\s+["']([^"']+)["']`)
+// tap "user/repo" or tap 'user/repo', optionally followed by a custom remote URL
+var tapRegex = regexp.MustCompile(`^\s*tap\s+["']([^"']+)["']\s*(?:,\s*["']([^"']+)["'])?`)
 
 // brew "formula" or brew "formula@16" (optional , ...)
 var brewRegex = regexp.MustCompile(`^\s*brew\s+["']([^"']+)["']`)
@@ -38,6 +41,32 @@ var brewRegex = regexp.MustCompile(`^\s*brew\s+["']([^"']+)["']`)
 // cask "name"
 var caskRegex = regexp.MustCompile(`^\s*cask\s+["']([^"']+)["']`)
 
+// args: ["HEAD", "with-foo"] or args: { appdir: "~/Applications", no_quarantine: true }
+var argsArrayRegex = regexp.MustCompile(`\bargs\s*:\s*\[([^\]]*)\]`)
+var argsHashRegex = regexp.MustCompile(`\bargs\s*:\s*\{([^}]*)\}`)
+
+// greedy: true
+var greedyRegex = regexp.MustCompile(`\bgreedy\s*:\s*true\b`)
+
+// restart_service: true, restart_service: :changed, or start_service: true
+var serviceRegex = regexp.MustCompile(`\b(?:re)?start_service\s*:\s*(?:true|:changed|:always)\b`)
+
+// cask_args appdir: "~/Applications", no_quarantine: true (applies to the casks after it)
+var caskArgsRegex = regexp.MustCompile(`^\s*cask_args\s+(.+)$`)
+
+// cargo "ripgrep"
+var cargoRegex = regexp.MustCompile(`^\s*cargo\s+["']([^"']+)["']`)
+
+// go "golang.org/x/tools/gopls"
+var goRegex = regexp.MustCompile(`^\s*go\s+["']([^"']+)["']`)
+
+// vscode "ms-python.python"
+var vscodeRegex = regexp.MustCompile(`^\s*vscode\s+["']([^"']+)["']`)
+
+// flatpak "org.mozilla.firefox", optionally with remote: "flathub-beta" (url: is not used; add the remote first)
+var flatpakRegex = regexp.MustCompile(`^\s*flatpak\s+["']([^"']+)["']`)
+var flatpakRemoteRegex = regexp.MustCompile(`\bremote\s*:\s*["']([^"']+)["']`)
+
 // mas "App Name", id: 1234567890
 var masRegex = regexp.MustCompile(`^\s*mas\s+["']([^"']+)["']\s*,?\s*id\s*:\s*(\d+)`)
 
@@ -46,11 +75,7 @@ var unsupportedPrefixes = []struct {
 	prefix string
 	label  string
 }{
-	{"vscode", "vscode"},
-	{"go ", "go"},
-	{"cargo ", "cargo"},
-	{"flatpak", "flatpak"},
-	{"cask_args", "cask_args"},
+	{"whalebrew", "whalebrew"},
 }
 
 // ParseFile reads path and parses the Brewfile, returning entries and skipped lines.
@@ -65,6 +90,7 @@ func ParseFile(path string) (*ParseResult, error) {
 	var skipped []SkippedLine
 	scanner := bufio.NewScanner(f)
 	lineNum := 0
+	var caskArgs []string
 
 	for scanner.Scan() {
 		lineNum++
@@ -75,12 +101,20 @@ func ParseFile(path string) (*ParseResult, error) {
 			continue
 		}
 
+		if m := caskArgsRegex.FindStringSubmatch(trimmed); len(m) == 2 {
+			caskArgs = parseArgsHash(m[1])
+			continue
+		}
+
 		entry, skipReason := parseLine(trimmed)
 		if skipReason != "" {
 			skipped = append(skipped, SkippedLine{LineNum: lineNum, Line: line, Reason: skipReason})
 			continue
 		}
 		if entry != nil {
+			if strings.HasPrefix(entry.ID, "cask:") && len(caskArgs) > 0 {
+				entry.Options = mergeOptions(caskArgs, entry.Options)
+			}
 			entries = append(entries, *entry)
 		}
 	}
@@ -95,13 +129,13 @@ func ParseFile(path string) (*ParseResult, error) {
 // parseLine parses a single non-empty, non-comment line.
 // Returns (entry, "") if parsed, (nil, reason) if skipped, (nil, "") for unknown/unsupported.
 func parseLine(line string) (*Entry, string) {
-	// tap "user/repo" — only single-arg tap
-	if m := tapRegex.FindStringSubmatch(line); len(m) == 2 {
+	// tap "user/repo" or tap "user/repo", "https://..."
+	if m := tapRegex.FindStringSubmatch(line); len(m) == 3 {
 		tapName := m[1]
 		if strings.Contains(tapName, " ") {
 			return nil, "tap with multiple args"
 		}
-		return &Entry{Provider: "brew", ID: "tap:" + tapName, Name: tapName}, ""
+		return &Entry{Provider: "brew", ID: "tap:" + tapName, Name: tapName, URL: m[2]}, ""
 	}
 	if strings.TrimSpace(line) != "" && (strings.HasPrefix(strings.TrimSpace(line), "tap ") || strings.HasPrefix(strings.TrimSpace(line), "tap\t")) {
 		// tap with URL etc
@@ -111,13 +145,17 @@ func parseLine(line string) (*Entry, string) {
 	// brew "formula"
 	if m := brewRegex.FindStringSubmatch(line); len(m) == 2 {
 		name := m[1]
-		return &Entry{Provider: "brew", ID: "formula:" + name, Name: name}, ""
+		entry := &Entry{Provider: "brew", ID: "formula:" + name, Name: name, Options: parseOptions(line)}
+		if serviceRegex.MatchString(line) {
+			entry.Service = "run"
+		}
+		return entry, ""
 	}
 
 	// cask "name"
 	if m := caskRegex.FindStringSubmatch(line); len(m) == 2 {
 		name := m[1]
-		return &Entry{Provider: "brew", ID: "cask:" + name, Name: name}, ""
+		return &Entry{Provider: "brew", ID: "cask:" + name, Name: name, Options: parseOptions(line)}, ""
 	}
 
 	// mas "App Name", id: 1234567890
@@ -130,6 +168,35 @@ func parseLine(line string) (*Entry, string) {
 		return nil, "mas (missing or invalid id)"
 	}
 
+	// cargo "ripgrep"
+	if m := cargoRegex.FindStringSubmatch(line); len(m) == 2 {
+		name := m[1]
+		return &Entry{Provider: "cargo", ID: name, Name: name}, ""
+	}
+
+	// go "golang.org/x/tools/gopls" (installed with @latest)
+	if m := goRegex.FindStringSubmatch(line); len(m) == 2 {
+		path := m[1]
+		elems := strings.Split(path, "/")
+		return &Entry{Provider: "go", ID: path + "@latest", Name: elems[len(elems)-1]}, ""
+	}
+
+	// vscode "ms-python.python"
+	if m := vscodeRegex.FindStringSubmatch(line); len(m) == 2 {
+		id := m[1]
+		return &Entry{Provider: "vscode", ID: id, Name: id}, ""
+	}
+
+	// flatpak "org.mozilla.firefox", remote: "flathub" (the ID has "<remote>:" for other remotes)
+	if m := flatpakRegex.FindStringSubmatch(line); len(m) == 2 {
+		app := m[1]
+		id := app
+		if r := flatpakRemoteRegex.FindStringSubmatch(line); len(r) == 2 && r[1] != "flathub" {
+			id = r[1] + ":" + app
+		}
+		return &Entry{Provider: "flatpak", ID: id, Name: app}, ""
+	}
+
 	// Unsupported types
 	lower := strings.ToLower(strings.TrimSpace(line))
 	for _, u := range unsupportedPrefixes {
@@ -145,6 +212,114 @@ func parseLine(line string) (*Entry, string) {
 	return nil, ""
 }
 
+// parseOptions returns the brew options of a brew or cask line: args: as an array ("HEAD" becomes
+// "--HEAD") or a hash (see parseArgsHash), and "--greedy" for greedy: true
+func parseOptions(line string) []string {
+	var options []string
+	if m := argsArrayRegex.FindStringSubmatch(line); len(m) == 2 {
+		for _, arg := range splitArgs(m[1]) {
+			arg = unquote(arg)
+			if arg == "" {
+				continue
+			}
+			if !strings.HasPrefix(arg, "-") {
+				arg = "--" + arg
+			}
+			options = append(options, arg)
+		}
+	} else if m := argsHashRegex.FindStringSubmatch(line); len(m) == 2 {
+		options = parseArgsHash(m[1])
+	}
+	if greedyRegex.MatchString(line) {
+		options = append(options, "--greedy")
+	}
+	return options
+}
+
+// parseArgsHash converts the pairs of a Ruby hash the way brew bundle does: key: true becomes
+// "--key", key: false "--no-key", and key: "value" "--key=value" (underscores in keys become dashes)
+func parseArgsHash(hash string) []string {
+	var options []string
+	for _, pair := range splitArgs(hash) {
+		var key, value string
+		if k, v, found := strings.Cut(pair, "=>"); found {
+			key, value = strings.TrimPrefix(unquote(k), ":"), unquote(v)
+		} else if k, v, found := strings.Cut(pair, ":"); found {
+			key, value = unquote(k), unquote(v)
+		} else {
+			continue
+		}
+		if key == "" {
+			continue
+		}
+		key = strings.ReplaceAll(key, "_", "-")
+		switch value {
+		case "true":
+			options = append(options, "--"+key)
+		case "false":
+			options = append(options, "--no-"+key)
+		default:
+			options = append(options, "--"+key+"="+value)
+		}
+	}
+	return options
+}
+
+// mergeOptions returns base with the options of override added, replacing the options of base with
+// the same key (like brew bundle merges cask_args with the args of a cask)
+func mergeOptions(base, override []string) []string {
+	overridden := make(map[string]bool, len(override))
+	for _, option := range override {
+		overridden[optionKey(option)] = true
+	}
+	var options []string
+	for _, option := range base {
+		if !overridden[optionKey(option)] {
+			options = append(options, option)
+		}
+	}
+	return append(options, override...)
+}
+
+// optionKey returns the name of an option: "appdir" for "--appdir=/Applications", "quarantine" for "--no-quarantine"
+func optionKey(option string) string {
+	key, _, _ := strings.Cut(strings.TrimLeft(option, "-"), "=")
+	return strings.TrimPrefix(key, "no-")
+}
+
+// splitArgs splits s at the commas that are not inside quotes
+func splitArgs(s string) []string {
+	var parts []string
+	var quote rune
+	start := 0
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == ',':
+			parts = append(parts, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+	if last := strings.TrimSpace(s[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// unquote trims spaces and the quotes around a Ruby string or symbol literal
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
+
 func looksLikeRubyLine(line string) bool {
 	trimmed := strings.TrimSpace(line)
 	if trimmed == "" {
@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BinaryReceipt records what the binary provider installed for a package
+type BinaryReceipt struct {
+	Version     string    `json:"version"`
+	URL         string    `json:"url"`
+	SHA256      string    `json:"sha256"`
+	Path        string    `json:"path"`
+	InstalledAt time.Time `json:"installed_at"`
+}
+
+// GetBinariesDir returns the path to ~/.al/binaries.d/
+func GetBinariesDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "binaries.d"), nil
+}
+
+// GetBinaryReceiptPath returns the path of a binary package's receipt (~/.al/binaries.d/<id>.json)
+func GetBinaryReceiptPath(id string) (string, error) {
+	binariesDir, err := GetBinariesDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(binariesDir, strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(id)+".json"), nil
+}
+
+// LoadBinaryReceipt loads a binary package's receipt. It returns nil if the package is not installed.
+func LoadBinaryReceipt(id string) (*BinaryReceipt, error) {
+	path, err := GetBinaryReceiptPath(id)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var receipt BinaryReceipt
+	if err := json.Unmarshal(data, &receipt); err != nil {
+		return nil, err
+	}
+	return &receipt, nil
+}
+
+// SaveBinaryReceipt writes a binary package's receipt, creating binaries.d
+func SaveBinaryReceipt(id string, receipt *BinaryReceipt) error {
+	path, err := GetBinaryReceiptPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(receipt, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// RemoveBinaryReceipt removes a binary package's receipt. It is a no-op if there is none.
+func RemoveBinaryReceipt(id string) error {
+	path, err := GetBinaryReceiptPath(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
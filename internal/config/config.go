@@ -2,8 +2,10 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 )
 
 // GetConfigDir returns the configuration directory path.
@@ -47,6 +49,11 @@ type AppConfig struct {
 	DefaultProvider string `json:"default_provider,omitempty"`
 	DefaultProfile  string `json:"default_profile,omitempty"`
 	DefaultStage    string `json:"default_stage,omitempty"`
+	// Aliases holds user-defined aliases. They override default aliases with the same name;
+	// an empty command disables a default alias.
+	Aliases map[string]string `json:"aliases,omitempty"`
+	// Logs configures the retention of operation logs
+	Logs *LogsConfig `json:"logs,omitempty"`
 }
 
 // GetConfigPath returns the path to the config.json file
@@ -140,11 +147,94 @@ func SetDefaultStage(stage string) error {
 // GetDefaultAliases returns the default command aliases
 func GetDefaultAliases() map[string]string {
 	return map[string]string{
-		"promote":  "package move {args} --to package.promote_to",
+		"promote":  "package move {args} --to {package.promote_to}",
 		"add":      "package add",
-		"remove":  "package remove",
-		"list":    "package list",
+		"remove":   "package remove",
+		"list":     "package list",
 		"register": "package add --provider manual",
 		"import":   "package import {args}",
 	}
 }
+
+// validAliasName matches allowed alias names: alphanumeric, underscore, and hyphen
+var validAliasName = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+// ValidateAliasName validates that an alias name can be used as a command name
+func ValidateAliasName(name string) error {
+	if name == "" {
+		return fmt.Errorf("alias name cannot be empty")
+	}
+	if !validAliasName.MatchString(name) {
+		return fmt.Errorf("alias name '%s' contains invalid characters. Only alphanumeric characters, - and _ are allowed", name)
+	}
+	return nil
+}
+
+// GetAliases returns the effective command aliases: default aliases overridden by user-defined ones.
+// Default aliases disabled by the user (empty command) are not included.
+func GetAliases() (map[string]string, error) {
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	aliases := GetDefaultAliases()
+	for name, command := range appConfig.Aliases {
+		if command == "" {
+			delete(aliases, name)
+			continue
+		}
+		aliases[name] = command
+	}
+	return aliases, nil
+}
+
+// SetAlias adds or overrides a user-defined alias
+func SetAlias(name, command string) error {
+	if err := ValidateAliasName(name); err != nil {
+		return err
+	}
+	if command == "" {
+		return fmt.Errorf("alias command cannot be empty")
+	}
+
+	config, err := LoadAppConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.Aliases == nil {
+		config.Aliases = make(map[string]string)
+	}
+	config.Aliases[name] = command
+	return SaveAppConfig(config)
+}
+
+// RemoveAlias removes a user-defined alias.
+// Removing an override of a default alias restores the default; removing a default alias disables it.
+// It returns true if a default alias was restored.
+func RemoveAlias(name string) (restoredDefault bool, err error) {
+	config, err := LoadAppConfig()
+	if err != nil {
+		return false, err
+	}
+
+	_, isDefault := GetDefaultAliases()[name]
+	command, isUser := config.Aliases[name]
+	if !isDefault && !isUser {
+		return false, fmt.Errorf("alias '%s' not found", name)
+	}
+	if isUser && command == "" {
+		return false, fmt.Errorf("alias '%s' is already removed", name)
+	}
+
+	if isUser {
+		delete(config.Aliases, name)
+	} else {
+		if config.Aliases == nil {
+			config.Aliases = make(map[string]string)
+		}
+		config.Aliases[name] = ""
+	}
+	return isUser && isDefault, SaveAppConfig(config)
+}
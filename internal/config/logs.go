@@ -0,0 +1,64 @@
+package config
+
+import (
+	"path/filepath"
+)
+
+// Default retention of operation logs
+const (
+	DefaultLogRetentionDays = 30
+	DefaultLogMaxRuns       = 50
+)
+
+// LogsConfig configures how long operation logs are kept
+type LogsConfig struct {
+	// RetentionDays removes logs older than this many days (0: default, negative: keep regardless of age)
+	RetentionDays int `json:"retention_days,omitempty"`
+	// MaxRuns keeps at most this many operation logs (0: default, negative: no limit)
+	MaxRuns int `json:"max_runs,omitempty"`
+}
+
+// EffectiveRetentionDays returns RetentionDays with the default applied; negative means no limit
+func (c *LogsConfig) EffectiveRetentionDays() int {
+	if c == nil || c.RetentionDays == 0 {
+		return DefaultLogRetentionDays
+	}
+	return c.RetentionDays
+}
+
+// EffectiveMaxRuns returns MaxRuns with the default applied; negative means no limit
+func (c *LogsConfig) EffectiveMaxRuns() int {
+	if c == nil || c.MaxRuns == 0 {
+		return DefaultLogMaxRuns
+	}
+	return c.MaxRuns
+}
+
+// GetLogsDir returns the directory holding operation logs
+func GetLogsDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "logs"), nil
+}
+
+// SetLogRetention sets the log retention; nil leaves a value unchanged
+func SetLogRetention(retentionDays, maxRuns *int) error {
+	config, err := LoadAppConfig()
+	if err != nil {
+		return err
+	}
+
+	if config.Logs == nil {
+		config.Logs = &LogsConfig{}
+	}
+	if retentionDays != nil {
+		config.Logs.RetentionDays = *retentionDays
+	}
+	if maxRuns != nil {
+		config.Logs.MaxRuns = *maxRuns
+	}
+	return SaveAppConfig(config)
+}
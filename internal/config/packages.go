@@ -17,6 +17,75 @@ type PackageConfig struct {
 	Version     string    `json:"version,omitempty"`
 	InstalledAt time.Time `json:"installed_at"`
 	Description string    `json:"description,omitempty"`
+	// Upgrade is the upgrade policy: "auto" (default when empty), "hold", or "manual"
+	Upgrade string `json:"upgrade,omitempty"`
+	// Constraint limits upgrades to versions that satisfy it (e.g. "20", ">=20, <22")
+	Constraint string `json:"constraint,omitempty"`
+	// URL is the remote of a brew tap that is not on GitHub (brew tap user/repo <url>), or of a git package.
+	// For a binary package, it is the download URL template ({version}, {os}, {arch}).
+	URL string `json:"url,omitempty"`
+	// Ref is the branch, tag, or commit a git package follows (empty: the remote's default branch)
+	Ref string `json:"ref,omitempty"`
+	// SHA256 is the checksum of a binary package's download for Version, or the URL template of a
+	// checksums file (e.g. checksums.txt) that lists the download of every version
+	SHA256 string `json:"sha256,omitempty"`
+	// Member is the file of a binary package's archive to install (empty: the file named like the package)
+	Member string `json:"member,omitempty"`
+	// Dir is the directory a binary package is installed to (empty: ~/.local/bin)
+	Dir string `json:"dir,omitempty"`
+	// LatestFrom is where a binary package's latest version is found: "github:owner/repo", or the URL
+	// of a text file with the version (empty: inferred from a GitHub release URL)
+	LatestFrom string `json:"latest_from,omitempty"`
+	// Global marks a runtime package's version as the global default of its runtime in the profile
+	Global bool `json:"global,omitempty"`
+	// Options are extra arguments the provider passes when installing and upgrading (e.g. brew "--HEAD", "--no-quarantine")
+	Options []string `json:"options,omitempty"`
+	// Service is the service policy of a brew formula: "run" (keep it started), "off" (keep it stopped), or empty (unmanaged)
+	Service string `json:"service,omitempty"`
+}
+
+// Upgrade policies
+const (
+	// UpgradeAuto upgrades the package with every upgrade
+	UpgradeAuto = "auto"
+	// UpgradeHold never upgrades the package
+	UpgradeHold = "hold"
+	// UpgradeManual upgrades the package only when it is named explicitly
+	UpgradeManual = "manual"
+)
+
+// UpgradePolicy returns the package's upgrade policy, defaulting to auto
+func (p PackageConfig) UpgradePolicy() string {
+	if p.Upgrade == "" {
+		return UpgradeAuto
+	}
+	return p.Upgrade
+}
+
+// ValidateUpgradePolicy validates an upgrade policy value
+func ValidateUpgradePolicy(policy string) error {
+	switch policy {
+	case "", UpgradeAuto, UpgradeHold, UpgradeManual:
+		return nil
+	}
+	return fmt.Errorf("invalid upgrade policy: %s (must be auto, hold, or manual)", policy)
+}
+
+// Service policies
+const (
+	// ServiceRun keeps the package's service started
+	ServiceRun = "run"
+	// ServiceOff keeps the package's service stopped
+	ServiceOff = "off"
+)
+
+// ValidateServicePolicy validates a service policy value
+func ValidateServicePolicy(policy string) error {
+	switch policy {
+	case "", ServiceRun, ServiceOff:
+		return nil
+	}
+	return fmt.Errorf("invalid service policy: %s (must be run or off)", policy)
 }
 
 // PackagesConfig represents the collection of package configurations
@@ -129,6 +198,32 @@ func AddOrUpdatePackage(pkg PackageConfig) error {
 	return SavePackagesConfig(config)
 }
 
+// UpdatePackageVersion sets the version of the package (same id and provider) in the given profiles.
+// packages.json is only written when a version changes.
+func UpdatePackageVersion(id, provider, version string, profiles []string) error {
+	config, err := LoadPackagesConfig()
+	if err != nil {
+		return err
+	}
+	changed := false
+	for i, pkg := range config.Packages {
+		if pkg.ID != id || pkg.Provider != provider || pkg.Version == version {
+			continue
+		}
+		for _, profile := range profiles {
+			if pkg.Profile == profile {
+				config.Packages[i].Version = version
+				changed = true
+				break
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+	return SavePackagesConfig(config)
+}
+
 // SamePackageInOtherProfile returns true if the same package (same id and provider) exists in at least one other profile.
 // Used to decide whether to uninstall when removing from a profile (only uninstall when this is the last profile).
 func SamePackageInOtherProfile(id, provider, profile string) (bool, error) {
@@ -144,6 +239,20 @@ func SamePackageInOtherProfile(id, provider, profile string) (bool, error) {
 	return false, nil
 }
 
+// HasPackage returns true if a package with id and provider exists in any profile
+func HasPackage(id, provider string) (bool, error) {
+	config, err := LoadPackagesConfig()
+	if err != nil {
+		return false, err
+	}
+	for _, pkg := range config.Packages {
+		if pkg.ID == id && pkg.Provider == provider {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 // RemovePackage removes a package from the configuration
 // Package is identified by id, provider, and profile combination
 func RemovePackage(id, provider, profile string) error {
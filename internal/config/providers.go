@@ -2,6 +2,7 @@ package config
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"time"
 )
@@ -11,6 +12,24 @@ type ProviderConfig struct {
 	Name        string    `json:"name"`
 	InstalledAt time.Time `json:"installed_at"`
 	Version     string    `json:"version,omitempty"`
+	// Timeout limits how long one command of the provider may run (e.g. "1h"); empty means the default
+	Timeout string `json:"timeout,omitempty"`
+	// Retries is how many times network operations (e.g. brew update) are retried; nil means the default
+	Retries *int `json:"retries,omitempty"`
+	// Backend selects the tool a provider runs when it supports several (pytool: "pipx" or "uv"; vscode: the editor CLI, e.g. "cursor")
+	Backend string `json:"backend,omitempty"`
+}
+
+// TimeoutDuration returns Timeout as a duration, or 0 if it is not set
+func (p ProviderConfig) TimeoutDuration() (time.Duration, error) {
+	if p.Timeout == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(p.Timeout)
+	if err != nil {
+		return 0, fmt.Errorf("invalid timeout for provider %s: %w", p.Name, err)
+	}
+	return d, nil
 }
 
 // ProvidersConfig represents the collection of provider configurations
@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Scripts of a script package
+const (
+	ScriptInstall     = "install"
+	ScriptUninstall   = "uninstall"
+	ScriptUpgrade     = "upgrade"
+	ScriptIsInstalled = "is-installed"
+)
+
+// ScriptKinds lists the scripts a script package can have
+var ScriptKinds = []string{ScriptInstall, ScriptUninstall, ScriptUpgrade, ScriptIsInstalled}
+
+// ValidateScriptKind validates a script kind
+func ValidateScriptKind(kind string) error {
+	for _, k := range ScriptKinds {
+		if kind == k {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid script: %s (must be %s)", kind, strings.Join(ScriptKinds, ", "))
+}
+
+// GetScriptsDir returns the path to ~/.al/scripts.d/
+func GetScriptsDir() (string, error) {
+	configDir, err := GetConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(configDir, "scripts.d"), nil
+}
+
+// ValidateScriptPackageID validates the ID of a script package, which names its scripts.d directory
+func ValidateScriptPackageID(id string) error {
+	if id == "" || id == "." || id == ".." {
+		return fmt.Errorf("invalid script package ID: '%s'", id)
+	}
+	return nil
+}
+
+// GetScriptPackageDir returns the path to ~/.al/scripts.d/<id>/
+func GetScriptPackageDir(id string) (string, error) {
+	if err := ValidateScriptPackageID(id); err != nil {
+		return "", err
+	}
+	scriptsDir, err := GetScriptsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(scriptsDir, strings.NewReplacer("/", "_", ":", "_", " ", "_").Replace(id)), nil
+}
+
+// GetScriptPath returns the path of a script of a script package (~/.al/scripts.d/<id>/<kind>.sh)
+func GetScriptPath(id, kind string) (string, error) {
+	pkgDir, err := GetScriptPackageDir(id)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(pkgDir, kind+".sh"), nil
+}
+
+// WriteScript writes content to a script of a script package, creating its directory.
+// A trailing newline is added if missing.
+func WriteScript(id, kind, content string) error {
+	if err := ValidateScriptKind(kind); err != nil {
+		return err
+	}
+	pkgDir, err := GetScriptPackageDir(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(pkgDir, 0755); err != nil {
+		return err
+	}
+	data := []byte(content)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	return os.WriteFile(filepath.Join(pkgDir, kind+".sh"), data, 0755)
+}
+
+// ScriptExists reports whether a script package has the script kind
+func ScriptExists(id, kind string) (bool, error) {
+	path, err := GetScriptPath(id, kind)
+	if err != nil {
+		return false, err
+	}
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// RemoveScriptPackageDir removes the package's scripts.d directory and all its contents.
+// It is a no-op if the directory does not exist.
+func RemoveScriptPackageDir(id string) error {
+	pkgDir, err := GetScriptPackageDir(id)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(pkgDir); os.IsNotExist(err) {
+		return nil
+	}
+	return os.RemoveAll(pkgDir)
+}
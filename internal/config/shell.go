@@ -71,6 +71,30 @@ func RemoveShellPackageDir(id, provider string) error {
 	return os.RemoveAll(pkgDir)
 }
 
+// WriteShellSnippet writes content to the package's shell.d snippet file for ext (e.g. ".zsh").
+// A trailing newline is added if missing. The manifest is created with defaults if it does not exist.
+func WriteShellSnippet(id, provider, ext, content string) error {
+	if err := EnsureShellPackageDir(id, provider); err != nil {
+		return err
+	}
+	pkgDir, err := GetShellPackageDir(id, provider)
+	if err != nil {
+		return err
+	}
+	data := []byte(content)
+	if len(data) > 0 && data[len(data)-1] != '\n' {
+		data = append(data, '\n')
+	}
+	if err := os.WriteFile(filepath.Join(pkgDir, "snippet"+ext), data, 0644); err != nil {
+		return err
+	}
+	manifest, err := LoadShellManifest(pkgDir)
+	if err != nil {
+		return err
+	}
+	return SaveShellManifest(pkgDir, manifest)
+}
+
 // LoadShellManifest loads the manifest from a package's shell.d directory.
 // If the file does not exist, returns a default manifest (Enabled: true).
 func LoadShellManifest(pkgDir string) (*ShellManifest, error) {
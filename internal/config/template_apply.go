@@ -0,0 +1,356 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// TemplateData is the data available to template strings when a template is applied.
+//
+//	{{.ProfileName}}     name given to 'al profile add'
+//	{{.DefaultProfile}}  default_profile from config.json (empty if not set)
+//	{{.Vars.team}}       user-supplied variable (--var team=infra); missing variables are an error
+//	{{.Host.Hostname}}   host facts: Hostname, ShortHostname, OS, Arch, User
+type TemplateData struct {
+	ProfileName    string
+	DefaultProfile string
+	Vars           map[string]string
+	Host           HostFacts
+}
+
+// HostFacts describes the machine a template is applied on
+type HostFacts struct {
+	Hostname      string
+	ShortHostname string
+	OS            string
+	Arch          string
+	User          string
+}
+
+// legacyPlaceholders maps the placeholders used before templates were rendered with
+// text/template to their template equivalents, so older templates.json files keep working.
+var legacyPlaceholders = strings.NewReplacer(
+	"<profile_name>", "{{.ProfileName}}",
+	"<default_profile>", "{{.DefaultProfile}}",
+)
+
+// templateFuncs are the functions available in template strings
+var templateFuncs = template.FuncMap{
+	"lower":     strings.ToLower,
+	"upper":     strings.ToUpper,
+	"replace":   strings.ReplaceAll,
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"hasSuffix": strings.HasSuffix,
+	"default": func(def, value string) string {
+		if value == "" {
+			return def
+		}
+		return value
+	},
+}
+
+// GetHostFacts returns the host facts for the current machine
+func GetHostFacts() HostFacts {
+	hostname, _ := os.Hostname()
+	short := hostname
+	if i := strings.Index(short, "."); i > 0 {
+		short = short[:i]
+	}
+	user := os.Getenv("USER")
+	if user == "" {
+		user = os.Getenv("LOGNAME")
+	}
+	return HostFacts{
+		Hostname:      hostname,
+		ShortHostname: short,
+		OS:            runtime.GOOS,
+		Arch:          runtime.GOARCH,
+		User:          user,
+	}
+}
+
+// parseTemplateString parses a single template string (after legacy placeholder translation)
+func parseTemplateString(name, text string) (*template.Template, error) {
+	return template.New(name).Funcs(templateFuncs).Option("missingkey=error").Parse(legacyPlaceholders.Replace(text))
+}
+
+// renderTemplateString renders a single template string with data
+func renderTemplateString(name, text string, data *TemplateData) (string, error) {
+	if text == "" {
+		return "", nil
+	}
+	tmpl, err := parseTemplateString(name, text)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// checkTemplateProfileSyntax parses every template string of a template profile without rendering it
+func checkTemplateProfileSyntax(profile TemplateProfile) error {
+	fields := []string{profile.Name, profile.Description, profile.Stage, profile.PromoteTo, profile.PackageDuplication, profile.When}
+	fields = append(fields, profile.Extends...)
+	for _, pkg := range profile.Packages {
+		fields = append(fields, pkg.Name, pkg.ID, pkg.Provider, pkg.Version, pkg.Description, pkg.When)
+		for _, content := range pkg.Shell {
+			fields = append(fields, content)
+		}
+	}
+	for _, field := range fields {
+		if _, err := parseTemplateString("check", field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isTruthy returns whether a rendered "when" condition enables its profile or package
+func isTruthy(rendered string) bool {
+	switch strings.ToLower(strings.TrimSpace(rendered)) {
+	case "", "false", "no", "0":
+		return false
+	}
+	return true
+}
+
+// ApplyTemplate applies a template to create profiles with the given profile name.
+// Every string field is rendered as a Go text/template with TemplateData; profiles and packages
+// whose "when" condition renders false are skipped, and extends entries that render empty are dropped.
+// The result is not validated; use ValidateProfileFamily before saving it.
+func ApplyTemplate(tmpl *ProfileTemplate, profileName string, vars map[string]string) ([]TemplateProfile, error) {
+	if tmpl == nil {
+		return nil, fmt.Errorf("template is nil")
+	}
+
+	if profileName == "" {
+		return nil, fmt.Errorf("profile name is required")
+	}
+
+	// Load app config to get default_profile
+	appConfig, err := LoadAppConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading app config: %w", err)
+	}
+
+	if vars == nil {
+		vars = map[string]string{}
+	}
+	data := &TemplateData{
+		ProfileName:    profileName,
+		DefaultProfile: appConfig.DefaultProfile,
+		Vars:           vars,
+		Host:           GetHostFacts(),
+	}
+
+	profiles := make([]TemplateProfile, 0, len(tmpl.Profiles))
+	for i, profile := range tmpl.Profiles {
+		label := fmt.Sprintf("profile %d", i+1)
+		renderRaw := func(field, text string) (string, error) {
+			out, err := renderTemplateString(label+" "+field, text, data)
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", label, err)
+			}
+			return out, nil
+		}
+		render := func(field, text string) (string, error) {
+			out, err := renderRaw(field, text)
+			return strings.TrimSpace(out), err
+		}
+
+		if profile.When != "" {
+			when, err := render("when", profile.When)
+			if err != nil {
+				return nil, err
+			}
+			if !isTruthy(when) {
+				continue
+			}
+		}
+
+		var newProfile TemplateProfile
+		if newProfile.Name, err = render("name", profile.Name); err != nil {
+			return nil, err
+		}
+		if newProfile.Description, err = render("description", profile.Description); err != nil {
+			return nil, err
+		}
+		if newProfile.Stage, err = render("stage", profile.Stage); err != nil {
+			return nil, err
+		}
+		if newProfile.PromoteTo, err = render("promote_to", profile.PromoteTo); err != nil {
+			return nil, err
+		}
+		if newProfile.PackageDuplication, err = render("package_duplication", profile.PackageDuplication); err != nil {
+			return nil, err
+		}
+		for _, ext := range profile.Extends {
+			renderedExt, err := render("extends", ext)
+			if err != nil {
+				return nil, err
+			}
+			if renderedExt != "" {
+				newProfile.Extends = append(newProfile.Extends, renderedExt)
+			}
+		}
+
+		for _, pkg := range profile.Packages {
+			newPkg, ok, err := applyTemplatePackage(pkg, render, renderRaw)
+			if err != nil {
+				return nil, err
+			}
+			if ok {
+				newProfile.Packages = append(newProfile.Packages, newPkg)
+			}
+		}
+
+		profiles = append(profiles, newProfile)
+	}
+
+	return profiles, nil
+}
+
+// applyTemplatePackage renders a template package. ok is false when its "when" condition is false.
+// Fields are rendered with render (trimmed) and shell snippets with renderRaw (untrimmed).
+func applyTemplatePackage(pkg TemplatePackage, render, renderRaw func(field, text string) (string, error)) (newPkg TemplatePackage, ok bool, err error) {
+	if pkg.When != "" {
+		when, err := render("package when", pkg.When)
+		if err != nil {
+			return newPkg, false, err
+		}
+		if !isTruthy(when) {
+			return newPkg, false, nil
+		}
+	}
+
+	if newPkg.Name, err = render("package name", pkg.Name); err != nil {
+		return newPkg, false, err
+	}
+	if newPkg.ID, err = render("package id", pkg.ID); err != nil {
+		return newPkg, false, err
+	}
+	if newPkg.Provider, err = render("package provider", pkg.Provider); err != nil {
+		return newPkg, false, err
+	}
+	if newPkg.Version, err = render("package version", pkg.Version); err != nil {
+		return newPkg, false, err
+	}
+	if newPkg.Description, err = render("package description", pkg.Description); err != nil {
+		return newPkg, false, err
+	}
+	if len(pkg.Shell) > 0 {
+		newPkg.Shell = make(map[string]string, len(pkg.Shell))
+		for shell, content := range pkg.Shell {
+			// Snippet content is rendered but not trimmed, so multi-line snippets keep their layout
+			rendered, err := renderRaw("package shell", content)
+			if err != nil {
+				return newPkg, false, err
+			}
+			newPkg.Shell[shell] = rendered
+		}
+	}
+
+	return newPkg, true, nil
+}
+
+// ValidateProfileFamily validates profiles rendered from a template before anything is saved:
+// names and references must pass ValidateProfileName, stages and package_duplication must be valid,
+// extends targets must exist or be created in the same batch, and extends must not form a cycle.
+func ValidateProfileFamily(profiles []TemplateProfile) error {
+	if len(profiles) == 0 {
+		return fmt.Errorf("template did not produce any profiles")
+	}
+
+	profilesConfig, err := LoadProfilesConfig()
+	if err != nil {
+		return fmt.Errorf("error loading profiles config: %w", err)
+	}
+
+	// extendsOf holds the extends graph of existing profiles overlaid with the new batch
+	extendsOf := make(map[string][]string)
+	for _, p := range profilesConfig.Profiles {
+		extendsOf[p.Name] = p.Extends
+	}
+
+	batch := make(map[string]bool)
+	for _, profile := range profiles {
+		if err := ValidateProfileName(profile.Name); err != nil {
+			return fmt.Errorf("invalid profile name '%s': %w", profile.Name, err)
+		}
+		if batch[profile.Name] {
+			return fmt.Errorf("profile '%s' is created more than once", profile.Name)
+		}
+		batch[profile.Name] = true
+		extendsOf[profile.Name] = profile.Extends
+	}
+
+	validStages := map[string]bool{"stable": true, "trial": true}
+	validDuplication := map[string]bool{"forbid": true, "allow": true, "warn": true}
+	for _, profile := range profiles {
+		if profile.Stage != "" && !validStages[profile.Stage] {
+			return fmt.Errorf("profile '%s': invalid stage value: %s (must be stable or trial)", profile.Name, profile.Stage)
+		}
+		if profile.PackageDuplication != "" && !validDuplication[profile.PackageDuplication] {
+			return fmt.Errorf("profile '%s': invalid package_duplication value: %s (must be forbid, allow, or warn)", profile.Name, profile.PackageDuplication)
+		}
+		if profile.PromoteTo != "" {
+			if err := ValidateProfileName(profile.PromoteTo); err != nil {
+				return fmt.Errorf("profile '%s': invalid promote_to profile name '%s': %w", profile.Name, profile.PromoteTo, err)
+			}
+		}
+		for _, ext := range profile.Extends {
+			if err := ValidateProfileName(ext); err != nil {
+				return fmt.Errorf("profile '%s': invalid extended profile name '%s': %w", profile.Name, ext, err)
+			}
+			if _, exists := extendsOf[ext]; !exists {
+				return fmt.Errorf("profile '%s': profile '%s' specified in extends does not exist", profile.Name, ext)
+			}
+		}
+	}
+
+	// Detect cycles reachable from the new profiles
+	names := make([]string, 0, len(batch))
+	for name := range batch {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	const (
+		visiting = 1
+		done     = 2
+	)
+	state := make(map[string]int)
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visiting:
+			return fmt.Errorf("extends cycle detected: %s", strings.Join(append(path, name), " -> "))
+		case done:
+			return nil
+		}
+		state[name] = visiting
+		for _, ext := range extendsOf[name] {
+			if err := visit(ext, append(path, name)); err != nil {
+				return err
+			}
+		}
+		state[name] = done
+		return nil
+	}
+	for _, name := range names {
+		if err := visit(name, nil); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
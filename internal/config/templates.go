@@ -5,13 +5,40 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 )
 
+// defaultProfileExtends is the extends entry used by the default templates to extend
+// default_profile, unless the profile being created is the default profile itself.
+const defaultProfileExtends = "{{if and .DefaultProfile (ne .DefaultProfile .ProfileName)}}{{.DefaultProfile}}{{end}}"
+
 // ProfileTemplate represents a profile template
 type ProfileTemplate struct {
-	Name     string          `json:"name"`
-	Profiles []ProfileConfig `json:"profiles"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Profiles    []TemplateProfile `json:"profiles"`
+}
+
+// TemplateProfile represents a profile created by a template.
+// It embeds ProfileConfig (so the JSON layout is the same as profiles.json) and adds
+// the starter packages registered to the profile when the template is applied.
+// All string fields are Go text/template strings (see ApplyTemplate).
+type TemplateProfile struct {
+	ProfileConfig
+	When     string            `json:"when,omitempty"` // condition; the profile is skipped when it renders to "", "false", "no", or "0"
+	Packages []TemplatePackage `json:"packages,omitempty"`
+}
+
+// TemplatePackage represents a starter package in a template profile
+type TemplatePackage struct {
+	Name        string            `json:"name"`
+	ID          string            `json:"id,omitempty"` // optional for brew (detected when empty), required for mas
+	Provider    string            `json:"provider"`
+	Version     string            `json:"version,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Shell       map[string]string `json:"shell,omitempty"` // shell.d snippet content keyed by shell ("zsh" or "bash")
+	When        string            `json:"when,omitempty"`  // condition; the package is skipped when it renders to "", "false", "no", or "0"
 }
 
 // TemplatesConfig represents the collection of template configurations
@@ -24,25 +51,33 @@ func GetDefaultTemplates() []ProfileTemplate {
 	return []ProfileTemplate{
 		{
 			Name: "stable-only",
-			Profiles: []ProfileConfig{
+			Profiles: []TemplateProfile{
 				{
-					Name:  "<profile_name>",
-					Stage: "stable",
+					ProfileConfig: ProfileConfig{
+						Name:    "{{.ProfileName}}",
+						Stage:   "stable",
+						Extends: []string{defaultProfileExtends},
+					},
 				},
 			},
 		},
 		{
 			Name: "stable-trial",
-			Profiles: []ProfileConfig{
+			Profiles: []TemplateProfile{
 				{
-					Name:  "<profile_name>",
-					Stage: "stable",
+					ProfileConfig: ProfileConfig{
+						Name:    "{{.ProfileName}}",
+						Stage:   "stable",
+						Extends: []string{defaultProfileExtends},
+					},
 				},
 				{
-					Name:      "<profile_name>.trial",
-					Stage:     "trial",
-					Extends:   []string{"<profile_name>"},
-					PromoteTo: "<profile_name>",
+					ProfileConfig: ProfileConfig{
+						Name:      "{{.ProfileName}}.trial",
+						Stage:     "trial",
+						Extends:   []string{defaultProfileExtends, "{{.ProfileName}}"},
+						PromoteTo: "{{.ProfileName}}",
+					},
 				},
 			},
 		},
@@ -81,9 +116,39 @@ func LoadTemplatesConfig() (*TemplatesConfig, error) {
 		return nil, err
 	}
 
+	for i := range config.Templates {
+		migrateLegacyTemplate(&config.Templates[i])
+	}
+
 	return &config, nil
 }
 
+// migrateLegacyTemplate updates a template that still uses the old <profile_name> and
+// <default_profile> placeholders. Those templates extended default_profile implicitly,
+// so the extends entry is added to each profile that does not extend it already.
+func migrateLegacyTemplate(tmpl *ProfileTemplate) {
+	data, err := json.Marshal(tmpl)
+	if err != nil {
+		return
+	}
+	if !strings.Contains(string(data), "<profile_name>") && !strings.Contains(string(data), "<default_profile>") {
+		return
+	}
+	for i := range tmpl.Profiles {
+		profile := &tmpl.Profiles[i]
+		extendsDefault := false
+		for _, ext := range profile.Extends {
+			if strings.Contains(ext, "<default_profile>") || strings.Contains(ext, ".DefaultProfile") {
+				extendsDefault = true
+				break
+			}
+		}
+		if !extendsDefault {
+			profile.Extends = append([]string{defaultProfileExtends}, profile.Extends...)
+		}
+	}
+}
+
 // SaveTemplatesConfig saves the templates configuration to JSON file
 func SaveTemplatesConfig(config *TemplatesConfig) error {
 	// Ensure config directory exists
@@ -133,6 +198,9 @@ func GetAllTemplates() ([]ProfileTemplate, error) {
 	for _, tmpl := range templateMap {
 		templates = append(templates, tmpl)
 	}
+	sort.Slice(templates, func(i, j int) bool {
+		return templates[i].Name < templates[j].Name
+	})
 
 	return templates, nil
 }
@@ -161,11 +229,8 @@ func AddOrUpdateTemplate(template ProfileTemplate) error {
 	}
 
 	// Check if it's a default template
-	defaultTemplates := GetDefaultTemplates()
-	for _, dt := range defaultTemplates {
-		if dt.Name == template.Name {
-			return fmt.Errorf("cannot override default template '%s'", template.Name)
-		}
+	if IsDefaultTemplate(template.Name) {
+		return fmt.Errorf("cannot override default template '%s'", template.Name)
 	}
 
 	// Check if template already exists
@@ -189,11 +254,8 @@ func AddOrUpdateTemplate(template ProfileTemplate) error {
 // RemoveTemplate removes a user-defined template
 func RemoveTemplate(name string) error {
 	// Check if it's a default template
-	defaultTemplates := GetDefaultTemplates()
-	for _, dt := range defaultTemplates {
-		if dt.Name == name {
-			return fmt.Errorf("cannot remove default template '%s'", name)
-		}
+	if IsDefaultTemplate(name) {
+		return fmt.Errorf("cannot remove default template '%s'", name)
 	}
 
 	config, err := LoadTemplatesConfig()
@@ -218,73 +280,107 @@ func RemoveTemplate(name string) error {
 	return SaveTemplatesConfig(config)
 }
 
-// ApplyTemplate applies a template to create profiles with the given profile name
-func ApplyTemplate(template *ProfileTemplate, profileName string) ([]ProfileConfig, error) {
-	if template == nil {
-		return nil, fmt.Errorf("template is nil")
+// IsDefaultTemplate returns true if name is one of the default (embedded) templates
+func IsDefaultTemplate(name string) bool {
+	for _, dt := range GetDefaultTemplates() {
+		if dt.Name == name {
+			return true
+		}
 	}
+	return false
+}
 
-	if profileName == "" {
-		return nil, fmt.Errorf("profile name is required")
+// ValidateTemplate checks that a template is well-formed before it is saved.
+// Profile names may contain placeholders, so only the parts that do not depend on
+// the applied profile name are checked here.
+func ValidateTemplate(template *ProfileTemplate) error {
+	if template == nil {
+		return fmt.Errorf("template is nil")
+	}
+	if err := ValidateProfileName(template.Name); err != nil {
+		return fmt.Errorf("invalid template name: %w", err)
+	}
+	if len(template.Profiles) == 0 {
+		return fmt.Errorf("template '%s' must define at least one profile", template.Name)
 	}
 
-	// Load app config to get default_profile
-	appConfig, err := LoadAppConfig()
-	if err != nil {
-		return nil, fmt.Errorf("error loading app config: %w", err)
-	}
-
-	defaultProfile := appConfig.DefaultProfile
-
-	profiles := make([]ProfileConfig, 0, len(template.Profiles))
-	for _, profile := range template.Profiles {
-		// Replace <profile_name> with actual profile name
-		newProfile := profile
-		newProfile.Name = strings.ReplaceAll(profile.Name, "<profile_name>", profileName)
-
-		// Build extends array
-		newExtends := make([]string, 0)
-		
-		// Add <default_profile> at the beginning if default_profile is set and not the same as the profile being created
-		if defaultProfile != "" {
-			// Parse the profile name to get profile_name part
-			profileNamePart, _, err := ParseProfileName(newProfile.Name)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing profile name '%s': %w", newProfile.Name, err)
-			}
-			
-			// Only add if default_profile is different from the profile_name part
-			if defaultProfile != profileNamePart {
-				newExtends = append(newExtends, fmt.Sprintf("<default_profile>"))
-			}
+	names := make(map[string]bool)
+	for i, profile := range template.Profiles {
+		if strings.TrimSpace(profile.Name) == "" {
+			return fmt.Errorf("profile %d: name is required", i+1)
 		}
+		if names[profile.Name] {
+			return fmt.Errorf("profile '%s' is defined more than once", profile.Name)
+		}
+		names[profile.Name] = true
 
-		// Add existing extends
-		if len(profile.Extends) > 0 {
-			for _, ext := range profile.Extends {
-				replacedExt := strings.ReplaceAll(ext, "<profile_name>", profileName)
-				newExtends = append(newExtends, replacedExt)
-			}
+		if err := checkTemplateProfileSyntax(profile); err != nil {
+			return fmt.Errorf("profile '%s': %w", profile.Name, err)
 		}
 
-		newProfile.Extends = newExtends
+		if profile.Stage != "" && !strings.Contains(profile.Stage, "{{") && profile.Stage != "stable" && profile.Stage != "trial" {
+			return fmt.Errorf("profile '%s': invalid stage value: %s (must be stable or trial)", profile.Name, profile.Stage)
+		}
+		if profile.PackageDuplication != "" && !strings.Contains(profile.PackageDuplication, "{{") {
+			validValues := map[string]bool{"forbid": true, "allow": true, "warn": true}
+			if !validValues[profile.PackageDuplication] {
+				return fmt.Errorf("profile '%s': invalid package_duplication value: %s (must be forbid, allow, or warn)", profile.Name, profile.PackageDuplication)
+			}
+		}
 
-		// Replace <profile_name> and <default_profile> in Extends
-		for i, ext := range newProfile.Extends {
-			ext = strings.ReplaceAll(ext, "<profile_name>", profileName)
-			if defaultProfile != "" {
-				ext = strings.ReplaceAll(ext, "<default_profile>", defaultProfile)
+		for j, pkg := range profile.Packages {
+			if strings.TrimSpace(pkg.Name) == "" {
+				return fmt.Errorf("profile '%s': package %d: name is required", profile.Name, j+1)
+			}
+			if pkg.Provider == "" {
+				return fmt.Errorf("profile '%s': package '%s': provider is required", profile.Name, pkg.Name)
+			}
+			if pkg.Provider == "mas" && pkg.ID == "" {
+				return fmt.Errorf("profile '%s': package '%s': id is required for mas", profile.Name, pkg.Name)
+			}
+			for shell := range pkg.Shell {
+				if shell != "zsh" && shell != "bash" {
+					return fmt.Errorf("profile '%s': package '%s': unsupported shell '%s' (use zsh or bash)", profile.Name, pkg.Name, shell)
+				}
 			}
-			newProfile.Extends[i] = ext
 		}
+	}
 
-		// Replace <profile_name> in PromoteTo
-		if profile.PromoteTo != "" {
-			newProfile.PromoteTo = strings.ReplaceAll(profile.PromoteTo, "<profile_name>", profileName)
+	return nil
+}
+
+// ParseTemplates parses template JSON. Both a single template object and
+// the templates.json layout ({"templates": [...]}) are accepted.
+func ParseTemplates(data []byte) ([]ProfileTemplate, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+
+	if _, ok := probe["templates"]; ok {
+		var config TemplatesConfig
+		if err := json.Unmarshal(data, &config); err != nil {
+			return nil, err
 		}
+		return config.Templates, nil
+	}
 
-		profiles = append(profiles, newProfile)
+	var template ProfileTemplate
+	if err := json.Unmarshal(data, &template); err != nil {
+		return nil, err
 	}
+	return []ProfileTemplate{template}, nil
+}
 
-	return profiles, nil
+// LoadTemplatesFile reads and parses templates from a JSON file
+func LoadTemplatesFile(path string) ([]ProfileTemplate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	templates, err := ParseTemplates(data)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %w", path, err)
+	}
+	return templates, nil
 }
@@ -0,0 +1,153 @@
+// Package download downloads release files, verifies their checksums, extracts binaries from
+// archives, and finds the latest version of a release.
+package download
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+)
+
+// Client is the HTTP client used for downloads. It also serves file:// URLs, so that local files
+// can stand in for a release server; a server cannot redirect to them.
+var Client = newClient()
+
+func newClient() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.RegisterProtocol("file", http.NewFileTransport(http.Dir("/")))
+	return &http.Client{Transport: transport, CheckRedirect: checkRedirect}
+}
+
+// checkRedirect follows up to 10 redirects like the default client, but not from a remote URL to a
+// local file
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return fmt.Errorf("stopped after 10 redirects")
+	}
+	if req.URL.Scheme == "file" && via[0].URL.Scheme != "file" {
+		return fmt.Errorf("refusing to redirect %s to a local file", via[0].URL)
+	}
+	return nil
+}
+
+// get sends a GET request for url and returns the response of a successful request
+func get(ctx context.Context, url string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("failed to download %s: status %d", url, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// File downloads url to dest. A partial download is removed.
+func File(ctx context.Context, url, dest string) error {
+	resp, err := get(ctx, url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(out, resp.Body)
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return nil
+}
+
+// VerifiedFile downloads url to dest and verifies its SHA-256 digest. The file is removed if the
+// download fails or the digest is not expected, so that dest only ever holds a verified file.
+func VerifiedFile(ctx context.Context, url, dest, expected string) error {
+	if err := File(ctx, url, dest); err != nil {
+		return err
+	}
+	if err := Verify(dest, expected); err != nil {
+		os.Remove(dest)
+		return err
+	}
+	return nil
+}
+
+// SHA256 returns the hex-encoded SHA-256 digest of the file at path
+func SHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// Verify returns an error if the SHA-256 digest of the file at path is not expected
+func Verify(path, expected string) error {
+	actual, err := SHA256(path)
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(actual, strings.TrimSpace(expected)) {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
+
+// IsSHA256 reports whether s is a hex-encoded SHA-256 digest
+func IsSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// Checksum downloads a checksums file (lines of "<sha256>  <file name>", as written by sha256sum and
+// published with most releases) and returns the digest of fileName
+func Checksum(ctx context.Context, url, fileName string) (string, error) {
+	resp, err := get(ctx, url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !IsSHA256(fields[0]) {
+			continue
+		}
+		// sha256sum marks binary mode with "*"; some files list paths
+		if path.Base(strings.TrimPrefix(fields[1], "*")) == fileName {
+			return strings.ToLower(fields[0]), nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+	return "", fmt.Errorf("no checksum for %s in %s", fileName, url)
+}
@@ -0,0 +1,165 @@
+package download
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const releaseContent = "release file\n"
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// newReleaseServer serves releaseContent at /tool.bin, a checksums file at /checksums.txt, and a
+// download that breaks off at /partial.bin
+func newReleaseServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/tool.bin", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(releaseContent))
+	})
+	mux.HandleFunc("/checksums.txt", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(strings.Join([]string{
+			"not a checksum line",
+			strings.Repeat("0", 64) + "  other.bin",
+			strings.ToUpper(sha256Hex(releaseContent)) + " *dist/tool.bin",
+			"",
+		}, "\n")))
+	})
+	mux.HandleFunc("/partial.bin", func(w http.ResponseWriter, r *http.Request) {
+		// Promise more than is sent, so the connection is closed mid-download
+		w.Header().Set("Content-Length", "1000")
+		w.Write([]byte("partial"))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestFile(t *testing.T) {
+	server := newReleaseServer(t)
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	dest := filepath.Join(dir, "tool.bin")
+	if err := File(ctx, server.URL+"/tool.bin", dest); err != nil {
+		t.Fatalf("File: %v", err)
+	}
+	data, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != releaseContent {
+		t.Errorf("downloaded %q, want %q", data, releaseContent)
+	}
+
+	missing := filepath.Join(dir, "missing.bin")
+	if err := File(ctx, server.URL+"/missing.bin", missing); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Errorf("File of a missing URL: got %v, want a status 404 error", err)
+	}
+	if _, err := os.Stat(missing); !os.IsNotExist(err) {
+		t.Error("File of a missing URL created the file")
+	}
+
+	partial := filepath.Join(dir, "partial.bin")
+	if err := File(ctx, server.URL+"/partial.bin", partial); err == nil {
+		t.Error("File of a broken download: expected an error")
+	}
+	if _, err := os.Stat(partial); !os.IsNotExist(err) {
+		t.Error("File of a broken download left the partial file")
+	}
+}
+
+func TestFileURL(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "tool.bin")
+	if err := os.WriteFile(src, []byte(releaseContent), 0644); err != nil {
+		t.Fatal(err)
+	}
+	dest := filepath.Join(t.TempDir(), "tool.bin")
+	if err := File(context.Background(), "file://"+src, dest); err != nil {
+		t.Fatalf("File of a file:// URL: %v", err)
+	}
+	if err := Verify(dest, sha256Hex(releaseContent)); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestRedirectToFileURL(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(src, []byte("secret\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(http.RedirectHandler("file://"+src, http.StatusFound))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "tool.bin")
+	if err := File(context.Background(), server.URL+"/tool.bin", dest); err == nil {
+		t.Fatal("File of a redirect to a file:// URL: expected an error")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("File of a redirect to a file:// URL wrote the local file")
+	}
+}
+
+func TestVerifiedFile(t *testing.T) {
+	server := newReleaseServer(t)
+	ctx := context.Background()
+	dest := filepath.Join(t.TempDir(), "tool.bin")
+
+	// Digests are compared case-insensitively
+	if err := VerifiedFile(ctx, server.URL+"/tool.bin", dest, strings.ToUpper(sha256Hex(releaseContent))); err != nil {
+		t.Fatalf("VerifiedFile: %v", err)
+	}
+	if _, err := os.Stat(dest); err != nil {
+		t.Errorf("the verified file is missing: %v", err)
+	}
+
+	err := VerifiedFile(ctx, server.URL+"/tool.bin", dest, sha256Hex("something else"))
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("VerifiedFile with a wrong digest: got %v, want a checksum mismatch", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Error("VerifiedFile left the file that failed verification")
+	}
+}
+
+func TestChecksum(t *testing.T) {
+	server := newReleaseServer(t)
+	ctx := context.Background()
+
+	sum, err := Checksum(ctx, server.URL+"/checksums.txt", "tool.bin")
+	if err != nil {
+		t.Fatalf("Checksum: %v", err)
+	}
+	if sum != sha256Hex(releaseContent) {
+		t.Errorf("Checksum = %s, want %s", sum, sha256Hex(releaseContent))
+	}
+
+	if _, err := Checksum(ctx, server.URL+"/checksums.txt", "missing.bin"); err == nil {
+		t.Error("Checksum of a file that is not listed: expected an error")
+	}
+}
+
+func TestIsSHA256(t *testing.T) {
+	tests := map[string]bool{
+		sha256Hex("x"):          true,
+		strings.Repeat("A", 64): true,
+		strings.Repeat("g", 64): false,
+		"abc":                   false,
+		"":                      false,
+	}
+	for s, want := range tests {
+		if got := IsSHA256(s); got != want {
+			t.Errorf("IsSHA256(%q) = %v, want %v", s, got, want)
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package download
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+)
+
+// IsArchive reports whether name is a file Extract can read members from (.tar.gz, .tgz, or .zip)
+func IsArchive(name string) bool {
+	lower := strings.ToLower(name)
+	return strings.HasSuffix(lower, ".tar.gz") || strings.HasSuffix(lower, ".tgz") || strings.HasSuffix(lower, ".zip")
+}
+
+// matchMember reports whether an archive entry is member: the same path, ignoring a leading "./",
+// or, for a member without a directory, the same base name at any depth
+func matchMember(name, member string) bool {
+	name = strings.TrimPrefix(name, "./")
+	if name == member {
+		return true
+	}
+	return !strings.Contains(member, "/") && path.Base(name) == member
+}
+
+// Extract writes the member of the archive at archivePath to dest. The format is chosen by the
+// archive's name: .tar.gz and .tgz are gzipped tarballs, and .zip is a zip file.
+func Extract(archivePath, member, dest string) error {
+	lower := strings.ToLower(archivePath)
+	switch {
+	case strings.HasSuffix(lower, ".tar.gz"), strings.HasSuffix(lower, ".tgz"):
+		return extractTarGz(archivePath, member, dest)
+	case strings.HasSuffix(lower, ".zip"):
+		return extractZip(archivePath, member, dest)
+	}
+	return fmt.Errorf("unsupported archive: %s (supported: .tar.gz, .tgz, .zip)", path.Base(archivePath))
+}
+
+// writeFile writes r to dest
+func writeFile(r io.Reader, dest string) error {
+	out, err := os.Create(dest)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, r); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func extractTarGz(archivePath, member, dest string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	gzr, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		// Only extract the member
+		if header.Typeflag == tar.TypeReg && matchMember(header.Name, member) {
+			return writeFile(tr, dest)
+		}
+	}
+
+	return fmt.Errorf("%s not found in %s", member, path.Base(archivePath))
+}
+
+func extractZip(archivePath, member, dest string) error {
+	zr, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() || !matchMember(f.Name, member) {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		return writeFile(rc, dest)
+	}
+
+	return fmt.Errorf("%s not found in %s", member, path.Base(archivePath))
+}
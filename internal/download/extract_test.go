@@ -0,0 +1,150 @@
+package download
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// archiveEntries are the files of the test archives; names ending in "/" are directories
+var archiveEntries = []struct {
+	name    string
+	content string
+}{
+	{"./tool_1.0/", ""},
+	{"./tool_1.0/README.md", "readme"},
+	{"./tool_1.0/bin/", ""},
+	{"./tool_1.0/bin/tool", "tool binary"},
+	{"./tool_1.0/bin/helper", "helper binary"},
+}
+
+func writeTarGz(t *testing.T, path string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	gzw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gzw)
+	for _, entry := range archiveEntries {
+		header := &tar.Header{Name: entry.name, Mode: 0755, Typeflag: tar.TypeReg, Size: int64(len(entry.content))}
+		if entry.name[len(entry.name)-1] == '/' {
+			header.Typeflag = tar.TypeDir
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func writeZip(t *testing.T, path string) {
+	t.Helper()
+	file, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	zw := zip.NewWriter(file)
+	for _, entry := range archiveEntries {
+		// zip entries are written without the leading "./"
+		w, err := zw.Create(entry.name[2:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(entry.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestExtract(t *testing.T) {
+	dir := t.TempDir()
+	archives := map[string]func(*testing.T, string){
+		"tool.tar.gz": writeTarGz,
+		"tool.TGZ":    writeTarGz,
+		"tool.zip":    writeZip,
+	}
+
+	tests := []struct {
+		member string
+		want   string
+	}{
+		{member: "tool", want: "tool binary"},              // base name at any depth
+		{member: "helper", want: "helper binary"},          // another member
+		{member: "tool_1.0/bin/tool", want: "tool binary"}, // full path
+		{member: "tool_1.0/README.md", want: "readme"},     // full path outside bin
+		{member: "bin/tool"},                               // a path must match exactly
+		{member: "tool_1.0"},                               // directories are not members
+		{member: "missing"},
+	}
+
+	for name, write := range archives {
+		archivePath := filepath.Join(dir, name)
+		write(t, archivePath)
+
+		for _, tt := range tests {
+			dest := filepath.Join(dir, "out")
+			os.Remove(dest)
+			err := Extract(archivePath, tt.member, dest)
+			if tt.want == "" {
+				if err == nil {
+					t.Errorf("%s: Extract(%s): expected an error", name, tt.member)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("%s: Extract(%s): %v", name, tt.member, err)
+				continue
+			}
+			data, err := os.ReadFile(dest)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("%s: Extract(%s) wrote %q, want %q", name, tt.member, data, tt.want)
+			}
+		}
+	}
+}
+
+func TestExtractUnsupported(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tool.tar.xz")
+	if err := os.WriteFile(path, []byte("xz"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := Extract(path, "tool", filepath.Join(t.TempDir(), "out")); err == nil {
+		t.Error("Extract of a .tar.xz: expected an error")
+	}
+}
+
+func TestIsArchive(t *testing.T) {
+	tests := map[string]bool{
+		"tool.tar.gz":      true,
+		"tool.TGZ":         true,
+		"tool.zip":         true,
+		"tool.tar.xz":      false,
+		"tool":             false,
+		"tool-linux-amd64": false,
+	}
+	for name, want := range tests {
+		if got := IsArchive(name); got != want {
+			t.Errorf("IsArchive(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
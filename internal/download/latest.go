@@ -0,0 +1,117 @@
+package download
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// GitHubAPI is the base URL of the GitHub REST API
+const GitHubAPI = "https://api.github.com"
+
+// Release is a GitHub release
+type Release struct {
+	TagName string  `json:"tag_name"`
+	Name    string  `json:"name"`
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a GitHub release
+type Asset struct {
+	Name               string `json:"name"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// LatestRelease returns the latest release of a GitHub repository from the API at api
+func LatestRelease(ctx context.Context, api, owner, repo string) (*Release, error) {
+	url := fmt.Sprintf("%s/repos/%s/%s/releases/latest", strings.TrimSuffix(api, "/"), owner, repo)
+
+	resp, err := get(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var release Release
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return nil, err
+	}
+
+	return &release, nil
+}
+
+// VersionSource finds the latest version of a release, without a leading "v"
+type VersionSource interface {
+	Latest(ctx context.Context) (string, error)
+}
+
+// GitHubSource finds the latest version from the latest release of a GitHub repository
+type GitHubSource struct {
+	// API is the base URL of the GitHub API (GitHubAPI, or a test server)
+	API   string
+	Owner string
+	Repo  string
+}
+
+// Latest returns the tag of the latest release
+func (s GitHubSource) Latest(ctx context.Context) (string, error) {
+	release, err := LatestRelease(ctx, s.API, s.Owner, s.Repo)
+	if err != nil {
+		return "", fmt.Errorf("failed to get the latest release of %s/%s: %w", s.Owner, s.Repo, err)
+	}
+	return strings.TrimPrefix(release.TagName, "v"), nil
+}
+
+// URLSource finds the latest version in the first line of a text file, such as a VERSION file
+// published next to the releases
+type URLSource struct {
+	URL string
+}
+
+// Latest returns the first line of the file
+func (s URLSource) Latest(ctx context.Context) (string, error) {
+	resp, err := get(ctx, s.URL)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	line, err := bufio.NewReader(io.LimitReader(resp.Body, 1024)).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+	version := strings.TrimPrefix(strings.TrimSpace(line), "v")
+	if version == "" {
+		return "", fmt.Errorf("no version in %s", s.URL)
+	}
+	return version, nil
+}
+
+// githubDownloadRegex matches the release download URLs of GitHub repositories
+var githubDownloadRegex = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/releases/download/`)
+
+// NewVersionSource returns the version source of spec: "github:owner/repo" for the latest GitHub
+// release, or the URL of a text file whose first line is the version. An empty spec is inferred
+// from a GitHub release download URL; otherwise there is no source and nil is returned.
+func NewVersionSource(spec, downloadURL string) (VersionSource, error) {
+	switch {
+	case spec == "":
+		if m := githubDownloadRegex.FindStringSubmatch(downloadURL); m != nil {
+			return GitHubSource{API: GitHubAPI, Owner: m[1], Repo: m[2]}, nil
+		}
+		return nil, nil
+	case strings.HasPrefix(spec, "github:"):
+		owner, repo, ok := strings.Cut(strings.TrimPrefix(spec, "github:"), "/")
+		if !ok || owner == "" || repo == "" {
+			return nil, fmt.Errorf("invalid version source: %s (expected github:owner/repo)", spec)
+		}
+		return GitHubSource{API: GitHubAPI, Owner: owner, Repo: repo}, nil
+	case strings.HasPrefix(spec, "http://"), strings.HasPrefix(spec, "https://"), strings.HasPrefix(spec, "file://"):
+		return URLSource{URL: spec}, nil
+	}
+	return nil, fmt.Errorf("invalid version source: %s (expected github:owner/repo or a URL)", spec)
+}
@@ -0,0 +1,138 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// newGitHubServer serves the latest release of owner/repo with tag, like the GitHub API
+func newGitHubServer(t *testing.T, tag string) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/owner/repo/releases/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"tag_name": %q, "name": "Release %s", "assets": [
+			{"name": "tool_linux_amd64.tar.gz", "browser_download_url": "https://github.com/owner/repo/releases/download/%s/tool_linux_amd64.tar.gz"}
+		]}`, tag, tag, tag)
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLatestRelease(t *testing.T) {
+	server := newGitHubServer(t, "v1.2.3")
+	ctx := context.Background()
+
+	// A trailing slash on the API URL is ignored
+	release, err := LatestRelease(ctx, server.URL+"/", "owner", "repo")
+	if err != nil {
+		t.Fatalf("LatestRelease: %v", err)
+	}
+	if release.TagName != "v1.2.3" || release.Name != "Release v1.2.3" {
+		t.Errorf("release = %s (%s), want v1.2.3 (Release v1.2.3)", release.TagName, release.Name)
+	}
+	if len(release.Assets) != 1 || release.Assets[0].Name != "tool_linux_amd64.tar.gz" {
+		t.Errorf("assets = %+v, want tool_linux_amd64.tar.gz", release.Assets)
+	}
+
+	if _, err := LatestRelease(ctx, server.URL, "owner", "missing"); err == nil {
+		t.Error("LatestRelease of a missing repository: expected an error")
+	}
+}
+
+func TestGitHubSource(t *testing.T) {
+	server := newGitHubServer(t, "v2.0.0")
+	ctx := context.Background()
+
+	version, err := GitHubSource{API: server.URL, Owner: "owner", Repo: "repo"}.Latest(ctx)
+	if err != nil {
+		t.Fatalf("Latest: %v", err)
+	}
+	if version != "2.0.0" {
+		t.Errorf("Latest = %s, want 2.0.0", version)
+	}
+
+	if _, err := (GitHubSource{API: server.URL, Owner: "owner", Repo: "missing"}).Latest(ctx); err == nil {
+		t.Error("Latest of a missing repository: expected an error")
+	}
+}
+
+func TestURLSource(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/VERSION", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("  v3.1.4  \nrelease notes\n"))
+	})
+	mux.HandleFunc("/NOEOL", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("0.9.0"))
+	})
+	mux.HandleFunc("/EMPTY", func(w http.ResponseWriter, r *http.Request) {})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	ctx := context.Background()
+
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "/VERSION", want: "3.1.4"},
+		{path: "/NOEOL", want: "0.9.0"},
+		{path: "/EMPTY", wantErr: true},
+		{path: "/missing", wantErr: true},
+	}
+	for _, tt := range tests {
+		version, err := URLSource{URL: server.URL + tt.path}.Latest(ctx)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("%s: expected an error, got %s", tt.path, version)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", tt.path, err)
+			continue
+		}
+		if version != tt.want {
+			t.Errorf("%s: Latest = %s, want %s", tt.path, version, tt.want)
+		}
+	}
+}
+
+func TestNewVersionSource(t *testing.T) {
+	const releaseURL = "https://github.com/junegunn/fzf/releases/download/v{version}/fzf-{version}-{os}_{arch}.tar.gz"
+	tests := []struct {
+		spec        string
+		downloadURL string
+		want        VersionSource
+		wantErr     bool
+	}{
+		{spec: "", downloadURL: releaseURL, want: GitHubSource{API: GitHubAPI, Owner: "junegunn", Repo: "fzf"}},
+		{spec: "", downloadURL: "https://example.com/tool-{version}.zip", want: nil},
+		{spec: "github:owner/repo", downloadURL: releaseURL, want: GitHubSource{API: GitHubAPI, Owner: "owner", Repo: "repo"}},
+		{spec: "https://example.com/VERSION", want: URLSource{URL: "https://example.com/VERSION"}},
+		{spec: "file:///srv/tool/VERSION", want: URLSource{URL: "file:///srv/tool/VERSION"}},
+		{spec: "github:owner", wantErr: true},
+		{spec: "github:/repo", wantErr: true},
+		{spec: "latest", wantErr: true},
+	}
+	for _, tt := range tests {
+		source, err := NewVersionSource(tt.spec, tt.downloadURL)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("NewVersionSource(%q): expected an error, got %#v", tt.spec, source)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewVersionSource(%q): %v", tt.spec, err)
+			continue
+		}
+		if !reflect.DeepEqual(source, tt.want) {
+			t.Errorf("NewVersionSource(%q, %q) = %#v, want %#v", tt.spec, tt.downloadURL, source, tt.want)
+		}
+	}
+}
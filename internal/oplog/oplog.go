@@ -0,0 +1,260 @@
+// Package oplog keeps the output of provider commands in per-operation log directories:
+// $AL_HOME/logs/<timestamp>-<op>/<package>.log
+package oplog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// timeLayout is the timestamp at the start of a run ID
+const timeLayout = "20060102-150405"
+
+// Run is the log directory of one operation, e.g. logs/20261018-093000-upgrade
+type Run struct {
+	ID   string
+	Op   string
+	Dir  string
+	Time time.Time
+
+	mu    sync.Mutex
+	files map[string]*os.File
+}
+
+// Start begins the log of a new operation and removes logs past the retention configured in
+// config.json. The log directory is created when the first log is written, so operations that
+// run no provider command leave nothing behind.
+func Start(op string) (*Run, error) {
+	logsDir, err := config.GetLogsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(logsDir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating logs directory: %w", err)
+	}
+
+	now := time.Now()
+	base := now.Format(timeLayout) + "-" + op
+	id := base
+	for n := 2; ; n++ {
+		if _, err := os.Stat(filepath.Join(logsDir, id)); os.IsNotExist(err) {
+			break
+		}
+		id = fmt.Sprintf("%s-%d", base, n)
+	}
+
+	if appConfig, err := config.LoadAppConfig(); err == nil {
+		// Retention is best effort: a log that cannot be removed is tried again next time
+		_ = Prune(appConfig.Logs)
+	}
+
+	return &Run{ID: id, Op: op, Dir: filepath.Join(logsDir, id), Time: now}, nil
+}
+
+// StartOrWarn is Start for commands that work without a log: if the log cannot be created, it
+// prints a warning and returns nil, which Tee, Attach, and Close accept
+func StartOrWarn(op string) *Run {
+	run, err := Start(op)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: provider output will not be logged: %v\n", err)
+		return nil
+	}
+	return run
+}
+
+// Writer returns the log of name (usually a package name) in the run, creating it on first use
+func (r *Run) Writer(name string) (io.Writer, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	fileName := logFileName(name)
+	if f, ok := r.files[fileName]; ok {
+		return f, nil
+	}
+	if err := os.MkdirAll(r.Dir, 0755); err != nil {
+		return nil, err
+	}
+	f, err := os.OpenFile(filepath.Join(r.Dir, fileName), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return nil, err
+	}
+	if r.files == nil {
+		r.files = make(map[string]*os.File)
+	}
+	r.files[fileName] = f
+	return f, nil
+}
+
+// Tee returns stdout and stderr extended to also write to the log of name. Logging never stops an
+// operation: without a run, or if the log cannot be opened, the writers are returned unchanged.
+func (r *Run) Tee(name string, stdout, stderr io.Writer) (io.Writer, io.Writer) {
+	if r == nil {
+		return stdout, stderr
+	}
+	log, err := r.Writer(name)
+	if err != nil {
+		return stdout, stderr
+	}
+	return io.MultiWriter(stdout, log), io.MultiWriter(stderr, log)
+}
+
+// outputSetter is implemented by providers whose output can be redirected
+type outputSetter interface {
+	SetOutput(stdout, stderr io.Writer)
+}
+
+// Attach sends the output of provider p to the terminal and to the log of name
+func (r *Run) Attach(p interface{}, name string) {
+	if setter, ok := p.(outputSetter); ok {
+		setter.SetOutput(r.Tee(name, os.Stdout, os.Stderr))
+	}
+}
+
+// Close closes the logs opened by the run
+func (r *Run) Close() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for _, f := range r.files {
+		if err := f.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	r.files = nil
+	return firstErr
+}
+
+// Files returns the log files of the run, sorted by name
+func (r *Run) Files() ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(r.Dir, "*.log"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// List returns the logged operations, oldest first
+func List() ([]*Run, error) {
+	logsDir, err := config.GetLogsDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(logsDir)
+	if os.IsNotExist(err) {
+		return []*Run{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error reading logs directory: %w", err)
+	}
+
+	runs := []*Run{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		run, ok := parseRun(logsDir, entry.Name())
+		if !ok {
+			continue
+		}
+		runs = append(runs, run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].ID < runs[j].ID })
+	return runs, nil
+}
+
+// Find returns the operation with the given ID, "last" (or "") for the most recent one, or a unique
+// ID prefix
+func Find(id string) (*Run, error) {
+	runs, err := List()
+	if err != nil {
+		return nil, err
+	}
+	if len(runs) == 0 {
+		return nil, fmt.Errorf("no logs found")
+	}
+	if id == "" || id == "last" {
+		return runs[len(runs)-1], nil
+	}
+
+	var matches []*Run
+	for _, run := range runs {
+		if run.ID == id {
+			return run, nil
+		}
+		if strings.HasPrefix(run.ID, id) {
+			matches = append(matches, run)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("log '%s' not found", id)
+	case 1:
+		return matches[0], nil
+	}
+	return nil, fmt.Errorf("log '%s' is ambiguous (%d matches)", id, len(matches))
+}
+
+// Prune removes logs older than the retention period, then the oldest logs beyond the maximum count
+func Prune(logsConfig *config.LogsConfig) error {
+	runs, err := List()
+	if err != nil {
+		return err
+	}
+
+	keep := runs
+	if days := logsConfig.EffectiveRetentionDays(); days > 0 {
+		cutoff := time.Now().AddDate(0, 0, -days)
+		keep = keep[:0:0]
+		for _, run := range runs {
+			if run.Time.Before(cutoff) {
+				if err := os.RemoveAll(run.Dir); err != nil {
+					return err
+				}
+				continue
+			}
+			keep = append(keep, run)
+		}
+	}
+	if max := logsConfig.EffectiveMaxRuns(); max > 0 && len(keep) > max {
+		for _, run := range keep[:len(keep)-max] {
+			if err := os.RemoveAll(run.Dir); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// parseRun reads a run from its directory name, "<timestamp>-<op>"
+func parseRun(logsDir, name string) (*Run, bool) {
+	if len(name) <= len(timeLayout)+1 || name[len(timeLayout)] != '-' {
+		return nil, false
+	}
+	t, err := time.ParseInLocation(timeLayout, name[:len(timeLayout)], time.Local)
+	if err != nil {
+		return nil, false
+	}
+	return &Run{ID: name, Op: name[len(timeLayout)+1:], Dir: filepath.Join(logsDir, name), Time: t}, true
+}
+
+// logFileName turns a package name such as hashicorp/tap/terraform into a file name
+func logFileName(name string) string {
+	name = strings.NewReplacer("/", "_", string(os.PathSeparator), "_", ":", "_").Replace(name)
+	if name == "" || name == "." || name == ".." {
+		name = "output"
+	}
+	return name + ".log"
+}
@@ -0,0 +1,301 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// Supported output formats
+const (
+	FormatTable = "table"
+	FormatJSON  = "json"
+	FormatYAML  = "yaml"
+	FormatTSV   = "tsv"
+)
+
+// Options holds the output flags shared by read commands
+type Options struct {
+	Format   string
+	Template string
+}
+
+// AddFlags registers --output/-o and --template on a read command.
+// defaultFormat is the format used when --output is not given.
+func AddFlags(cmd *cobra.Command, opts *Options, defaultFormat string) {
+	cmd.Flags().StringVarP(&opts.Format, "output", "o", defaultFormat, "Output format: table, json, yaml, or tsv")
+	cmd.Flags().StringVar(&opts.Template, "template", "", "Format output with a Go template (fields use the JSON names, e.g. '{{range .}}{{.name}}{{\"\\n\"}}{{end}}')")
+}
+
+// Validate checks the output options
+func (o *Options) Validate() error {
+	switch o.Format {
+	case FormatTable, FormatJSON, FormatYAML, FormatTSV:
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format: %s (must be table, json, yaml, or tsv)", o.Format)
+	}
+}
+
+// IsTable returns true if human-readable table output is selected
+func (o *Options) IsTable() bool {
+	return o.Template == "" && o.Format == FormatTable
+}
+
+// Result is the data of a read command in all of its output forms
+type Result struct {
+	// Data is rendered as JSON/YAML and is the input of --template. Its JSON form is the stable schema.
+	Data interface{}
+	// Columns are the TSV columns as JSON field names of the elements of Data (dotted for nested fields).
+	// If empty, TSV output is not supported.
+	Columns []string
+	// Table writes the human-readable output
+	Table func(w io.Writer) error
+}
+
+// Print renders result to stdout according to opts
+func Print(opts Options, result Result) error {
+	return Render(os.Stdout, opts, result)
+}
+
+// Render renders result to w according to opts
+func Render(w io.Writer, opts Options, result Result) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	if opts.Template != "" {
+		return renderTemplate(w, opts.Template, result.Data)
+	}
+
+	switch opts.Format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(result.Data, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshaling JSON: %w", err)
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	case FormatYAML:
+		generic, err := toGeneric(result.Data)
+		if err != nil {
+			return err
+		}
+		data, err := yaml.Marshal(generic)
+		if err != nil {
+			return fmt.Errorf("error marshaling YAML: %w", err)
+		}
+		_, err = w.Write(data)
+		return err
+	case FormatTSV:
+		return renderTSV(w, result)
+	default:
+		if result.Table == nil {
+			return fmt.Errorf("table output is not supported for this command")
+		}
+		return result.Table(w)
+	}
+}
+
+// KeyValueTable returns a Table function that writes each object in data as "key  value" lines,
+// for commands that show the details of a few items. Keys listed in columns come first and the
+// other keys follow in sorted order; empty values are omitted. Objects are separated by a blank line.
+func KeyValueTable(data interface{}, columns []string) func(w io.Writer) error {
+	return func(w io.Writer) error {
+		generic, err := toGeneric(data)
+		if err != nil {
+			return err
+		}
+		var objects []interface{}
+		switch v := generic.(type) {
+		case []interface{}:
+			objects = v
+		case nil:
+			objects = nil
+		default:
+			objects = []interface{}{v}
+		}
+
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for i, object := range objects {
+			m, ok := object.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			if i > 0 {
+				fmt.Fprintln(tw)
+			}
+			keys := append([]string{}, columns...)
+			var rest []string
+			for k := range m {
+				if !containsString(columns, k) {
+					rest = append(rest, k)
+				}
+			}
+			sort.Strings(rest)
+			for _, k := range append(keys, rest...) {
+				if value := tsvValue(m[k]); value != "" {
+					fmt.Fprintf(tw, "%s\t%s\n", k, value)
+				}
+			}
+		}
+		return tw.Flush()
+	}
+}
+
+func containsString(items []string, s string) bool {
+	for _, item := range items {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// toGeneric converts v to maps/slices keyed by JSON field names, so that YAML and templates
+// see the same field names (and omitempty behavior) as JSON
+func toGeneric(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling output: %w", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var generic interface{}
+	if err := decoder.Decode(&generic); err != nil {
+		return nil, fmt.Errorf("error converting output: %w", err)
+	}
+	return convertNumbers(generic), nil
+}
+
+// convertNumbers replaces json.Number with int64 or float64 so that YAML does not quote them
+func convertNumbers(v interface{}) interface{} {
+	switch value := v.(type) {
+	case json.Number:
+		if i, err := value.Int64(); err == nil {
+			return i
+		}
+		f, _ := value.Float64()
+		return f
+	case []interface{}:
+		for i := range value {
+			value[i] = convertNumbers(value[i])
+		}
+	case map[string]interface{}:
+		for k := range value {
+			value[k] = convertNumbers(value[k])
+		}
+	}
+	return v
+}
+
+var templateFuncs = template.FuncMap{
+	"join": func(sep string, items []interface{}) string {
+		parts := make([]string, len(items))
+		for i, item := range items {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, sep)
+	},
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+func renderTemplate(w io.Writer, text string, data interface{}) error {
+	tmpl, err := template.New("output").Funcs(templateFuncs).Parse(text)
+	if err != nil {
+		return fmt.Errorf("invalid template: %w", err)
+	}
+	generic, err := toGeneric(data)
+	if err != nil {
+		return err
+	}
+	if err := tmpl.Execute(w, generic); err != nil {
+		return fmt.Errorf("error executing template: %w", err)
+	}
+	return nil
+}
+
+func renderTSV(w io.Writer, result Result) error {
+	if len(result.Columns) == 0 {
+		return fmt.Errorf("tsv output is not supported for this command")
+	}
+	generic, err := toGeneric(result.Data)
+	if err != nil {
+		return err
+	}
+
+	var rows []interface{}
+	switch v := generic.(type) {
+	case []interface{}:
+		rows = v
+	case nil:
+		rows = nil
+	default:
+		rows = []interface{}{v}
+	}
+
+	fmt.Fprintln(w, strings.Join(result.Columns, "\t"))
+	for _, row := range rows {
+		values := make([]string, len(result.Columns))
+		for i, column := range result.Columns {
+			values[i] = tsvValue(lookup(row, column))
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return nil
+}
+
+// lookup returns the value at a dotted JSON path
+func lookup(v interface{}, path string) interface{} {
+	for _, key := range strings.Split(path, ".") {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		v = m[key]
+	}
+	return v
+}
+
+// tsvValue formats a value for a TSV cell. Lists are joined with commas; tabs and newlines are replaced with spaces.
+func tsvValue(v interface{}) string {
+	var s string
+	switch value := v.(type) {
+	case nil:
+		s = ""
+	case string:
+		s = value
+	case []interface{}:
+		parts := make([]string, len(value))
+		for i, part := range value {
+			parts[i] = tsvValue(part)
+		}
+		s = strings.Join(parts, ",")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(value))
+		for k := range value {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		parts := make([]string, len(keys))
+		for i, k := range keys {
+			parts[i] = k + "=" + tsvValue(value[k])
+		}
+		s = strings.Join(parts, ",")
+	default:
+		s = fmt.Sprint(value)
+	}
+	return strings.NewReplacer("\t", " ", "\n", " ", "\r", " ").Replace(s)
+}
@@ -0,0 +1,59 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// PrefixWriter writes complete lines to an underlying writer with a prefix, so that output of
+// concurrent tasks can share a terminal without lines being interleaved mid-line.
+// Writers created with the same mutex never write to the underlying writer at the same time.
+type PrefixWriter struct {
+	w      io.Writer
+	prefix string
+	mu     *sync.Mutex
+	buf    bytes.Buffer
+}
+
+// NewPrefixWriter returns a writer that prefixes every line written to w
+func NewPrefixWriter(w io.Writer, prefix string, mu *sync.Mutex) *PrefixWriter {
+	return &PrefixWriter{w: w, prefix: prefix, mu: mu}
+}
+
+// Write buffers p and writes out every complete line
+func (pw *PrefixWriter) Write(p []byte) (int, error) {
+	pw.buf.Write(p)
+	for {
+		line, err := pw.buf.ReadBytes('\n')
+		if err != nil {
+			// Incomplete line: keep it for the next write
+			pw.buf.Reset()
+			pw.buf.Write(line)
+			return len(p), nil
+		}
+		if err := pw.writeLine(line); err != nil {
+			return len(p), err
+		}
+	}
+}
+
+// Flush writes out a trailing incomplete line
+func (pw *PrefixWriter) Flush() error {
+	if pw.buf.Len() == 0 {
+		return nil
+	}
+	line := append(pw.buf.Bytes(), '\n')
+	pw.buf.Reset()
+	return pw.writeLine(line)
+}
+
+func (pw *PrefixWriter) writeLine(line []byte) error {
+	pw.mu.Lock()
+	defer pw.mu.Unlock()
+	if _, err := io.WriteString(pw.w, pw.prefix); err != nil {
+		return err
+	}
+	_, err := pw.w.Write(line)
+	return err
+}
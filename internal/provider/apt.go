@@ -0,0 +1,300 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// AptProvider implements the Provider interface for Debian and Ubuntu packages installed with apt-get.
+// Commands that change the system run with sudo unless al runs as root.
+type AptProvider struct {
+	name string
+	runner
+}
+
+// NewAptProvider creates a new apt provider
+func NewAptProvider() *AptProvider {
+	return &AptProvider{name: "apt", runner: newRunner("apt")}
+}
+
+// Name returns the provider name
+func (p *AptProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the package name as the ID and the name
+func (p *AptProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, id, nil
+}
+
+// Platforms returns the operating systems apt runs on
+func (p *AptProvider) Platforms() []string {
+	return []string{"linux"}
+}
+
+// RequiresRoot reports that apt-get runs through sudo
+func (p *AptProvider) RequiresRoot() bool {
+	return true
+}
+
+// CheckInstalled checks if apt is installed by running `apt-get --version`
+func (p *AptProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "apt-get", "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, apt is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of apt
+func (p *AptProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "apt-get", "--version")
+	if err != nil {
+		return "", err
+	}
+	// The first line is "apt 2.4.11 (amd64)"
+	line, _, _ := strings.Cut(string(output), "\n")
+	return versionField(line), nil
+}
+
+// Install returns an error: apt comes with the operating system
+func (p *AptProvider) Install(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check apt installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("apt is already installed")
+	}
+	return fmt.Errorf("apt is not available on this system (it comes with Debian and Ubuntu)")
+}
+
+// SetupConfig sets up the configuration for apt provider
+func (p *AptProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if apt is not installed
+func (p *AptProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check apt installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("apt is not installed. Please install it first using 'al provider add apt'")
+	}
+	return nil
+}
+
+// aptGetCmd returns the command that runs apt-get as root without prompts (debconf questions get their
+// default answers). Callers retry commands that download and run the others once.
+func aptGetCmd(args ...string) (string, []string) {
+	return sudo("env", append([]string{"DEBIAN_FRONTEND=noninteractive", "apt-get", "-y"}, args...)...)
+}
+
+// InstallPackage installs a package with `apt-get install`
+func (p *AptProvider) InstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.stdout, "Installing %s using apt...\n", packageID)
+	name, args := aptGetCmd("install", packageID)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls a package with `apt-get remove`
+func (p *AptProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.stdout, "Uninstalling %s using apt...\n", packageID)
+	name, args := aptGetCmd("remove", packageID)
+	if err := p.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage upgrades an installed package with `apt-get install --only-upgrade`
+func (p *AptProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	return p.UpgradePackages(ctx, []string{packageID})
+}
+
+// UpgradePackages upgrades several installed packages with one `apt-get install --only-upgrade`
+func (p *AptProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	names := strings.Join(packageIDs, ", ")
+	fmt.Fprintf(p.stdout, "Upgrading %s using apt...\n", names)
+	name, args := aptGetCmd(append([]string{"install", "--only-upgrade"}, packageIDs...)...)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to upgrade packages %s: %w", names, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", names)
+	return nil
+}
+
+// Upgrade updates the package lists with `apt-get update`, so that outdated and upgrade see new versions
+func (p *AptProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.stdout, "Updating apt package lists...")
+	name, args := aptGetCmd("update")
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to update apt: %w", err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully updated apt")
+	return nil
+}
+
+// SearchPackage searches package names with `apt-cache search --names-only`
+func (p *AptProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	output, err := p.output(ctx, "apt-cache", "search", "--names-only", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search packages: %w", err)
+	}
+
+	// Each line is "<name> - <description>"
+	results := []SearchResult{}
+	for _, line := range strings.Split(string(output), "\n") {
+		name, description, found := strings.Cut(line, " - ")
+		if !found || name == "" {
+			continue
+		}
+		results = append(results, SearchResult{ID: name, Name: name, Description: description})
+	}
+	return results, nil
+}
+
+// aptPackageName removes the architecture qualifier of a multiarch package name ("libc6:i386")
+func aptPackageName(name string) string {
+	name, _, _ = strings.Cut(strings.TrimSpace(name), ":")
+	return name
+}
+
+// ListInstalled lists the manually installed packages (`apt-mark showmanual`) and the registered
+// ones with their versions (`dpkg-query`). Packages installed as dependencies are left out.
+func (p *AptProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	wanted, err := registeredIDs(p.name)
+	if err != nil {
+		return nil, err
+	}
+	manual, err := p.output(ctx, "apt-mark", "showmanual")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list manually installed packages: %w", err)
+	}
+	for _, name := range strings.Fields(string(manual)) {
+		wanted[aptPackageName(name)] = true
+	}
+
+	output, err := p.output(ctx, "dpkg-query", "-W", "-f", "${Package}\t${Version}\t${db:Status-Status}\n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	var packages []InstalledPackage
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 || fields[2] != "installed" {
+			continue
+		}
+		name := fields[0]
+		if !wanted[name] || seen[name] {
+			continue
+		}
+		seen[name] = true
+		packages = append(packages, InstalledPackage{ID: name, Name: name, Version: fields[1]})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// aptUpgradableRegex matches a line of `apt list --upgradable`:
+// "curl/jammy-updates 7.81.0-1ubuntu1.16 amd64 [upgradable from: 7.81.0-1ubuntu1.15]"
+var aptUpgradableRegex = regexp.MustCompile(`^([^/\s]+)/\S+\s+(\S+)\s+\S+\s+\[upgradable from: ([^\]]+)\]`)
+
+// ListOutdated lists the installed packages with newer versions in the package lists
+// (`apt list --upgradable`). Run `al provider upgrade apt` to update the lists first.
+func (p *AptProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	output, err := p.output(ctx, "apt", "list", "--upgradable")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upgradable packages: %w", err)
+	}
+
+	var packages []OutdatedPackage
+	for _, line := range strings.Split(string(output), "\n") {
+		m := aptUpgradableRegex.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		packages = append(packages, OutdatedPackage{ID: m[1], Name: m[1], CurrentVersion: m[3], LatestVersion: m[2]})
+	}
+	return packages, nil
+}
@@ -0,0 +1,424 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/download"
+	"github.com/kkato1030/al/internal/version"
+)
+
+// DefaultBinaryDir is the directory binary packages are installed to when they have no Dir
+const DefaultBinaryDir = "~/.local/bin"
+
+// BinaryProvider implements the Provider interface for binaries downloaded from release URLs such as
+// GitHub releases. A binary package's ID is the name of the installed binary; PackageConfig.URL is
+// the download URL template, and every download is verified with PackageConfig.SHA256.
+type BinaryProvider struct {
+	name string
+	runner
+}
+
+// NewBinaryProvider creates a new binary provider
+func NewBinaryProvider() *BinaryProvider {
+	return &BinaryProvider{name: "binary", runner: newRunner("binary")}
+}
+
+// Name returns the provider name
+func (p *BinaryProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the name of the installed binary as the ID and the name
+func (p *BinaryProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, id, nil
+}
+
+// ExpandBinaryTemplate fills in a URL or member template: {version}, {os} (e.g. darwin, linux), and
+// {arch} (e.g. arm64, amd64)
+func ExpandBinaryTemplate(template, version string) string {
+	return strings.NewReplacer("{version}", version, "{os}", runtime.GOOS, "{arch}", runtime.GOARCH).Replace(template)
+}
+
+// isChecksumURL reports whether the SHA256 of a binary package is the URL of a checksums file
+func isChecksumURL(sha256 string) bool {
+	return strings.HasPrefix(sha256, "https://") || strings.HasPrefix(sha256, "http://") || strings.HasPrefix(sha256, "file://")
+}
+
+// ValidateBinaryChecksum validates the SHA256 of a binary package: a hex-encoded SHA-256 digest,
+// or the URL template of a checksums file
+func ValidateBinaryChecksum(sha256 string) error {
+	if download.IsSHA256(sha256) || isChecksumURL(sha256) {
+		return nil
+	}
+	return fmt.Errorf("invalid checksum: %s (must be a SHA-256 digest or the URL of a checksums file)", sha256)
+}
+
+// CheckInstalled always returns true: the binary provider downloads with al itself
+func (p *BinaryProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	return true, nil
+}
+
+// Install does nothing: the binary provider needs no package manager
+func (p *BinaryProvider) Install(ctx context.Context) error {
+	return nil
+}
+
+// SetupConfig sets up the configuration for binary provider
+func (p *BinaryProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Add provider to config (binary provider doesn't have a version)
+	if err := saveProviderVersion(p.name, ""); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// registeredPackage returns the registered binary package with the ID
+func (p *BinaryProvider) registeredPackage(packageID string) (*config.PackageConfig, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+	for i := range packagesConfig.Packages {
+		pkg := &packagesConfig.Packages[i]
+		if pkg.Provider == p.name && pkg.ID == packageID {
+			return pkg, nil
+		}
+	}
+	return nil, fmt.Errorf("binary package %s is not registered", packageID)
+}
+
+// latestVersion returns the latest version of a package from its version source, or "" if it has none
+func (p *BinaryProvider) latestVersion(ctx context.Context, pkg config.PackageConfig) (string, error) {
+	source, err := download.NewVersionSource(pkg.LatestFrom, pkg.URL)
+	if err != nil || source == nil {
+		return "", err
+	}
+	latest, err := source.Latest(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check the latest version of %s: %w", pkg.ID, err)
+	}
+	return latest, nil
+}
+
+// targetVersion returns the version a package should be at: the newer of its registered version
+// and the latest version from its source
+func (p *BinaryProvider) targetVersion(ctx context.Context, pkg config.PackageConfig) (string, error) {
+	latest, err := p.latestVersion(ctx, pkg)
+	if err != nil {
+		return "", err
+	}
+	if latest == "" || (pkg.Version != "" && version.Compare(latest, pkg.Version) <= 0) {
+		return pkg.Version, nil
+	}
+	return latest, nil
+}
+
+// checksum returns the expected SHA-256 of the download fileName of a version
+func (p *BinaryProvider) checksum(ctx context.Context, pkg config.PackageConfig, ver, fileName string) (string, error) {
+	switch {
+	case pkg.SHA256 == "":
+		return "", fmt.Errorf("binary package %s has no checksum. Add it with --sha256", pkg.ID)
+	case isChecksumURL(pkg.SHA256):
+		return download.Checksum(ctx, ExpandBinaryTemplate(pkg.SHA256, ver), fileName)
+	case pkg.Version != "" && pkg.Version != ver:
+		return "", fmt.Errorf("the checksum of %s is for version %s, not %s. Add it again with --version %s --sha256 <checksum>, or use a checksums file URL as --sha256", pkg.ID, pkg.Version, ver, ver)
+	}
+	return pkg.SHA256, nil
+}
+
+// installDir returns the directory a package is installed to
+func installDir(pkg config.PackageConfig) (string, error) {
+	dir := pkg.Dir
+	if dir == "" {
+		dir = DefaultBinaryDir
+	}
+	return expandHome(dir)
+}
+
+// installVersion downloads a version of a package, verifies its checksum, extracts the binary from
+// an archive, and installs it to the package's directory, recording a receipt
+func (p *BinaryProvider) installVersion(ctx context.Context, pkg config.PackageConfig, ver string) error {
+	downloadURL := ExpandBinaryTemplate(pkg.URL, ver)
+	parsed, err := url.Parse(downloadURL)
+	if err != nil {
+		return fmt.Errorf("invalid URL %s: %w", downloadURL, err)
+	}
+	fileName := path.Base(parsed.Path)
+
+	sum, err := p.checksum(ctx, pkg, ver, fileName)
+	if err != nil {
+		return err
+	}
+	dir, err := installDir(pkg)
+	if err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", "al-binary-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	// Download and verify the release file
+	fmt.Fprintf(p.stdout, "Downloading %s...\n", downloadURL)
+	archivePath := filepath.Join(tmpDir, fileName)
+	if err := download.VerifiedFile(ctx, downloadURL, archivePath, sum); err != nil {
+		return fmt.Errorf("failed to download %s: %w", fileName, err)
+	}
+
+	// Extract the binary from an archive; any other file is the binary itself
+	binaryPath := archivePath
+	if download.IsArchive(fileName) {
+		member := pkg.ID
+		if pkg.Member != "" {
+			member = ExpandBinaryTemplate(pkg.Member, ver)
+		}
+		binaryPath = filepath.Join(tmpDir, ".al-binary")
+		if err := download.Extract(archivePath, member, binaryPath); err != nil {
+			return fmt.Errorf("failed to extract: %w", err)
+		}
+	}
+
+	target := filepath.Join(dir, pkg.ID)
+	if err := installFile(binaryPath, target); err != nil {
+		return fmt.Errorf("failed to install %s: %w", target, err)
+	}
+
+	receipt := &config.BinaryReceipt{Version: ver, URL: downloadURL, SHA256: sum, Path: target, InstalledAt: time.Now()}
+	if err := config.SaveBinaryReceipt(pkg.ID, receipt); err != nil {
+		return fmt.Errorf("failed to save receipt: %w", err)
+	}
+	return nil
+}
+
+// installFile copies src to the executable file target, replacing it atomically
+func installFile(src, target string) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := io.Copy(tmp, in); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// InstallBinary installs a binary package that may not be registered yet, at its version or, without
+// one, at the latest version. It returns the installed version.
+func (p *BinaryProvider) InstallBinary(ctx context.Context, pkg config.PackageConfig) (string, error) {
+	if pkg.URL == "" {
+		return "", fmt.Errorf("binary package %s needs a download URL (--url)", pkg.ID)
+	}
+	ver := pkg.Version
+	if ver == "" {
+		latest, err := p.latestVersion(ctx, pkg)
+		if err != nil {
+			return "", err
+		}
+		if latest == "" {
+			return "", fmt.Errorf("binary package %s needs --version: its latest version cannot be checked (set --latest-from)", pkg.ID)
+		}
+		ver = latest
+	}
+
+	fmt.Fprintf(p.stdout, "Installing %s %s...\n", pkg.ID, ver)
+	if err := p.installVersion(ctx, pkg, ver); err != nil {
+		return "", fmt.Errorf("failed to install package %s: %w", pkg.ID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s %s\n", pkg.ID, ver)
+	return ver, nil
+}
+
+// InstallPackage installs a registered binary package
+func (p *BinaryProvider) InstallPackage(ctx context.Context, packageID string) error {
+	pkg, err := p.registeredPackage(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+	_, err = p.InstallBinary(ctx, *pkg)
+	return err
+}
+
+// UninstallPackage removes the installed binary and its receipt
+func (p *BinaryProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	receipt, err := config.LoadBinaryReceipt(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+	if receipt == nil {
+		fmt.Fprintf(p.stdout, "%s is not installed\n", packageID)
+		return nil
+	}
+
+	fmt.Fprintf(p.stdout, "Removing %s...\n", receipt.Path)
+	if err := os.Remove(receipt.Path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+	if err := config.RemoveBinaryReceipt(packageID); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage installs the latest version of a package, or its registered version if that is newer
+func (p *BinaryProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	_, err := p.UpgradePackageVersion(ctx, packageID)
+	return err
+}
+
+// UpgradePackageVersion installs the latest version of a package, or its registered version if
+// that is newer, and returns the installed version. The caller records it in packages.json.
+func (p *BinaryProvider) UpgradePackageVersion(ctx context.Context, packageID string) (string, error) {
+	pkg, err := p.registeredPackage(packageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	receipt, err := config.LoadBinaryReceipt(packageID)
+	if err != nil {
+		return "", fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	if receipt == nil {
+		return "", fmt.Errorf("failed to upgrade package %s: it is not installed", packageID)
+	}
+
+	target, err := p.targetVersion(ctx, *pkg)
+	if err != nil {
+		return "", fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	if target == "" || target == receipt.Version {
+		fmt.Fprintf(p.stdout, "%s %s is up to date\n", packageID, receipt.Version)
+		return receipt.Version, nil
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s from %s to %s...\n", packageID, receipt.Version, target)
+	if err := p.installVersion(ctx, *pkg, target); err != nil {
+		return "", fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", packageID)
+	return target, nil
+}
+
+// Upgrade does nothing: the binary provider has nothing to upgrade
+func (p *BinaryProvider) Upgrade(ctx context.Context) error {
+	fmt.Fprintln(p.stdout, "binary provider has nothing to upgrade")
+	return nil
+}
+
+// SearchPackage is not supported: a binary package is any release URL
+func (p *BinaryProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	return nil, fmt.Errorf("search is not supported by binary")
+}
+
+// installedPackages returns the registered binary packages with their receipts, once per ID,
+// leaving out those that are not installed
+func (p *BinaryProvider) installedPackages() ([]config.PackageConfig, map[string]*config.BinaryReceipt, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+
+	receipts := make(map[string]*config.BinaryReceipt)
+	var packages []config.PackageConfig
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider != p.name {
+			continue
+		}
+		if _, ok := receipts[pkg.ID]; ok {
+			continue
+		}
+		receipt, err := config.LoadBinaryReceipt(pkg.ID)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the receipt of %s: %w", pkg.ID, err)
+		}
+		if receipt == nil {
+			continue
+		}
+		if _, err := os.Stat(receipt.Path); err != nil {
+			continue
+		}
+		receipts[pkg.ID] = receipt
+		packages = append(packages, pkg)
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, receipts, nil
+}
+
+// ListInstalled lists the installed binary packages with the versions in their receipts
+func (p *BinaryProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	packages, receipts, err := p.installedPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	var installed []InstalledPackage
+	for _, pkg := range packages {
+		installed = append(installed, InstalledPackage{ID: pkg.ID, Name: pkg.Name, Version: receipts[pkg.ID].Version})
+	}
+	return installed, nil
+}
+
+// ListOutdated lists the installed binary packages older than their latest or registered version
+func (p *BinaryProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	packages, receipts, err := p.installedPackages()
+	if err != nil {
+		return nil, err
+	}
+
+	var outdated []OutdatedPackage
+	for _, pkg := range packages {
+		target, err := p.targetVersion(ctx, pkg)
+		if err != nil {
+			return nil, err
+		}
+		current := receipts[pkg.ID].Version
+		if target == "" || target == current || version.Compare(target, current) < 0 {
+			continue
+		}
+		outdated = append(outdated, OutdatedPackage{ID: pkg.ID, Name: pkg.Name, CurrentVersion: current, LatestVersion: target})
+	}
+	return outdated, nil
+}
@@ -1,11 +1,11 @@
 package provider
 
 import (
+	"context"
+	"errors"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/kkato1030/al/internal/config"
 )
@@ -13,11 +13,12 @@ import (
 // BrewProvider implements the Provider interface for Homebrew
 type BrewProvider struct {
 	name string
+	runner
 }
 
 // NewBrewProvider creates a new brew provider
 func NewBrewProvider() *BrewProvider {
-	return &BrewProvider{name: "brew"}
+	return &BrewProvider{name: "brew", runner: newRunner("brew")}
 }
 
 // Name returns the provider name
@@ -26,10 +27,12 @@ func (p *BrewProvider) Name() string {
 }
 
 // CheckInstalled checks if brew is installed by running `brew --version`
-func (p *BrewProvider) CheckInstalled() (bool, error) {
-	cmd := exec.Command("brew", "--version")
-	err := cmd.Run()
+func (p *BrewProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "brew", "--version")
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
 		// If command fails, brew is not installed
 		return false, nil
 	}
@@ -37,9 +40,8 @@ func (p *BrewProvider) CheckInstalled() (bool, error) {
 }
 
 // GetVersion returns the version of brew
-func (p *BrewProvider) GetVersion() (string, error) {
-	cmd := exec.Command("brew", "--version")
-	output, err := cmd.Output()
+func (p *BrewProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "brew", "--version")
 	if err != nil {
 		return "", err
 	}
@@ -57,9 +59,9 @@ func (p *BrewProvider) GetVersion() (string, error) {
 }
 
 // Install installs Homebrew using the official installation script
-func (p *BrewProvider) Install() error {
+func (p *BrewProvider) Install(ctx context.Context) error {
 	// Check if brew is already installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check brew installation: %w", err)
 	}
@@ -69,12 +71,7 @@ func (p *BrewProvider) Install() error {
 
 	// Run the official Homebrew installation script
 	installScript := "/bin/bash -c \"$(curl -fsSL https://raw.githubusercontent.com/Homebrew/install/HEAD/install.sh)\""
-	cmd := exec.Command("sh", "-c", installScript)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	if err := p.run(ctx, "sh", "-c", installScript); err != nil {
 		return fmt.Errorf("failed to install brew: %w", err)
 	}
 
@@ -82,27 +79,21 @@ func (p *BrewProvider) Install() error {
 }
 
 // SetupConfig sets up the configuration for brew provider
-func (p *BrewProvider) SetupConfig() error {
+func (p *BrewProvider) SetupConfig(ctx context.Context) error {
 	// Ensure config directory exists
 	if err := config.EnsureConfigDir(); err != nil {
 		return fmt.Errorf("failed to ensure config directory: %w", err)
 	}
 
 	// Get version
-	version, err := p.GetVersion()
+	version, err := p.GetVersion(ctx)
 	if err != nil {
 		// If version cannot be retrieved, continue without it
 		version = ""
 	}
 
 	// Add provider to config
-	providerConfig := config.ProviderConfig{
-		Name:        p.name,
-		InstalledAt: time.Now(),
-		Version:     version,
-	}
-
-	if err := config.AddOrUpdateProvider(providerConfig); err != nil {
+	if err := saveProviderVersion(p.name, version); err != nil {
 		return fmt.Errorf("failed to save provider config: %w", err)
 	}
 
@@ -128,60 +119,112 @@ func (p *BrewProvider) parsePackageID(packageID string) (pkgType, pkgName string
 	return pkgType, pkgName, nil
 }
 
-// detectPackageType detects if a package is a formula, cask, or tap
-func (p *BrewProvider) detectPackageType(packageName string) (string, error) {
-	// Try cask first (casks can have same name as formulas)
-	cmd := exec.Command("brew", "info", "--cask", packageName)
-	err := cmd.Run()
-	if err == nil {
-		return "cask", nil
+// isBrewGreedyOption reports whether option is one of the --greedy options, which only brew upgrade accepts
+func isBrewGreedyOption(option string) bool {
+	return option == "--greedy" || strings.HasPrefix(option, "--greedy-")
+}
+
+// brewInstallOptions returns the options that brew install accepts
+func brewInstallOptions(options []string) []string {
+	var args []string
+	for _, option := range options {
+		if !isBrewGreedyOption(option) {
+			args = append(args, option)
+		}
 	}
+	return args
+}
 
-	// Try formula
-	cmd = exec.Command("brew", "info", packageName)
-	err = cmd.Run()
-	if err == nil {
-		return "formula", nil
-	}
-
-	// Try tap (format: tap_name/package_name or just tap_name)
-	if strings.Contains(packageName, "/") {
-		parts := strings.SplitN(packageName, "/", 2)
-		if len(parts) == 2 {
-			// Check if tap exists
-			cmd = exec.Command("brew", "tap-info", parts[0])
-			err = cmd.Run()
-			if err == nil {
-				return "tap", nil
-			}
+// brewUpgradeOptions returns the options that brew upgrade accepts. Casks take their options as
+// they are. For formulae, brew reuses the build options recorded at install, so only --HEAD is kept
+// (as --fetch-HEAD, to upgrade to the latest commit).
+func brewUpgradeOptions(pkgType string, options []string) []string {
+	if pkgType == "cask" {
+		return options
+	}
+	var args []string
+	for _, option := range options {
+		if option == "--HEAD" {
+			args = append(args, "--fetch-HEAD")
 		}
-	} else {
-		// Check if it's a tap name itself
-		cmd = exec.Command("brew", "tap-info", packageName)
-		err = cmd.Run()
-		if err == nil {
-			return "tap", nil
+	}
+	return args
+}
+
+// detectPackageTypes detects whether each name is a formula, cask, or tap.
+// Casks are tried first (casks can have the same name as formulae) with one batched
+// `brew info --json=v2 --cask` call, then the rest with one `--formula` call; only names
+// found by neither are checked with `brew tap-info`. Undetermined names default to formula.
+func (p *BrewProvider) detectPackageTypes(ctx context.Context, names []string) map[string]string {
+	types := make(map[string]string, len(names))
+	remaining := names
+	for _, pkgType := range []string{"cask", "formula"} {
+		if len(remaining) == 0 {
+			break
+		}
+		infos, err := p.Info(ctx, pkgType, remaining)
+		if err != nil {
+			continue
+		}
+		found := indexBrewInfo(remaining, infos)
+		var rest []string
+		for _, name := range remaining {
+			if _, ok := found[name]; ok {
+				types[name] = pkgType
+			} else {
+				rest = append(rest, name)
+			}
 		}
+		remaining = rest
 	}
 
-	// Default to formula if cannot determine
-	return "formula", nil
+	for _, name := range remaining {
+		types[name] = "formula"
+		// user/repo/tool is a formula of the tap user/repo (which may not be tapped yet)
+		if strings.Count(name, "/") >= 2 {
+			continue
+		}
+		// Try tap (format: user/repo)
+		if err := p.check(ctx, "brew", "tap-info", name); err == nil {
+			types[name] = "tap"
+		}
+	}
+	return types
 }
 
 // GeneratePackageID generates package ID in format "{formula,cask,tap}:<package_name>"
-func (p *BrewProvider) GeneratePackageID(packageName string) (string, error) {
-	pkgType, err := p.detectPackageType(packageName)
-	if err != nil {
+func (p *BrewProvider) GeneratePackageID(ctx context.Context, packageName string) (string, error) {
+	pkgType := p.detectPackageTypes(ctx, []string{packageName})[packageName]
+	if err := ctx.Err(); err != nil {
 		return "", err
 	}
 	return fmt.Sprintf("%s:%s", pkgType, packageName), nil
 }
 
+// ResolvePackage returns id, or the ID detected with GeneratePackageID when id is empty
+func (p *BrewProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id != "" {
+		return id, name, nil
+	}
+	id, err := p.GeneratePackageID(ctx, name)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to detect the package type of %s: %w", name, err)
+	}
+	return id, name, nil
+}
+
 // InstallPackage installs a package using brew
 // packageID is in format "{formula,cask,tap}:<package_name>"
-func (p *BrewProvider) InstallPackage(packageID string) error {
+func (p *BrewProvider) InstallPackage(ctx context.Context, packageID string) error {
+	return p.InstallPackageWithOptions(ctx, packageID, nil)
+}
+
+// InstallPackageWithOptions installs a package using brew, passing options to brew install
+// (e.g. "--HEAD" or "--no-quarantine"). Upgrade-only options such as "--greedy" and the options of
+// taps are ignored.
+func (p *BrewProvider) InstallPackageWithOptions(ctx context.Context, packageID string, options []string) error {
 	// Check if brew is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check brew installation: %w", err)
 	}
@@ -196,32 +239,33 @@ func (p *BrewProvider) InstallPackage(packageID string) error {
 	}
 
 	// Run brew install command
-	fmt.Printf("Installing %s using brew...\n", pkgName)
-	var cmd *exec.Cmd
+	fmt.Fprintf(p.stdout, "Installing %s using brew...\n", pkgName)
+	var args []string
 	if pkgType == "cask" {
-		cmd = exec.Command("brew", "install", "--cask", pkgName)
+		args = append(append([]string{"install", "--cask"}, brewInstallOptions(options)...), pkgName)
 	} else if pkgType == "tap" {
-		cmd = exec.Command("brew", "tap", pkgName)
+		if err := p.InstallTap(ctx, pkgName, ""); err != nil {
+			return err
+		}
+		fmt.Fprintf(p.stdout, "Successfully installed %s\n", pkgName)
+		return nil
 	} else {
-		cmd = exec.Command("brew", "install", pkgName)
+		args = append(append([]string{"install"}, brewInstallOptions(options)...), pkgName)
 	}
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := p.runWithRetry(ctx, "brew", args...); err != nil {
 		return fmt.Errorf("failed to install package %s: %w", pkgName, err)
 	}
 
-	fmt.Printf("Successfully installed %s\n", pkgName)
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", pkgName)
 	return nil
 }
 
 // UninstallPackage uninstalls a package using brew
 // packageID is in format "{formula,cask,tap}:<package_name>"
-func (p *BrewProvider) UninstallPackage(packageID string) error {
+func (p *BrewProvider) UninstallPackage(ctx context.Context, packageID string) error {
 	// Check if brew is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check brew installation: %w", err)
 	}
@@ -236,32 +280,35 @@ func (p *BrewProvider) UninstallPackage(packageID string) error {
 	}
 
 	// Run brew uninstall command
-	fmt.Printf("Uninstalling %s using brew...\n", pkgName)
-	var cmd *exec.Cmd
+	fmt.Fprintf(p.stdout, "Uninstalling %s using brew...\n", pkgName)
+	var args []string
 	if pkgType == "cask" {
-		cmd = exec.Command("brew", "uninstall", "--cask", pkgName)
+		args = []string{"uninstall", "--cask", pkgName}
 	} else if pkgType == "tap" {
-		cmd = exec.Command("brew", "untap", pkgName)
+		args = []string{"untap", pkgName}
 	} else {
-		cmd = exec.Command("brew", "uninstall", pkgName)
+		args = []string{"uninstall", pkgName}
 	}
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := p.run(ctx, "brew", args...); err != nil {
 		return fmt.Errorf("failed to uninstall package %s: %w", pkgName, err)
 	}
 
-	fmt.Printf("Successfully uninstalled %s\n", pkgName)
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", pkgName)
 	return nil
 }
 
 // UpgradePackage upgrades a package using brew
 // packageID is in format "{formula,cask,tap}:<package_name>"
-func (p *BrewProvider) UpgradePackage(packageID string) error {
+func (p *BrewProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	return p.UpgradePackageWithOptions(ctx, packageID, nil)
+}
+
+// UpgradePackageWithOptions upgrades a package using brew, passing the options that brew upgrade
+// accepts (see brewUpgradeOptions)
+func (p *BrewProvider) UpgradePackageWithOptions(ctx context.Context, packageID string, options []string) error {
 	// Check if brew is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check brew installation: %w", err)
 	}
@@ -276,32 +323,30 @@ func (p *BrewProvider) UpgradePackage(packageID string) error {
 	}
 
 	// Run brew upgrade command
-	fmt.Printf("Upgrading %s using brew...\n", pkgName)
-	var cmd *exec.Cmd
+	fmt.Fprintf(p.stdout, "Upgrading %s using brew...\n", pkgName)
+	var args []string
 	if pkgType == "cask" {
-		cmd = exec.Command("brew", "upgrade", "--cask", pkgName)
+		args = append(append([]string{"upgrade", "--cask"}, brewUpgradeOptions(pkgType, options)...), pkgName)
 	} else if pkgType == "tap" {
 		// Taps don't have upgrade, but we can reinstall
-		cmd = exec.Command("brew", "tap", pkgName)
+		args = []string{"tap", pkgName}
 	} else {
-		cmd = exec.Command("brew", "upgrade", pkgName)
+		args = append(append([]string{"upgrade"}, brewUpgradeOptions(pkgType, options)...), pkgName)
 	}
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
 
-	if err := cmd.Run(); err != nil {
+	if err := p.run(ctx, "brew", args...); err != nil {
 		return fmt.Errorf("failed to upgrade package %s: %w", pkgName, err)
 	}
 
-	fmt.Printf("Successfully upgraded %s\n", pkgName)
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", pkgName)
 	return nil
 }
 
-// Upgrade upgrades brew itself
-func (p *BrewProvider) Upgrade() error {
+// UpgradePackages upgrades several packages with one `brew upgrade` for formulae and one
+// `brew upgrade --cask` for casks. Taps are re-tapped one by one.
+func (p *BrewProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
 	// Check if brew is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check brew installation: %w", err)
 	}
@@ -309,38 +354,82 @@ func (p *BrewProvider) Upgrade() error {
 		return fmt.Errorf("brew is not installed. Please install it first using 'al provider add brew'")
 	}
 
-	// Run brew update and upgrade
-	fmt.Println("Updating brew...")
-	updateCmd := exec.Command("brew", "update")
-	updateCmd.Stdin = os.Stdin
-	updateCmd.Stdout = os.Stdout
-	updateCmd.Stderr = os.Stderr
+	var formulae, casks, taps []string
+	for _, packageID := range packageIDs {
+		pkgType, pkgName, err := p.parsePackageID(packageID)
+		if err != nil {
+			return fmt.Errorf("failed to parse package ID: %w", err)
+		}
+		switch pkgType {
+		case "cask":
+			casks = append(casks, pkgName)
+		case "tap":
+			taps = append(taps, pkgName)
+		default:
+			formulae = append(formulae, pkgName)
+		}
+	}
+
+	var commands [][]string
+	if len(formulae) > 0 {
+		commands = append(commands, append([]string{"upgrade"}, formulae...))
+	}
+	if len(casks) > 0 {
+		commands = append(commands, append([]string{"upgrade", "--cask"}, casks...))
+	}
+	for _, tap := range taps {
+		commands = append(commands, []string{"tap", tap})
+	}
+
+	var failed []string
+	for _, args := range commands {
+		fmt.Fprintf(p.stdout, "Running brew %s...\n", strings.Join(args, " "))
+		if err := p.run(ctx, "brew", args...); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("failed to upgrade packages: %w", err)
+			}
+			failed = append(failed, fmt.Sprintf("brew %s: %v", strings.Join(args, " "), err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to upgrade packages: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// Upgrade upgrades brew itself
+func (p *BrewProvider) Upgrade(ctx context.Context) error {
+	// Check if brew is installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check brew installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("brew is not installed. Please install it first using 'al provider add brew'")
+	}
 
-	if err := updateCmd.Run(); err != nil {
+	// Run brew update; it fetches from the network, so it is retried
+	fmt.Fprintln(p.stdout, "Updating brew...")
+	if err := p.runWithRetry(ctx, "brew", "update"); err != nil {
 		return fmt.Errorf("failed to update brew: %w", err)
 	}
 
 	// Update version in config
-	version, err := p.GetVersion()
+	version, err := p.GetVersion(ctx)
 	if err == nil {
-		providerConfig := config.ProviderConfig{
-			Name:        p.name,
-			InstalledAt: time.Now(),
-			Version:     version,
-		}
-		if err := config.AddOrUpdateProvider(providerConfig); err != nil {
-			fmt.Printf("Warning: failed to update provider config: %v\n", err)
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
 		}
 	}
 
-	fmt.Println("Successfully upgraded brew")
+	fmt.Fprintln(p.stdout, "Successfully upgraded brew")
 	return nil
 }
 
 // SearchPackage searches for packages using brew search
-func (p *BrewProvider) SearchPackage(query string) ([]SearchResult, error) {
+func (p *BrewProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
 	// Check if brew is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check brew installation: %w", err)
 	}
@@ -348,37 +437,63 @@ func (p *BrewProvider) SearchPackage(query string) ([]SearchResult, error) {
 		return nil, fmt.Errorf("brew is not installed. Please install it first using 'al provider add brew'")
 	}
 
-	// Run brew search command
-	cmd := exec.Command("brew", "search", query)
-	output, err := cmd.Output()
+	// Search formulae and casks separately so that the type of each hit is known without detection
+	var results []SearchResult
+	for _, pkgType := range []string{"formula", "cask"} {
+		names, err := p.searchNames(ctx, pkgType, query)
+		if err != nil {
+			return nil, err
+		}
+
+		// Fill description, version, homepage, and tap with one batched brew info call
+		infos, err := p.Info(ctx, pkgType, names)
+		if err != nil {
+			return nil, err
+		}
+		index := indexBrewInfo(names, infos)
+
+		for _, name := range names {
+			result := SearchResult{
+				ID:   fmt.Sprintf("%s:%s", pkgType, name),
+				Name: name,
+			}
+			if info, ok := index[name]; ok {
+				result.Description = info.Description
+				result.Version = info.Version
+				result.Homepage = info.Homepage
+				result.Tap = info.Tap
+			}
+			results = append(results, result)
+		}
+	}
+
+	return results, nil
+}
+
+// searchNames runs `brew search --formula|--cask` and returns the names it prints
+func (p *BrewProvider) searchNames(ctx context.Context, pkgType, query string) ([]string, error) {
+	output, err := p.output(ctx, "brew", "search", "--"+pkgType, query)
 	if err != nil {
+		// brew search exits with an error when nothing matches
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			stderr := string(exitErr.Stderr)
+			if strings.Contains(stderr, "No formulae") || strings.Contains(stderr, "No casks") {
+				return nil, nil
+			}
+		}
 		return nil, fmt.Errorf("failed to search packages: %w", err)
 	}
 
 	// Parse output - brew search returns one package name per line
-	lines := strings.Split(strings.TrimSpace(string(output)), "\n")
-	results := make([]SearchResult, 0, len(lines))
-
-	for _, line := range lines {
+	var names []string
+	for _, line := range strings.Split(string(output), "\n") {
 		line = strings.TrimSpace(line)
-		if line == "" {
+		// Skip section headers and informational lines
+		if line == "" || strings.HasPrefix(line, "==>") {
 			continue
 		}
-
-		// Detect package type and generate ID
-		pkgType, err := p.detectPackageType(line)
-		if err != nil {
-			// If detection fails, default to formula
-			pkgType = "formula"
-		}
-
-		pkgID := fmt.Sprintf("%s:%s", pkgType, line)
-		results = append(results, SearchResult{
-			ID:          pkgID,
-			Name:        line,
-			Description: "",
-		})
+		names = append(names, line)
 	}
-
-	return results, nil
+	return names, nil
 }
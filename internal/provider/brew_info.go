@@ -0,0 +1,213 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// BrewPackageInfo is the metadata of a formula or cask from `brew info --json=v2`
+type BrewPackageInfo struct {
+	// Type is "formula" or "cask"
+	Type string
+	// Name is the formula name or cask token as given to brew (e.g. "node", "slack")
+	Name string
+	// FullName includes the tap for formulae and casks outside the default taps (e.g. "user/tap/name")
+	FullName string
+	// Aliases are other formula names brew resolves to this package
+	Aliases     []string
+	Description string
+	Homepage    string
+	// Version is the latest available version
+	Version string
+	Tap     string
+	// InstalledVersion is empty if the package is not installed
+	InstalledVersion string
+	Outdated         bool
+}
+
+// brewInfoV2 is the payload of `brew info --json=v2`
+type brewInfoV2 struct {
+	Formulae []brewFormulaJSON `json:"formulae"`
+	Casks    []brewCaskJSON    `json:"casks"`
+}
+
+type brewFormulaJSON struct {
+	Name     string   `json:"name"`
+	FullName string   `json:"full_name"`
+	Tap      string   `json:"tap"`
+	Desc     string   `json:"desc"`
+	Homepage string   `json:"homepage"`
+	Aliases  []string `json:"aliases"`
+	Versions struct {
+		Stable string `json:"stable"`
+	} `json:"versions"`
+	Installed []struct {
+		Version string `json:"version"`
+	} `json:"installed"`
+	Outdated bool `json:"outdated"`
+}
+
+type brewCaskJSON struct {
+	Token     string   `json:"token"`
+	FullToken string   `json:"full_token"`
+	Tap       string   `json:"tap"`
+	Name      []string `json:"name"`
+	Desc      string   `json:"desc"`
+	Homepage  string   `json:"homepage"`
+	Version   string   `json:"version"`
+	Installed *string  `json:"installed"`
+	Outdated  bool     `json:"outdated"`
+}
+
+// ParseBrewInfoJSON parses the output of `brew info --json=v2` into formulae followed by casks
+func ParseBrewInfoJSON(data []byte) ([]BrewPackageInfo, error) {
+	var payload brewInfoV2
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse brew info JSON: %w", err)
+	}
+
+	infos := make([]BrewPackageInfo, 0, len(payload.Formulae)+len(payload.Casks))
+	for _, f := range payload.Formulae {
+		info := BrewPackageInfo{
+			Type:        "formula",
+			Name:        f.Name,
+			FullName:    f.FullName,
+			Aliases:     f.Aliases,
+			Description: f.Desc,
+			Homepage:    f.Homepage,
+			Version:     f.Versions.Stable,
+			Tap:         f.Tap,
+			Outdated:    f.Outdated,
+		}
+		if len(f.Installed) > 0 {
+			info.InstalledVersion = f.Installed[len(f.Installed)-1].Version
+		}
+		infos = append(infos, info)
+	}
+	for _, c := range payload.Casks {
+		info := BrewPackageInfo{
+			Type:        "cask",
+			Name:        c.Token,
+			FullName:    c.FullToken,
+			Description: c.Desc,
+			Homepage:    c.Homepage,
+			Version:     c.Version,
+			Tap:         c.Tap,
+			Outdated:    c.Outdated,
+		}
+		// Casks without a description fall back to their display name
+		if info.Description == "" && len(c.Name) > 0 {
+			info.Description = c.Name[0]
+		}
+		if c.Installed != nil {
+			info.InstalledVersion = *c.Installed
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Info returns metadata for the given formulae or casks with a single `brew info --json=v2` call.
+// pkgType is "formula" or "cask". Names that brew does not know are omitted from the result:
+// if the batched call fails because of them, the names are queried one by one.
+func (p *BrewProvider) Info(ctx context.Context, pkgType string, names []string) ([]BrewPackageInfo, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+	if pkgType != "formula" && pkgType != "cask" {
+		return nil, fmt.Errorf("invalid package type: %s (must be formula or cask)", pkgType)
+	}
+
+	infos, err := p.runInfo(ctx, pkgType, names)
+	if err == nil {
+		return infos, nil
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	if !isBrewNotFound(err) {
+		return nil, err
+	}
+	if len(names) == 1 {
+		// An unknown name is not an error; it is simply not found
+		return nil, nil
+	}
+
+	// The batch failed because at least one of the names does not exist
+	infos = nil
+	for _, name := range names {
+		single, err := p.runInfo(ctx, pkgType, []string{name})
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !isBrewNotFound(err) {
+				return nil, err
+			}
+			continue
+		}
+		infos = append(infos, single...)
+	}
+	return infos, nil
+}
+
+// isBrewNotFound reports whether a failed brew command failed because a formula or cask does not exist
+func isBrewNotFound(err error) bool {
+	var exitErr *exec.ExitError
+	if !errors.As(err, &exitErr) {
+		return false
+	}
+	stderr := strings.ToLower(string(exitErr.Stderr))
+	for _, message := range []string{"no available formula", "no available cask", "no cask with this name", "no formulae or casks found"} {
+		if strings.Contains(stderr, message) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *BrewProvider) runInfo(ctx context.Context, pkgType string, names []string) ([]BrewPackageInfo, error) {
+	args := append([]string{"info", "--json=v2", "--" + pkgType}, names...)
+	output, err := p.output(ctx, "brew", args...)
+	if err != nil {
+		return nil, fmt.Errorf("brew info failed for %s: %w", strings.Join(names, ", "), err)
+	}
+	return ParseBrewInfoJSON(output)
+}
+
+// indexBrewInfo maps every name a package was requested by to its metadata.
+// brew resolves aliases and tap-qualified names, so the requested name is matched against
+// the name, the full name, aliases, and (when it contains a tap) the last path segment. brew
+// reports packages of the default taps without their tap, so the last segment only matches a
+// package of homebrew/core, homebrew/cask, or the requested tap.
+func indexBrewInfo(names []string, infos []BrewPackageInfo) map[string]BrewPackageInfo {
+	byName := make(map[string]BrewPackageInfo, len(infos)*2)
+	for _, info := range infos {
+		byName[info.Name] = info
+		if info.FullName != "" {
+			byName[info.FullName] = info
+		}
+		for _, alias := range info.Aliases {
+			byName[alias] = info
+		}
+	}
+
+	index := make(map[string]BrewPackageInfo, len(names))
+	for _, name := range names {
+		if info, ok := byName[name]; ok {
+			index[name] = info
+			continue
+		}
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			info, ok := byName[name[i+1:]]
+			if ok && (info.Tap == "homebrew/core" || info.Tap == "homebrew/cask" || info.Tap == name[:i]) {
+				index[name] = info
+			}
+		}
+	}
+	return index
+}
@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func loadBrewInfoFixture(t *testing.T) []BrewPackageInfo {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", "brew-info-v2.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	infos, err := ParseBrewInfoJSON(data)
+	if err != nil {
+		t.Fatalf("ParseBrewInfoJSON: %v", err)
+	}
+	return infos
+}
+
+func TestParseBrewInfoJSON(t *testing.T) {
+	infos := loadBrewInfoFixture(t)
+
+	want := []BrewPackageInfo{
+		{
+			Type:             "formula",
+			Name:             "node",
+			FullName:         "node",
+			Description:      "Platform built on V8 to build network applications",
+			Homepage:         "https://nodejs.org/",
+			Version:          "22.9.0",
+			Tap:              "homebrew/core",
+			InstalledVersion: "22.9.0",
+		},
+		{
+			Type:        "formula",
+			Name:        "jq",
+			FullName:    "jq",
+			Description: "Lightweight and flexible command-line JSON processor",
+			Homepage:    "https://jqlang.github.io/jq/",
+			Version:     "1.7.1",
+			Tap:         "homebrew/core",
+		},
+		{
+			Type:             "formula",
+			Name:             "mytool",
+			FullName:         "kkato1030/tap/mytool",
+			Description:      "A tool from a third-party tap",
+			Homepage:         "https://github.com/kkato1030/mytool",
+			Version:          "0.3.0",
+			Tap:              "kkato1030/tap",
+			InstalledVersion: "0.2.0",
+			Outdated:         true,
+		},
+		{
+			Type:             "cask",
+			Name:             "slack",
+			FullName:         "slack",
+			Description:      "Team communication and collaboration software",
+			Homepage:         "https://slack.com/",
+			Version:          "4.40.128",
+			Tap:              "homebrew/cask",
+			InstalledVersion: "4.39.95",
+			Outdated:         true,
+		},
+		{
+			// A cask without a description falls back to its display name
+			Type:        "cask",
+			Name:        "someapp",
+			FullName:    "kkato1030/tap/someapp",
+			Description: "Some App",
+			Homepage:    "https://example.com/someapp",
+			Version:     "1.0.0",
+			Tap:         "kkato1030/tap",
+		},
+	}
+
+	if len(infos) != len(want) {
+		t.Fatalf("got %d packages, want %d", len(infos), len(want))
+	}
+	for i, w := range want {
+		got := infos[i]
+		if got.Type != w.Type || got.Name != w.Name || got.FullName != w.FullName {
+			t.Errorf("package %d: got %s %s (%s), want %s %s (%s)", i, got.Type, got.Name, got.FullName, w.Type, w.Name, w.FullName)
+		}
+		if got.Description != w.Description {
+			t.Errorf("%s: Description = %q, want %q", w.Name, got.Description, w.Description)
+		}
+		if got.Version != w.Version {
+			t.Errorf("%s: Version = %q, want %q", w.Name, got.Version, w.Version)
+		}
+		if got.Homepage != w.Homepage {
+			t.Errorf("%s: Homepage = %q, want %q", w.Name, got.Homepage, w.Homepage)
+		}
+		if got.Tap != w.Tap {
+			t.Errorf("%s: Tap = %q, want %q", w.Name, got.Tap, w.Tap)
+		}
+		if got.InstalledVersion != w.InstalledVersion {
+			t.Errorf("%s: InstalledVersion = %q, want %q", w.Name, got.InstalledVersion, w.InstalledVersion)
+		}
+		if got.Outdated != w.Outdated {
+			t.Errorf("%s: Outdated = %v, want %v", w.Name, got.Outdated, w.Outdated)
+		}
+	}
+}
+
+func TestParseBrewInfoJSONInvalid(t *testing.T) {
+	if _, err := ParseBrewInfoJSON([]byte("not json")); err == nil {
+		t.Error("expected an error for invalid JSON")
+	}
+}
+
+func TestIndexBrewInfo(t *testing.T) {
+	infos := loadBrewInfoFixture(t)
+
+	names := []string{"node", "nodejs", "node@22", "kkato1030/tap/mytool", "mytool", "homebrew/cask/slack", "kkato1030/tap/someapp", "unknown", "other/tap/unknown", "other/tap/mytool"}
+	index := indexBrewInfo(names, infos)
+
+	tests := []struct {
+		requested string
+		want      string
+	}{
+		{"node", "node"},
+		{"nodejs", "node"},  // alias
+		{"node@22", "node"}, // alias
+		{"kkato1030/tap/mytool", "mytool"},
+		{"mytool", "mytool"},
+		{"homebrew/cask/slack", "slack"}, // tap-qualified name of a package brew reports without its tap
+		{"kkato1030/tap/someapp", "someapp"},
+	}
+	for _, tt := range tests {
+		info, ok := index[tt.requested]
+		if !ok {
+			t.Errorf("%s: not found", tt.requested)
+			continue
+		}
+		if info.Name != tt.want {
+			t.Errorf("%s: got %s, want %s", tt.requested, info.Name, tt.want)
+		}
+	}
+
+	// mytool of kkato1030/tap is not another tap's mytool
+	for _, name := range []string{"unknown", "other/tap/unknown", "other/tap/mytool"} {
+		if _, ok := index[name]; ok {
+			t.Errorf("%s: unexpectedly found", name)
+		}
+	}
+	if len(index) != len(tests) {
+		t.Errorf("index has %d entries, want %d", len(index), len(tests))
+	}
+}
@@ -0,0 +1,138 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// brewOutdatedV2 is the payload of `brew outdated --json=v2`
+type brewOutdatedV2 struct {
+	Formulae []brewOutdatedJSON `json:"formulae"`
+	Casks    []brewOutdatedJSON `json:"casks"`
+}
+
+type brewOutdatedJSON struct {
+	Name              string   `json:"name"`
+	InstalledVersions []string `json:"installed_versions"`
+	CurrentVersion    string   `json:"current_version"`
+}
+
+// NormalizeID returns the canonical form of a brew package ID: IDs without a type are formulae,
+// and formulae and casks are identified by their short name (brew list prints names without the tap)
+func (p *BrewProvider) NormalizeID(packageID string) string {
+	pkgType, pkgName, err := p.parsePackageID(packageID)
+	if err != nil {
+		return packageID
+	}
+	if pkgType != "tap" {
+		if i := strings.LastIndex(pkgName, "/"); i >= 0 {
+			pkgName = pkgName[i+1:]
+		}
+	}
+	return fmt.Sprintf("%s:%s", pkgType, pkgName)
+}
+
+// ListInstalled lists installed formulae and casks (`brew list --versions`) and taps (`brew tap`)
+func (p *BrewProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	var packages []InstalledPackage
+	for _, pkgType := range []string{"formula", "cask"} {
+		output, err := p.output(ctx, "brew", "list", "--versions", "--"+pkgType)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed %s packages: %w", pkgType, err)
+		}
+		packages = append(packages, parseBrewListVersions(pkgType, string(output))...)
+	}
+
+	output, err := p.output(ctx, "brew", "tap")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list taps: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if tap := strings.TrimSpace(line); tap != "" {
+			packages = append(packages, InstalledPackage{ID: "tap:" + tap, Name: tap})
+		}
+	}
+
+	return packages, nil
+}
+
+// parseBrewListVersions parses `brew list --versions` output: "<name> <version> [<version>...]" per line.
+// When several versions are installed, the last one is used.
+func parseBrewListVersions(pkgType, output string) []InstalledPackage {
+	var packages []InstalledPackage
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pkg := InstalledPackage{ID: fmt.Sprintf("%s:%s", pkgType, fields[0]), Name: fields[0]}
+		if len(fields) > 1 {
+			pkg.Version = fields[len(fields)-1]
+		}
+		packages = append(packages, pkg)
+	}
+	return packages
+}
+
+// ListOutdated lists outdated formulae and casks with `brew outdated --json=v2`
+func (p *BrewProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	output, err := p.output(ctx, "brew", "outdated", "--json=v2")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdated packages: %w", err)
+	}
+	return parseBrewOutdatedJSON(output)
+}
+
+// parseBrewOutdatedJSON parses the output of `brew outdated --json=v2`
+func parseBrewOutdatedJSON(data []byte) ([]OutdatedPackage, error) {
+	var payload brewOutdatedV2
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("failed to parse brew outdated JSON: %w", err)
+	}
+
+	packages := make([]OutdatedPackage, 0, len(payload.Formulae)+len(payload.Casks))
+	add := func(pkgType string, items []brewOutdatedJSON) {
+		for _, item := range items {
+			pkg := OutdatedPackage{
+				ID:            fmt.Sprintf("%s:%s", pkgType, item.Name),
+				Name:          item.Name,
+				LatestVersion: item.CurrentVersion,
+			}
+			if len(item.InstalledVersions) > 0 {
+				pkg.CurrentVersion = item.InstalledVersions[len(item.InstalledVersions)-1]
+			}
+			packages = append(packages, pkg)
+		}
+	}
+	add("formula", payload.Formulae)
+	add("cask", payload.Casks)
+	return packages, nil
+}
+
+// Pin pins a formula with `brew pin` so that `brew upgrade` skips it.
+// Casks and taps cannot be pinned; they are held by al only.
+func (p *BrewProvider) Pin(ctx context.Context, packageID string) error {
+	return p.runPin(ctx, "pin", packageID)
+}
+
+// Unpin unpins a formula with `brew unpin`
+func (p *BrewProvider) Unpin(ctx context.Context, packageID string) error {
+	return p.runPin(ctx, "unpin", packageID)
+}
+
+func (p *BrewProvider) runPin(ctx context.Context, command, packageID string) error {
+	pkgType, pkgName, err := p.parsePackageID(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to parse package ID: %w", err)
+	}
+	if pkgType != "formula" {
+		return nil
+	}
+
+	if err := p.run(ctx, "brew", command, pkgName); err != nil {
+		return fmt.Errorf("failed to %s %s: %w", command, pkgName, err)
+	}
+	return nil
+}
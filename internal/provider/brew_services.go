@@ -0,0 +1,80 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// BrewService is a formula's service as listed by `brew services list --json`
+type BrewService struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // started, stopped, none, error, scheduled, ...
+	User   string `json:"user,omitempty"`
+	File   string `json:"file,omitempty"`
+}
+
+// Running reports whether the service is started
+func (s BrewService) Running() bool {
+	return s.Status == "started"
+}
+
+// BrewServiceName returns the name brew services lists a formula's service under: the formula
+// name without its tap ("tool" for "formula:user/repo/tool")
+func BrewServiceName(packageID string) string {
+	name := strings.TrimPrefix(packageID, "formula:")
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// ListServices lists the services of installed formulae, keyed by BrewServiceName
+func (p *BrewProvider) ListServices(ctx context.Context) (map[string]BrewService, error) {
+	output, err := p.output(ctx, "brew", "services", "list", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services: %w", err)
+	}
+	var services []BrewService
+	if len(strings.TrimSpace(string(output))) > 0 {
+		if err := json.Unmarshal(output, &services); err != nil {
+			return nil, fmt.Errorf("failed to parse brew services output: %w", err)
+		}
+	}
+	result := make(map[string]BrewService, len(services))
+	for _, service := range services {
+		result[service.Name] = service
+	}
+	return result, nil
+}
+
+// StartService starts a formula's service with `brew services start`
+func (p *BrewProvider) StartService(ctx context.Context, packageID string) error {
+	return p.runService(ctx, "start", packageID)
+}
+
+// StopService stops a formula's service with `brew services stop`
+func (p *BrewProvider) StopService(ctx context.Context, packageID string) error {
+	return p.runService(ctx, "stop", packageID)
+}
+
+// RestartService restarts (or starts) a formula's service with `brew services restart`
+func (p *BrewProvider) RestartService(ctx context.Context, packageID string) error {
+	return p.runService(ctx, "restart", packageID)
+}
+
+func (p *BrewProvider) runService(ctx context.Context, command, packageID string) error {
+	pkgType, pkgName, err := p.parsePackageID(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to parse package ID: %w", err)
+	}
+	if pkgType != "formula" {
+		return fmt.Errorf("%s is a %s; only formulae have services", pkgName, pkgType)
+	}
+
+	if err := p.run(ctx, "brew", "services", command, pkgName); err != nil {
+		return fmt.Errorf("failed to %s service %s: %w", command, pkgName, err)
+	}
+	return nil
+}
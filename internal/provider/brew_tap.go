@@ -0,0 +1,83 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// BrewTapDependency returns the tap a tap-qualified formula or cask comes from: "user/repo" for
+// "formula:user/repo/tool". It returns "" for packages of the default taps and for taps themselves.
+func BrewTapDependency(packageID string) string {
+	pkgType, pkgName, found := strings.Cut(packageID, ":")
+	if !found {
+		pkgType, pkgName = "formula", packageID
+	}
+	if pkgType != "formula" && pkgType != "cask" {
+		return ""
+	}
+	parts := strings.Split(pkgName, "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[0] + "/" + parts[1]
+}
+
+// SameBrewTap reports whether two tap names refer to the same tap. brew accepts "user/repo" for the
+// repository user/homebrew-repo and compares names case-insensitively.
+func SameBrewTap(a, b string) bool {
+	return normalizeTapName(a) == normalizeTapName(b)
+}
+
+// ValidateBrewTapName returns an error unless name has the form "user/repo"
+func ValidateBrewTapName(name string) error {
+	parts := strings.Split(name, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return fmt.Errorf("invalid tap name '%s' (must be user/repo)", name)
+	}
+	return nil
+}
+
+func normalizeTapName(name string) string {
+	user, repo, _ := strings.Cut(strings.ToLower(name), "/")
+	return user + "/" + strings.TrimPrefix(repo, "homebrew-")
+}
+
+// Tapped reports whether the tap is tapped
+func (p *BrewProvider) Tapped(ctx context.Context, name string) (bool, error) {
+	output, err := p.output(ctx, "brew", "tap")
+	if err != nil {
+		return false, fmt.Errorf("failed to list taps: %w", err)
+	}
+	for _, line := range strings.Split(string(output), "\n") {
+		if tap := strings.TrimSpace(line); tap != "" && SameBrewTap(tap, name) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// InstallTap taps name, cloning it from url when url is not empty (a tap with a custom remote)
+func (p *BrewProvider) InstallTap(ctx context.Context, name, url string) error {
+	args := []string{"tap", name}
+	if url != "" {
+		args = append(args, url)
+	}
+	if err := p.runWithRetry(ctx, "brew", args...); err != nil {
+		return fmt.Errorf("failed to tap %s: %w", name, err)
+	}
+	return nil
+}
+
+// EnsureTap taps name unless it is already tapped
+func (p *BrewProvider) EnsureTap(ctx context.Context, name, url string) error {
+	tapped, err := p.Tapped(ctx, name)
+	if err != nil {
+		return err
+	}
+	if tapped {
+		return nil
+	}
+	fmt.Fprintf(p.stdout, "Tapping %s...\n", name)
+	return p.InstallTap(ctx, name, url)
+}
@@ -0,0 +1,407 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/version"
+)
+
+// CargoProvider implements the Provider interface for Rust crates installed with `cargo install`
+type CargoProvider struct {
+	name string
+	runner
+}
+
+// NewCargoProvider creates a new cargo provider
+func NewCargoProvider() *CargoProvider {
+	return &CargoProvider{name: "cargo", runner: newRunner("cargo")}
+}
+
+// Name returns the provider name
+func (p *CargoProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the crate name as the ID and the name; --git, --locked, and features are options
+func (p *CargoProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, id, nil
+}
+
+// CheckInstalled checks if cargo is installed by running `cargo --version`
+func (p *CargoProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "cargo", "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, cargo is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of cargo
+func (p *CargoProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "cargo", "--version")
+	if err != nil {
+		return "", err
+	}
+
+	// cargo --version prints "cargo 1.80.0 (376290515 2024-07-16)"
+	fields := strings.Fields(string(output))
+	if len(fields) >= 2 {
+		return fields[1], nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Install installs cargo (with the Rust toolchain) using Homebrew
+func (p *CargoProvider) Install(ctx context.Context) error {
+	// Check if cargo is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check cargo installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("cargo is already installed")
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install cargo. Please install brew first using 'al provider add brew'")
+	}
+
+	// Install rust (which includes cargo) using brew
+	fmt.Fprintln(p.stdout, "Installing rust using brew...")
+	if err := p.runWithRetry(ctx, "brew", "install", "rust"); err != nil {
+		return fmt.Errorf("failed to install cargo: %w", err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for cargo provider
+func (p *CargoProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if cargo is not installed
+func (p *CargoProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check cargo installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("cargo is not installed. Please install it first using 'al provider add cargo'")
+	}
+	return nil
+}
+
+// InstallPackage installs a crate with `cargo install`
+func (p *CargoProvider) InstallPackage(ctx context.Context, packageID string) error {
+	return p.InstallPackageWithOptions(ctx, packageID, nil)
+}
+
+// InstallPackageWithOptions installs a crate with `cargo install`, passing options such as
+// "--locked", "--features=a,b", or "--git=https://..."
+func (p *CargoProvider) InstallPackageWithOptions(ctx context.Context, packageID string, options []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	// Run cargo install
+	fmt.Fprintf(p.stdout, "Installing %s using cargo...\n", packageID)
+	args := append(append([]string{"install"}, options...), packageID)
+	if err := p.runWithRetry(ctx, "cargo", args...); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls a crate with `cargo uninstall`
+func (p *CargoProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.stdout, "Uninstalling %s using cargo...\n", packageID)
+	if err := p.run(ctx, "cargo", "uninstall", packageID); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage upgrades a crate by reinstalling it when a newer version exists
+func (p *CargoProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	return p.UpgradePackageWithOptions(ctx, packageID, nil)
+}
+
+// UpgradePackageWithOptions upgrades a crate by reinstalling it with its options when crates.io has
+// a newer version. Crates installed from git are always reinstalled; cargo skips them when the
+// commit has not changed.
+func (p *CargoProvider) UpgradePackageWithOptions(ctx context.Context, packageID string, options []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	if !cargoFromGit(options) {
+		crates, err := readCrates2()
+		if err != nil {
+			return fmt.Errorf("failed to read installed crates: %w", err)
+		}
+		if crate, ok := crates[packageID]; ok && crate.registry {
+			latest, err := p.latestVersion(ctx, packageID)
+			if err != nil {
+				return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+			}
+			if latest != "" && version.Compare(latest, crate.version) <= 0 {
+				fmt.Fprintf(p.stdout, "%s %s is up to date\n", packageID, crate.version)
+				return nil
+			}
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using cargo...\n", packageID)
+	args := append(append([]string{"install"}, options...), packageID)
+	if err := p.runWithRetry(ctx, "cargo", args...); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", packageID)
+	return nil
+}
+
+// cargoFromGit reports whether options install the crate from a git repository
+func cargoFromGit(options []string) bool {
+	for _, option := range options {
+		if option == "--git" || strings.HasPrefix(option, "--git=") {
+			return true
+		}
+	}
+	return false
+}
+
+// Upgrade upgrades the Rust toolchain with rustup, or with brew when it was installed with brew
+func (p *CargoProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	if err := p.check(ctx, "rustup", "--version"); err == nil {
+		fmt.Fprintln(p.stdout, "Upgrading rust using rustup...")
+		if err := p.runWithRetry(ctx, "rustup", "update"); err != nil {
+			return fmt.Errorf("failed to upgrade cargo: %w", err)
+		}
+	} else {
+		// Check if brew is installed
+		if err := p.check(ctx, "brew", "--version"); err != nil {
+			return fmt.Errorf("rustup or brew is required to upgrade cargo")
+		}
+		fmt.Fprintln(p.stdout, "Upgrading rust using brew...")
+		if err := p.runWithRetry(ctx, "brew", "upgrade", "rust"); err != nil {
+			return fmt.Errorf("failed to upgrade cargo: %w", err)
+		}
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully upgraded cargo")
+	return nil
+}
+
+// cargoSearchRegex matches a line of `cargo search`: ripgrep = "14.1.0"    # description
+var cargoSearchRegex = regexp.MustCompile(`^(\S+)\s*=\s*"([^"]+)"\s*(?:#\s*(.*))?$`)
+
+// search runs `cargo search` and returns its results
+func (p *CargoProvider) search(ctx context.Context, query string, limit int) ([]SearchResult, error) {
+	output, err := p.output(ctx, "cargo", "search", query, "--limit", fmt.Sprint(limit))
+	if err != nil {
+		return nil, err
+	}
+
+	var results []SearchResult
+	for _, line := range strings.Split(string(output), "\n") {
+		m := cargoSearchRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+		results = append(results, SearchResult{
+			ID:          m[1],
+			Name:        m[1],
+			Description: strings.TrimSpace(m[3]),
+			Version:     m[2],
+			Homepage:    "https://crates.io/crates/" + m[1],
+		})
+	}
+	return results, nil
+}
+
+// latestVersion returns the latest version of a crate on crates.io, or "" if it is not found
+func (p *CargoProvider) latestVersion(ctx context.Context, crate string) (string, error) {
+	results, err := p.search(ctx, crate, 10)
+	if err != nil {
+		return "", err
+	}
+	for _, r := range results {
+		if r.ID == crate {
+			return r.Version, nil
+		}
+	}
+	return "", nil
+}
+
+// SearchPackage searches crates.io with `cargo search`
+func (p *CargoProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	results, err := p.search(ctx, query, 20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search packages: %w", err)
+	}
+	return results, nil
+}
+
+// cargoCrate is an installed crate recorded in .crates2.json
+type cargoCrate struct {
+	version  string
+	registry bool // installed from crates.io (not from git or a path)
+}
+
+// cargoHome returns $CARGO_HOME, or ~/.cargo
+func cargoHome() (string, error) {
+	if home := os.Getenv("CARGO_HOME"); home != "" {
+		return home, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, ".cargo"), nil
+}
+
+// readCrates2 reads the crates installed with cargo install from $CARGO_HOME/.crates2.json, keyed
+// by crate name. Its keys look like "ripgrep 14.1.0 (registry+https://github.com/rust-lang/crates.io-index)".
+func readCrates2() (map[string]cargoCrate, error) {
+	home, err := cargoHome()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(home, ".crates2.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			// Nothing has been installed yet
+			return map[string]cargoCrate{}, nil
+		}
+		return nil, err
+	}
+
+	var file struct {
+		Installs map[string]json.RawMessage `json:"installs"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse .crates2.json: %w", err)
+	}
+
+	crates := make(map[string]cargoCrate, len(file.Installs))
+	for key := range file.Installs {
+		fields := strings.Fields(key)
+		if len(fields) < 2 {
+			continue
+		}
+		source := ""
+		if len(fields) >= 3 {
+			source = strings.Trim(fields[2], "()")
+		}
+		crates[fields[0]] = cargoCrate{version: fields[1], registry: strings.HasPrefix(source, "registry+") || strings.HasPrefix(source, "sparse+")}
+	}
+	return crates, nil
+}
+
+// ListInstalled lists the crates installed with cargo install (from .crates2.json)
+func (p *CargoProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	crates, err := readCrates2()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed crates: %w", err)
+	}
+
+	packages := make([]InstalledPackage, 0, len(crates))
+	for name, crate := range crates {
+		packages = append(packages, InstalledPackage{ID: name, Name: name, Version: crate.version})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated lists the crates from crates.io with a newer version, asking `cargo search` for each.
+// Crates installed from git or a path are left out.
+func (p *CargoProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	crates, err := readCrates2()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed crates: %w", err)
+	}
+	names := make([]string, 0, len(crates))
+	for name, crate := range crates {
+		if crate.registry {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var packages []OutdatedPackage
+	for _, name := range names {
+		latest, err := p.latestVersion(ctx, name)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			return nil, fmt.Errorf("failed to list outdated crates: %w", err)
+		}
+		current := crates[name].version
+		if latest != "" && version.Compare(latest, current) > 0 {
+			packages = append(packages, OutdatedPackage{ID: name, Name: name, CurrentVersion: current, LatestVersion: latest})
+		}
+	}
+	return packages, nil
+}
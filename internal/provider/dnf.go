@@ -0,0 +1,337 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/version"
+)
+
+// DnfProvider implements the Provider interface for Fedora and RHEL packages installed with dnf.
+// Commands that change the system run with sudo unless al runs as root.
+type DnfProvider struct {
+	name string
+	runner
+}
+
+// NewDnfProvider creates a new dnf provider
+func NewDnfProvider() *DnfProvider {
+	return &DnfProvider{name: "dnf", runner: newRunner("dnf")}
+}
+
+// Name returns the provider name
+func (p *DnfProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the package name as the ID and the name
+func (p *DnfProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, id, nil
+}
+
+// Platforms returns the operating systems dnf runs on
+func (p *DnfProvider) Platforms() []string {
+	return []string{"linux"}
+}
+
+// RequiresRoot reports that dnf runs through sudo
+func (p *DnfProvider) RequiresRoot() bool {
+	return true
+}
+
+// CheckInstalled checks if dnf is installed by running `dnf --version`
+func (p *DnfProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "dnf", "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, dnf is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of dnf
+func (p *DnfProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "dnf", "--version")
+	if err != nil {
+		return "", err
+	}
+	// dnf 4 prints the version on the first line, dnf 5 prints "dnf5 version 5.1.0"
+	line, _, _ := strings.Cut(string(output), "\n")
+	return versionField(line), nil
+}
+
+// Install returns an error: dnf comes with the operating system
+func (p *DnfProvider) Install(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check dnf installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("dnf is already installed")
+	}
+	return fmt.Errorf("dnf is not available on this system (it comes with Fedora and RHEL)")
+}
+
+// SetupConfig sets up the configuration for dnf provider
+func (p *DnfProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if dnf is not installed
+func (p *DnfProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check dnf installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("dnf is not installed. Please install it first using 'al provider add dnf'")
+	}
+	return nil
+}
+
+// dnfCmd returns the command that runs dnf as root without prompts. Callers retry commands that
+// download and run the others once.
+func dnfCmd(args ...string) (string, []string) {
+	return sudo("dnf", append([]string{"-y"}, args...)...)
+}
+
+// InstallPackage installs a package with `dnf install`
+func (p *DnfProvider) InstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.stdout, "Installing %s using dnf...\n", packageID)
+	name, args := dnfCmd("install", packageID)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls a package with `dnf remove`
+func (p *DnfProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.stdout, "Uninstalling %s using dnf...\n", packageID)
+	name, args := dnfCmd("remove", packageID)
+	if err := p.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage upgrades a package with `dnf upgrade`
+func (p *DnfProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	return p.UpgradePackages(ctx, []string{packageID})
+}
+
+// UpgradePackages upgrades several packages with one `dnf upgrade`
+func (p *DnfProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	names := strings.Join(packageIDs, ", ")
+	fmt.Fprintf(p.stdout, "Upgrading %s using dnf...\n", names)
+	name, args := dnfCmd(append([]string{"upgrade"}, packageIDs...)...)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to upgrade packages %s: %w", names, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", names)
+	return nil
+}
+
+// Upgrade refreshes the repository metadata with `dnf makecache`
+func (p *DnfProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.stdout, "Refreshing dnf metadata...")
+	name, args := dnfCmd("makecache")
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to refresh dnf metadata: %w", err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully refreshed dnf metadata")
+	return nil
+}
+
+// dnfPackageName removes the architecture of a "<name>.<arch>" package
+func dnfPackageName(nameArch string) string {
+	if i := strings.LastIndex(nameArch, "."); i > 0 {
+		return nameArch[:i]
+	}
+	return nameArch
+}
+
+// SearchPackage searches packages with `dnf search`
+func (p *DnfProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	output, err := p.output(ctx, "dnf", "search", "-q", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search packages: %w", err)
+	}
+
+	// dnf 4 prints "<name>.<arch> : <summary>", dnf 5 " <name>.<arch>\t<summary>" under "Matched fields" headings
+	results := []SearchResult{}
+	seen := make(map[string]bool)
+	for _, line := range strings.Split(string(output), "\n") {
+		nameArch, summary, found := strings.Cut(line, " : ")
+		if !found {
+			nameArch, summary, found = strings.Cut(line, "\t")
+		}
+		nameArch = strings.TrimSpace(nameArch)
+		if !found || nameArch == "" || strings.Contains(nameArch, " ") {
+			continue
+		}
+		name := dnfPackageName(nameArch)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		results = append(results, SearchResult{ID: name, Name: name, Description: strings.TrimSpace(summary)})
+	}
+	return results, nil
+}
+
+// queryVersions runs a query that prints "<name>\t<version>" lines and returns the highest
+// version of each package
+func (p *DnfProvider) queryVersions(ctx context.Context, name string, args ...string) (map[string]string, error) {
+	output, err := p.output(ctx, name, args...)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]string)
+	for _, line := range strings.Split(string(output), "\n") {
+		pkg, v, found := strings.Cut(strings.TrimSpace(line), "\t")
+		if !found || pkg == "" {
+			continue
+		}
+		if current, ok := versions[pkg]; !ok || version.Compare(v, current) > 0 {
+			versions[pkg] = v
+		}
+	}
+	return versions, nil
+}
+
+// installedVersions returns the versions of the packages installed by the user (`dnf repoquery
+// --userinstalled`) and the registered ones. Packages installed as dependencies are left out.
+func (p *DnfProvider) installedVersions(ctx context.Context) (map[string]string, error) {
+	wanted, err := registeredIDs(p.name)
+	if err != nil {
+		return nil, err
+	}
+	userInstalled, err := p.output(ctx, "dnf", "repoquery", "-q", "--userinstalled", "--queryformat", "%{name}\n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list user-installed packages: %w", err)
+	}
+	for _, name := range strings.Fields(string(userInstalled)) {
+		wanted[name] = true
+	}
+
+	all, err := p.queryVersions(ctx, "rpm", "-qa", "--queryformat", "%{NAME}\t%{VERSION}-%{RELEASE}\n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+	versions := make(map[string]string)
+	for name, v := range all {
+		if wanted[name] {
+			versions[name] = v
+		}
+	}
+	return versions, nil
+}
+
+// ListInstalled lists the packages installed by the user and the registered ones with their versions
+func (p *DnfProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+	versions, err := p.installedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []InstalledPackage
+	for name, v := range versions {
+		packages = append(packages, InstalledPackage{ID: name, Name: name, Version: v})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated lists the installed packages with upgrades in the repositories (`dnf repoquery --upgrades`)
+func (p *DnfProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+	installed, err := p.installedVersions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	upgrades, err := p.queryVersions(ctx, "dnf", "repoquery", "-q", "--upgrades", "--queryformat", "%{name}\t%{version}-%{release}\n")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list upgrades: %w", err)
+	}
+
+	var packages []OutdatedPackage
+	for name, latest := range upgrades {
+		current, ok := installed[name]
+		if !ok {
+			continue
+		}
+		packages = append(packages, OutdatedPackage{ID: name, Name: name, CurrentVersion: current, LatestVersion: latest})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
@@ -0,0 +1,136 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// Default limits of provider commands. A provider's entry in providers.json can override them
+// with "timeout" (e.g. "1h") and "retries".
+const (
+	DefaultTimeout = 30 * time.Minute
+	DefaultRetries = 2
+)
+
+// retryBackoff is the wait before the first retry; it doubles for every further retry
+var retryBackoff = 2 * time.Second
+
+// interruptGrace is how long a canceled command gets to clean up after SIGINT before it is killed
+const interruptGrace = 10 * time.Second
+
+// runner runs the commands of a provider: where their output goes, how long one command may run,
+// and how many times network operations are retried
+type runner struct {
+	stdout  io.Writer
+	stderr  io.Writer
+	timeout time.Duration
+	retries int
+}
+
+// newRunner returns a runner writing to os.Stdout and os.Stderr with the limits configured for
+// the provider in providers.json, or the defaults
+func newRunner(providerName string) runner {
+	r := runner{stdout: os.Stdout, stderr: os.Stderr, timeout: DefaultTimeout, retries: DefaultRetries}
+	providerConfig, err := config.GetProvider(providerName)
+	if err != nil || providerConfig == nil {
+		return r
+	}
+	if timeout, err := providerConfig.TimeoutDuration(); err == nil && timeout > 0 {
+		r.timeout = timeout
+	}
+	if providerConfig.Retries != nil && *providerConfig.Retries >= 0 {
+		r.retries = *providerConfig.Retries
+	}
+	return r
+}
+
+// SetOutput redirects command output and messages
+func (r *runner) SetOutput(stdout, stderr io.Writer) {
+	r.stdout = stdout
+	r.stderr = stderr
+}
+
+// execute runs a command bound to ctx and the runner's time limit; do sets it up, starts it, and
+// waits for it. When ctx is canceled or the time limit is reached, the command receives SIGINT so
+// that the package manager can clean up, and is killed if it has not exited after a grace period.
+// The error then wraps context.Canceled or context.DeadlineExceeded.
+func (r *runner) execute(ctx context.Context, do func(cmd *exec.Cmd) error, name string, args ...string) error {
+	cmdCtx, cancel := ctx, context.CancelFunc(func() {})
+	if r.timeout > 0 {
+		cmdCtx, cancel = context.WithTimeout(ctx, r.timeout)
+	}
+	defer cancel()
+
+	cmd := exec.CommandContext(cmdCtx, name, args...)
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+	cmd.WaitDelay = interruptGrace
+
+	err := do(cmd)
+	switch {
+	case err == nil:
+		return nil
+	case ctx.Err() != nil:
+		return fmt.Errorf("interrupted: %w", ctx.Err())
+	case errors.Is(cmdCtx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("timed out after %s: %w", r.timeout, context.DeadlineExceeded)
+	}
+	return err
+}
+
+// run runs a command attached to the terminal's stdin and the runner's output
+func (r *runner) run(ctx context.Context, name string, args ...string) error {
+	return r.execute(ctx, func(cmd *exec.Cmd) error {
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = r.stdout
+		cmd.Stderr = r.stderr
+		return cmd.Run()
+	}, name, args...)
+}
+
+// output runs a command and returns its standard output
+func (r *runner) output(ctx context.Context, name string, args ...string) ([]byte, error) {
+	var out []byte
+	err := r.execute(ctx, func(cmd *exec.Cmd) error {
+		var err error
+		out, err = cmd.Output()
+		return err
+	}, name, args...)
+	return out, err
+}
+
+// check runs a command without output, e.g. `brew --version` to see if brew is installed
+func (r *runner) check(ctx context.Context, name string, args ...string) error {
+	return r.execute(ctx, func(cmd *exec.Cmd) error {
+		return cmd.Run()
+	}, name, args...)
+}
+
+// runWithRetry runs a network operation such as `brew update`, retrying with backoff when it fails.
+// Timeouts and cancellation are not retried.
+func (r *runner) runWithRetry(ctx context.Context, name string, args ...string) error {
+	backoff := retryBackoff
+	for attempt := 0; ; attempt++ {
+		err := r.run(ctx, name, args...)
+		if err == nil || attempt >= r.retries || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return err
+		}
+
+		fmt.Fprintf(r.stderr, "%s %s failed (%v); retrying in %s (%d/%d)\n", name, strings.Join(args, " "), err, backoff, attempt+1, r.retries)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
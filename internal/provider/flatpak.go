@@ -0,0 +1,371 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// The default flatpak remote, added by SetupConfig
+const (
+	FlathubRemote = "flathub"
+	FlathubURL    = "https://dl.flathub.org/repo/flathub.flatpakrepo"
+)
+
+// flatpakNonInteractive are the flags that keep flatpak from asking questions
+var flatpakNonInteractive = []string{"-y", "--noninteractive"}
+
+// FlatpakProvider implements the Provider interface for flatpak applications, installed system-wide.
+// A package's ID is the application ID (org.mozilla.firefox), installed from Flathub, or
+// "<remote>:<application ID>" for another remote. Commands that change the system run with sudo
+// unless al runs as root.
+type FlatpakProvider struct {
+	name string
+	runner
+}
+
+// NewFlatpakProvider creates a new flatpak provider
+func NewFlatpakProvider() *FlatpakProvider {
+	return &FlatpakProvider{name: "flatpak", runner: newRunner("flatpak")}
+}
+
+// Name returns the provider name
+func (p *FlatpakProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the application ID, with "<remote>:" for a remote other than flathub, as
+// the ID, named by the application ID
+func (p *FlatpakProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	_, app := FlatpakSpec(id)
+	return id, app, nil
+}
+
+// Platforms returns the operating systems flatpak runs on
+func (p *FlatpakProvider) Platforms() []string {
+	return []string{"linux"}
+}
+
+// RequiresRoot reports that flatpak runs through sudo
+func (p *FlatpakProvider) RequiresRoot() bool {
+	return true
+}
+
+// FlatpakSpec splits a flatpak package ID into the remote and the application ID
+// ("flathub", "org.mozilla.firefox" for "org.mozilla.firefox")
+func FlatpakSpec(packageID string) (remote, app string) {
+	if remote, app, found := strings.Cut(packageID, ":"); found {
+		return remote, app
+	}
+	return FlathubRemote, packageID
+}
+
+// flatpakPackageID returns the package ID of an application installed from remote
+func flatpakPackageID(remote, app string) string {
+	if remote == FlathubRemote || remote == "" {
+		return app
+	}
+	return remote + ":" + app
+}
+
+// NormalizeID returns the application ID without the remote, as an application is only installed once
+func (p *FlatpakProvider) NormalizeID(packageID string) string {
+	_, app := FlatpakSpec(packageID)
+	return app
+}
+
+// CheckInstalled checks if flatpak is installed by running `flatpak --version`
+func (p *FlatpakProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "flatpak", "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, flatpak is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of flatpak
+func (p *FlatpakProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "flatpak", "--version")
+	if err != nil {
+		return "", err
+	}
+	// flatpak prints "Flatpak 1.14.4"
+	return versionField(string(output)), nil
+}
+
+// Install returns an error: flatpak is installed with the distribution's package manager
+func (p *FlatpakProvider) Install(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check flatpak installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("flatpak is already installed")
+	}
+	return fmt.Errorf("flatpak is not installed. Install it with the system's package manager first (e.g. 'al package add flatpak --provider apt')")
+}
+
+// SetupConfig sets up the configuration for flatpak provider and adds the Flathub remote
+// if it does not exist
+func (p *FlatpakProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	fmt.Fprintln(p.stdout, "Adding the Flathub remote...")
+	name, args := sudo("flatpak", "remote-add", "--if-not-exists", FlathubRemote, FlathubURL)
+	if err := p.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to add the Flathub remote: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if flatpak is not installed
+func (p *FlatpakProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check flatpak installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("flatpak is not installed. Please install it first using 'al provider add flatpak'")
+	}
+	return nil
+}
+
+// flatpakCmd returns the command that runs a flatpak command as root without prompts. Callers retry
+// commands that download and run the others once.
+func flatpakCmd(command string, args ...string) (string, []string) {
+	return sudo("flatpak", append(append([]string{command}, flatpakNonInteractive...), args...)...)
+}
+
+// InstallPackage installs an application with `flatpak install <remote> <application ID>`
+func (p *FlatpakProvider) InstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	remote, app := FlatpakSpec(packageID)
+	fmt.Fprintf(p.stdout, "Installing %s from %s using flatpak...\n", app, remote)
+	name, args := flatpakCmd("install", remote, app)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", app)
+	return nil
+}
+
+// UninstallPackage uninstalls an application with `flatpak uninstall`
+func (p *FlatpakProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	_, app := FlatpakSpec(packageID)
+	fmt.Fprintf(p.stdout, "Uninstalling %s using flatpak...\n", app)
+	name, args := flatpakCmd("uninstall", app)
+	if err := p.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", app, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", app)
+	return nil
+}
+
+// UpgradePackage upgrades an application with `flatpak update`
+func (p *FlatpakProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	return p.UpgradePackages(ctx, []string{packageID})
+}
+
+// UpgradePackages upgrades several applications with one `flatpak update`
+func (p *FlatpakProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	apps := make([]string, len(packageIDs))
+	for i, packageID := range packageIDs {
+		_, apps[i] = FlatpakSpec(packageID)
+	}
+	names := strings.Join(apps, ", ")
+	fmt.Fprintf(p.stdout, "Upgrading %s using flatpak...\n", names)
+	name, args := flatpakCmd("update", apps...)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to upgrade packages %s: %w", names, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", names)
+	return nil
+}
+
+// Upgrade updates the remotes' application metadata with `flatpak update --appstream`
+func (p *FlatpakProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.stdout, "Updating flatpak metadata...")
+	name, args := flatpakCmd("update", "--appstream")
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to update flatpak metadata: %w", err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully updated flatpak metadata")
+	return nil
+}
+
+// columns runs a flatpak command that prints tab-separated columns and returns the rows
+func (p *FlatpakProvider) columns(ctx context.Context, args ...string) ([][]string, error) {
+	output, err := p.output(ctx, "flatpak", args...)
+	if err != nil {
+		return nil, err
+	}
+	var rows [][]string
+	for _, line := range strings.Split(string(output), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		rows = append(rows, strings.Split(line, "\t"))
+	}
+	return rows, nil
+}
+
+// SearchPackage searches applications in the remotes with `flatpak search`
+func (p *FlatpakProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	rows, err := p.columns(ctx, "search", "--columns=application,name,description,version,remotes", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search packages: %w", err)
+	}
+
+	results := []SearchResult{}
+	for _, row := range rows {
+		// flatpak prints "No matches found" without columns
+		if len(row) < 5 {
+			continue
+		}
+		// An application in several remotes lists them separated by commas
+		remote, _, _ := strings.Cut(row[4], ",")
+		results = append(results, SearchResult{
+			ID:          flatpakPackageID(remote, row[0]),
+			Name:        row[1],
+			Description: row[2],
+			Version:     row[3],
+		})
+	}
+	return results, nil
+}
+
+// flatpakApp is an installed application
+type flatpakApp struct {
+	remote  string
+	version string
+}
+
+// installedApps returns the installed applications by application ID
+func (p *FlatpakProvider) installedApps(ctx context.Context) (map[string]flatpakApp, error) {
+	rows, err := p.columns(ctx, "list", "--app", "--columns=application,origin,version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed applications: %w", err)
+	}
+	apps := make(map[string]flatpakApp)
+	for _, row := range rows {
+		if len(row) < 3 {
+			continue
+		}
+		apps[row[0]] = flatpakApp{remote: row[1], version: row[2]}
+	}
+	return apps, nil
+}
+
+// ListInstalled lists the installed applications with their versions
+func (p *FlatpakProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+	apps, err := p.installedApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []InstalledPackage
+	for id, app := range apps {
+		packages = append(packages, InstalledPackage{ID: flatpakPackageID(app.remote, id), Name: id, Version: app.version})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated lists the installed applications with updates in their remotes (`flatpak remote-ls --updates`)
+func (p *FlatpakProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+	apps, err := p.installedApps(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := p.columns(ctx, "remote-ls", "--updates", "--app", "--columns=application,version")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list updates: %w", err)
+	}
+
+	var packages []OutdatedPackage
+	for _, row := range rows {
+		app, ok := apps[row[0]]
+		if !ok {
+			continue
+		}
+		latest := ""
+		if len(row) > 1 {
+			latest = row[1]
+		}
+		packages = append(packages, OutdatedPackage{
+			ID:             flatpakPackageID(app.remote, row[0]),
+			Name:           row[0],
+			CurrentVersion: app.version,
+			LatestVersion:  latest,
+		})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
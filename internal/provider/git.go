@@ -0,0 +1,522 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// GitProvider implements the Provider interface for git repositories cloned to a destination path.
+// A git package's ID is the destination path ("~/.zsh/zsh-autosuggestions"); its remote is
+// PackageConfig.URL and the branch, tag, or commit it follows is PackageConfig.Ref.
+type GitProvider struct {
+	name string
+	runner
+}
+
+// GitStatus is the state of a clone against the commit it follows
+type GitStatus struct {
+	// Head is the short hash of the checked out commit
+	Head string `json:"head"`
+	// Branch is the checked out branch, or empty when HEAD is detached
+	Branch string `json:"branch,omitempty"`
+	// Target is what the clone follows: the upstream branch, or the pinned ref. It is empty
+	// when there is nothing to compare with (a detached HEAD without a ref).
+	Target string `json:"target,omitempty"`
+	// Dirty is the number of changed and untracked files
+	Dirty int `json:"dirty"`
+	// Ahead is the number of local commits not in Target
+	Ahead int `json:"ahead"`
+	// Behind is the number of commits in Target not checked out
+	Behind int `json:"behind"`
+}
+
+// NewGitProvider creates a new git provider
+func NewGitProvider() *GitProvider {
+	return &GitProvider{name: "git", runner: newRunner("git")}
+}
+
+// Name returns the provider name
+func (p *GitProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the destination path of the clone (see GitPackageID) as the ID
+func (p *GitProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	path, err := GitPackageID(id)
+	if err != nil {
+		return "", "", err
+	}
+	return path, name, nil
+}
+
+// GitPackageID returns the package ID of a destination path: a path under the home directory
+// written with "~" is kept as it is, so that packages.json works on other machines, and a relative
+// path is made absolute
+func GitPackageID(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("the destination path of a git package must not be empty")
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") || filepath.IsAbs(path) {
+		return filepath.Clean(path), nil
+	}
+	return filepath.Abs(path)
+}
+
+// GitPath returns the destination path of a git package ID, expanding "~"
+func GitPath(packageID string) (string, error) {
+	return expandHome(packageID)
+}
+
+// IsGitClone reports whether path is the top of a git work tree
+func IsGitClone(path string) bool {
+	_, err := os.Stat(filepath.Join(path, ".git"))
+	return err == nil
+}
+
+// CheckInstalled checks if git is installed by running `git --version`
+func (p *GitProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "git", "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, git is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of git
+func (p *GitProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "git", "--version")
+	if err != nil {
+		return "", err
+	}
+
+	// git --version prints "git version 2.46.0"
+	fields := strings.Fields(string(output))
+	if len(fields) >= 3 {
+		return fields[2], nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Install installs git using Homebrew
+func (p *GitProvider) Install(ctx context.Context) error {
+	// Check if git is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check git installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("git is already installed")
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install git. Please install brew first using 'al provider add brew'")
+	}
+
+	// Install git using brew
+	fmt.Fprintln(p.stdout, "Installing git using brew...")
+	if err := p.runWithRetry(ctx, "brew", "install", "git"); err != nil {
+		return fmt.Errorf("failed to install git: %w", err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for git provider
+func (p *GitProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if git is not installed
+func (p *GitProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check git installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("git is not installed. Please install it first using 'al provider add git'")
+	}
+	return nil
+}
+
+// registeredPackage returns the registered git package with the ID, which has its remote and ref
+func (p *GitProvider) registeredPackage(packageID string) (*config.PackageConfig, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+	for i := range packagesConfig.Packages {
+		pkg := &packagesConfig.Packages[i]
+		if pkg.Provider == p.name && pkg.ID == packageID {
+			return pkg, nil
+		}
+	}
+	return nil, fmt.Errorf("git package %s is not registered", packageID)
+}
+
+// gitOutput runs git in the clone at dir and returns its trimmed standard output
+func (p *GitProvider) gitOutput(ctx context.Context, dir string, args ...string) (string, error) {
+	output, err := p.output(ctx, "git", append([]string{"-C", dir}, args...)...)
+	return strings.TrimSpace(string(output)), err
+}
+
+// isRemoteBranch reports whether ref is a branch of the clone's origin
+func (p *GitProvider) isRemoteBranch(ctx context.Context, dir, ref string) bool {
+	return p.check(ctx, "git", "-C", dir, "rev-parse", "--verify", "--quiet", "refs/remotes/origin/"+ref) == nil
+}
+
+// checkoutRef checks out ref in the clone at dir: a branch of origin is checked out and
+// fast-forwarded to origin, and a tag or commit is checked out as a detached HEAD
+func (p *GitProvider) checkoutRef(ctx context.Context, dir, ref string) error {
+	if p.isRemoteBranch(ctx, dir, ref) {
+		if err := p.run(ctx, "git", "-C", dir, "checkout", "--quiet", ref); err != nil {
+			return err
+		}
+		return p.run(ctx, "git", "-C", dir, "merge", "--ff-only", "origin/"+ref)
+	}
+	return p.run(ctx, "git", "-C", dir, "-c", "advice.detachedHead=false", "checkout", "--quiet", ref)
+}
+
+// Clone clones url to the destination path and checks out ref, if any. A path that is already a
+// clone of url is left as it is.
+func (p *GitProvider) Clone(ctx context.Context, url, path, ref string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+	if url == "" {
+		return fmt.Errorf("git package %s needs a remote URL (--url)", path)
+	}
+	dir, err := GitPath(path)
+	if err != nil {
+		return err
+	}
+
+	if IsGitClone(dir) {
+		origin, err := p.gitOutput(ctx, dir, "remote", "get-url", "origin")
+		if err != nil || origin != url {
+			return fmt.Errorf("%s is already a git repository with another remote (%s)", path, origin)
+		}
+		fmt.Fprintf(p.stdout, "%s is already cloned\n", path)
+		return nil
+	}
+
+	// Clone the repository
+	fmt.Fprintf(p.stdout, "Cloning %s into %s...\n", url, path)
+	if err := p.runWithRetry(ctx, "git", "clone", url, dir); err != nil {
+		return fmt.Errorf("failed to clone %s: %w", url, err)
+	}
+	if ref != "" {
+		if err := p.checkoutRef(ctx, dir, ref); err != nil {
+			return fmt.Errorf("failed to check out %s in %s: %w", ref, path, err)
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully cloned %s\n", path)
+	return nil
+}
+
+// InstallPackage clones a registered git package with its remote and ref
+func (p *GitProvider) InstallPackage(ctx context.Context, packageID string) error {
+	pkg, err := p.registeredPackage(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+	return p.Clone(ctx, pkg.URL, pkg.ID, pkg.Ref)
+}
+
+// Status returns the state of the clone at the destination path against ref, or against the
+// upstream branch when ref is empty. It does not fetch; call Fetch first for an up-to-date count.
+func (p *GitProvider) Status(ctx context.Context, path, ref string) (*GitStatus, error) {
+	dir, err := GitPath(path)
+	if err != nil {
+		return nil, err
+	}
+	if !IsGitClone(dir) {
+		return nil, fmt.Errorf("%s is not cloned", path)
+	}
+
+	status := &GitStatus{}
+	if status.Head, err = p.gitOutput(ctx, dir, "rev-parse", "--short", "HEAD"); err != nil {
+		return nil, fmt.Errorf("failed to read HEAD of %s: %w", path, err)
+	}
+	if branch, err := p.gitOutput(ctx, dir, "symbolic-ref", "--quiet", "--short", "HEAD"); err == nil {
+		status.Branch = branch
+	}
+
+	changes, err := p.gitOutput(ctx, dir, "status", "--porcelain")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the status of %s: %w", path, err)
+	}
+	if changes != "" {
+		status.Dirty = len(strings.Split(changes, "\n"))
+	}
+
+	// Compare with the branch of origin for a branch ref, the tag or commit itself otherwise
+	target := ref
+	switch {
+	case ref == "":
+		if status.Branch != "" {
+			target, _ = p.gitOutput(ctx, dir, "rev-parse", "--abbrev-ref", "--symbolic-full-name", "@{u}")
+		}
+	case p.isRemoteBranch(ctx, dir, ref):
+		target = "origin/" + ref
+	}
+	if target == "" || p.check(ctx, "git", "-C", dir, "rev-parse", "--verify", "--quiet", target+"^{commit}") != nil {
+		return status, nil
+	}
+	status.Target = target
+
+	counts, err := p.gitOutput(ctx, dir, "rev-list", "--left-right", "--count", "HEAD..."+target)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compare %s with %s: %w", path, target, err)
+	}
+	fields := strings.Fields(counts)
+	if len(fields) == 2 {
+		status.Ahead, _ = strconv.Atoi(fields[0])
+		status.Behind, _ = strconv.Atoi(fields[1])
+	}
+	return status, nil
+}
+
+// Fetch fetches the branches and tags of the clone's origin without changing the work tree
+func (p *GitProvider) Fetch(ctx context.Context, path string) error {
+	dir, err := GitPath(path)
+	if err != nil {
+		return err
+	}
+	if err := p.check(ctx, "git", "-C", dir, "fetch", "--quiet", "--tags", "origin"); err != nil {
+		return fmt.Errorf("failed to fetch %s: %w", path, err)
+	}
+	return nil
+}
+
+// UninstallPackage removes the clone. It fails if the clone has uncommitted changes, untracked
+// files, commits that are not in its upstream or ref, unpushed branches, or stashes, or if there is
+// nothing to compare it with, so that no local work is lost.
+func (p *GitProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+	dir, err := GitPath(packageID)
+	if err != nil {
+		return err
+	}
+	if !IsGitClone(dir) {
+		fmt.Fprintf(p.stdout, "%s is not cloned\n", packageID)
+		return nil
+	}
+
+	ref := ""
+	if pkg, err := p.registeredPackage(packageID); err == nil {
+		ref = pkg.Ref
+	}
+	status, err := p.Status(ctx, packageID, ref)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+	if status.Dirty > 0 {
+		return fmt.Errorf("%s has %d uncommitted change(s); commit or discard them, or remove it yourself", packageID, status.Dirty)
+	}
+	if status.Target == "" {
+		return fmt.Errorf("%s has no upstream or ref to compare with, so local commits cannot be ruled out; remove it yourself", packageID)
+	}
+	if status.Ahead > 0 {
+		return fmt.Errorf("%s has %d commit(s) not in %s; push them, or remove it yourself", packageID, status.Ahead, status.Target)
+	}
+	unpushed, err := p.gitOutput(ctx, dir, "log", "--oneline", "--branches", "--not", "--remotes")
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+	if unpushed != "" {
+		return fmt.Errorf("%s has %d commit(s) on local branches that are not pushed; push them, or remove it yourself", packageID, len(strings.Split(unpushed, "\n")))
+	}
+	stashes, err := p.gitOutput(ctx, dir, "stash", "list")
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+	if stashes != "" {
+		return fmt.Errorf("%s has %d stash(es); apply or drop them, or remove it yourself", packageID, len(strings.Split(stashes, "\n")))
+	}
+
+	fmt.Fprintf(p.stdout, "Removing %s...\n", packageID)
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage fetches origin and fast-forwards the clone to its upstream, or checks out its
+// ref: a branch is fast-forwarded to origin, and a tag or commit is checked out as it is
+func (p *GitProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+	pkg, err := p.registeredPackage(packageID)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	dir, err := GitPath(packageID)
+	if err != nil {
+		return err
+	}
+	if !IsGitClone(dir) {
+		return fmt.Errorf("failed to upgrade package %s: it is not cloned", packageID)
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using git...\n", packageID)
+	if err := p.runWithRetry(ctx, "git", "-C", dir, "fetch", "--tags", "origin"); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	if pkg.Ref != "" {
+		err = p.checkoutRef(ctx, dir, pkg.Ref)
+	} else {
+		err = p.run(ctx, "git", "-C", dir, "merge", "--ff-only", "@{u}")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", packageID)
+	return nil
+}
+
+// Upgrade upgrades git itself using brew
+func (p *GitProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	// Check if brew is installed
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to upgrade git. Please install brew first using 'al provider add brew'")
+	}
+
+	fmt.Fprintln(p.stdout, "Upgrading git using brew...")
+	if err := p.runWithRetry(ctx, "brew", "upgrade", "git"); err != nil {
+		return fmt.Errorf("failed to upgrade git: %w", err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully upgraded git")
+	return nil
+}
+
+// SearchPackage is not supported: a git package is any repository
+func (p *GitProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	return nil, fmt.Errorf("search is not supported by git")
+}
+
+// describe returns a readable name of a commit in the clone at dir: the nearest tag, or the short hash
+func (p *GitProvider) describe(ctx context.Context, dir, commit string) string {
+	name, err := p.gitOutput(ctx, dir, "describe", "--tags", "--always", commit)
+	if err != nil {
+		return ""
+	}
+	return name
+}
+
+// registeredClones returns the registered git packages that are cloned, once per ID
+func (p *GitProvider) registeredClones() ([]config.PackageConfig, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var clones []config.PackageConfig
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider != p.name || seen[pkg.ID] {
+			continue
+		}
+		seen[pkg.ID] = true
+		if dir, err := GitPath(pkg.ID); err == nil && IsGitClone(dir) {
+			clones = append(clones, pkg)
+		}
+	}
+	sort.Slice(clones, func(i, j int) bool {
+		return clones[i].Name < clones[j].Name
+	})
+	return clones, nil
+}
+
+// ListInstalled lists the registered git packages that are cloned, with the tag or commit checked out
+func (p *GitProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	clones, err := p.registeredClones()
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []InstalledPackage
+	for _, pkg := range clones {
+		dir, _ := GitPath(pkg.ID)
+		packages = append(packages, InstalledPackage{ID: pkg.ID, Name: pkg.Name, Version: p.describe(ctx, dir, "HEAD")})
+	}
+	return packages, nil
+}
+
+// ListOutdated fetches every registered clone and lists those behind their upstream or ref
+func (p *GitProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	clones, err := p.registeredClones()
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []OutdatedPackage
+	for _, pkg := range clones {
+		if err := p.Fetch(ctx, pkg.ID); err != nil {
+			return nil, err
+		}
+		status, err := p.Status(ctx, pkg.ID, pkg.Ref)
+		if err != nil {
+			return nil, err
+		}
+		if status.Behind == 0 {
+			continue
+		}
+		dir, _ := GitPath(pkg.ID)
+		packages = append(packages, OutdatedPackage{
+			ID:             pkg.ID,
+			Name:           pkg.Name,
+			CurrentVersion: p.describe(ctx, dir, "HEAD"),
+			LatestVersion:  p.describe(ctx, dir, status.Target),
+		})
+	}
+	return packages, nil
+}
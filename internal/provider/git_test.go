@@ -0,0 +1,254 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// gitFixture is a bare repository served over file:// and a work tree that pushes to it
+type gitFixture struct {
+	t    *testing.T
+	work string
+	url  string
+}
+
+func newGitFixture(t *testing.T) *gitFixture {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	t.Setenv("AL_HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_GLOBAL", os.DevNull)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "al")
+	t.Setenv("GIT_AUTHOR_EMAIL", "al@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "al")
+	t.Setenv("GIT_COMMITTER_EMAIL", "al@example.com")
+
+	root := t.TempDir()
+	f := &gitFixture{t: t, work: filepath.Join(root, "work")}
+	bare := filepath.Join(root, "remote.git")
+	f.git(root, "init", "--quiet", "--bare", "--initial-branch=main", bare)
+	f.git(root, "clone", "--quiet", bare, f.work)
+	f.git(f.work, "checkout", "--quiet", "-b", "main")
+	f.url = "file://" + bare
+	return f
+}
+
+// git runs git in dir and returns its trimmed output
+func (f *gitFixture) git(dir string, args ...string) string {
+	f.t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		f.t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return strings.TrimSpace(string(output))
+}
+
+// commit commits a change to file in the work tree, pushes it, and returns the commit hash
+func (f *gitFixture) commit(file, content string) string {
+	f.t.Helper()
+	if err := os.WriteFile(filepath.Join(f.work, file), []byte(content), 0644); err != nil {
+		f.t.Fatal(err)
+	}
+	f.git(f.work, "add", file)
+	f.git(f.work, "commit", "--quiet", "-m", "update "+file)
+	f.git(f.work, "push", "--quiet", "origin", "main")
+	return f.git(f.work, "rev-parse", "HEAD")
+}
+
+// register saves the git package to packages.json, where InstallPackage and UpgradePackage read it
+func (f *gitFixture) register(path, ref string) {
+	f.t.Helper()
+	err := config.SavePackagesConfig(&config.PackagesConfig{Packages: []config.PackageConfig{
+		{Name: filepath.Base(path), ID: path, Provider: "git", Profile: "default", URL: f.url, Ref: ref},
+	}})
+	if err != nil {
+		f.t.Fatal(err)
+	}
+}
+
+func newQuietGitProvider() *GitProvider {
+	p := NewGitProvider()
+	p.SetOutput(io.Discard, io.Discard)
+	return p
+}
+
+func TestGitCloneAtRef(t *testing.T) {
+	f := newGitFixture(t)
+	first := f.commit("README", "v1\n")
+	f.git(f.work, "tag", "v1")
+	f.git(f.work, "push", "--quiet", "origin", "v1")
+	f.commit("README", "v2\n")
+
+	ctx := context.Background()
+	p := newQuietGitProvider()
+	dest := filepath.Join(t.TempDir(), "clone")
+	f.register(dest, "v1")
+	if err := p.InstallPackage(ctx, dest); err != nil {
+		t.Fatalf("InstallPackage: %v", err)
+	}
+
+	if head := f.git(dest, "rev-parse", "HEAD"); head != first {
+		t.Errorf("HEAD = %s, want the v1 commit %s", head, first)
+	}
+	status, err := p.Status(ctx, dest, "v1")
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Branch != "" || status.Target != "v1" || status.Behind != 0 || status.Dirty != 0 {
+		t.Errorf("Status = %+v, want a clean detached HEAD at v1", status)
+	}
+
+	// Cloning again to the same path with the same remote is a no-op
+	if err := p.Clone(ctx, f.url, dest, "v1"); err != nil {
+		t.Errorf("Clone of an existing clone: %v", err)
+	}
+	if err := p.Clone(ctx, "file:///nonexistent.git", dest, ""); err == nil {
+		t.Error("Clone over a clone of another remote: expected an error")
+	}
+}
+
+func TestGitStatusAndUpgrade(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("README", "v1\n")
+
+	ctx := context.Background()
+	p := newQuietGitProvider()
+	dest := filepath.Join(t.TempDir(), "clone")
+	f.register(dest, "main")
+	if err := p.InstallPackage(ctx, dest); err != nil {
+		t.Fatalf("InstallPackage: %v", err)
+	}
+
+	f.commit("README", "v2\n")
+	latest := f.commit("NEWS", "news\n")
+
+	// Status does not fetch
+	status, err := p.Status(ctx, dest, "main")
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Behind != 0 {
+		t.Errorf("Behind before fetch = %d, want 0", status.Behind)
+	}
+
+	if err := p.Fetch(ctx, dest); err != nil {
+		t.Fatalf("Fetch: %v", err)
+	}
+	status, err = p.Status(ctx, dest, "main")
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Branch != "main" || status.Target != "origin/main" {
+		t.Errorf("Branch, Target = %q, %q, want main, origin/main", status.Branch, status.Target)
+	}
+	if status.Behind != 2 || status.Ahead != 0 || status.Dirty != 0 {
+		t.Errorf("Status = %+v, want 2 behind, 0 ahead, clean", status)
+	}
+
+	if err := os.WriteFile(filepath.Join(dest, "local.txt"), []byte("local\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	status, err = p.Status(ctx, dest, "main")
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Dirty != 1 {
+		t.Errorf("Dirty = %d, want 1", status.Dirty)
+	}
+
+	if err := p.UpgradePackage(ctx, dest); err != nil {
+		t.Fatalf("UpgradePackage: %v", err)
+	}
+	if head := f.git(dest, "rev-parse", "HEAD"); head != latest {
+		t.Errorf("HEAD after upgrade = %s, want %s", head, latest)
+	}
+	status, err = p.Status(ctx, dest, "main")
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	if status.Behind != 0 || status.Ahead != 0 {
+		t.Errorf("Status after upgrade = %+v, want up to date", status)
+	}
+}
+
+func TestGitUninstallRefusesLocalWork(t *testing.T) {
+	f := newGitFixture(t)
+	f.commit("README", "v1\n")
+
+	ctx := context.Background()
+	p := newQuietGitProvider()
+	dest := filepath.Join(t.TempDir(), "clone")
+	f.register(dest, "")
+	if err := p.InstallPackage(ctx, dest); err != nil {
+		t.Fatalf("InstallPackage: %v", err)
+	}
+
+	// An uncommitted change
+	if err := os.WriteFile(filepath.Join(dest, "README"), []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.UninstallPackage(ctx, dest); err == nil {
+		t.Fatal("UninstallPackage of a dirty clone: expected an error")
+	}
+	if !IsGitClone(dest) {
+		t.Fatal("the dirty clone was removed")
+	}
+
+	// A local commit that is not pushed
+	f.git(dest, "commit", "--quiet", "-am", "local")
+	if err := p.UninstallPackage(ctx, dest); err == nil {
+		t.Fatal("UninstallPackage of a clone with unpushed commits: expected an error")
+	}
+	if !IsGitClone(dest) {
+		t.Fatal("the clone with unpushed commits was removed")
+	}
+
+	f.git(dest, "reset", "--quiet", "--hard", "origin/main")
+
+	// A commit on a local branch that is not pushed
+	f.git(dest, "checkout", "--quiet", "-b", "wip")
+	if err := os.WriteFile(filepath.Join(dest, "WIP"), []byte("wip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.git(dest, "add", "WIP")
+	f.git(dest, "commit", "--quiet", "-m", "wip")
+	f.git(dest, "checkout", "--quiet", "main")
+	if err := p.UninstallPackage(ctx, dest); err == nil {
+		t.Fatal("UninstallPackage of a clone with an unpushed branch: expected an error")
+	}
+	f.git(dest, "branch", "--quiet", "-D", "wip")
+
+	// A stash
+	if err := os.WriteFile(filepath.Join(dest, "README"), []byte("stashed\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	f.git(dest, "stash", "--quiet")
+	if err := p.UninstallPackage(ctx, dest); err == nil {
+		t.Fatal("UninstallPackage of a clone with a stash: expected an error")
+	}
+	f.git(dest, "stash", "drop", "--quiet")
+
+	// A branch without an upstream cannot be compared
+	f.git(dest, "branch", "--unset-upstream")
+	if err := p.UninstallPackage(ctx, dest); err == nil {
+		t.Fatal("UninstallPackage of a branch without an upstream: expected an error")
+	}
+	f.git(dest, "branch", "--quiet", "--set-upstream-to", "origin/main")
+
+	if err := p.UninstallPackage(ctx, dest); err != nil {
+		t.Fatalf("UninstallPackage of a clean clone: %v", err)
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("the clean clone was not removed")
+	}
+}
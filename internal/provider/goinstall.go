@@ -0,0 +1,397 @@
+package provider
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/version"
+)
+
+// GoProvider implements the Provider interface for tools installed with `go install`
+type GoProvider struct {
+	name string
+	runner
+}
+
+// NewGoProvider creates a new go provider
+func NewGoProvider() *GoProvider {
+	return &GoProvider{name: "go", runner: newRunner("go")}
+}
+
+// Name returns the provider name
+func (p *GoProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the full package path with a version query as the ID
+// (golang.org/x/tools/gopls@latest, "@latest" when there is none), named by the binary
+func (p *GoProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	if !strings.Contains(id, "@") {
+		id += "@latest"
+	}
+	return id, GoBinaryName(id), nil
+}
+
+// GoPackagePath returns the package path of a go package ID, which is the full package path with a
+// version query: "golang.org/x/tools/gopls" for "golang.org/x/tools/gopls@latest"
+func GoPackagePath(packageID string) string {
+	path, _, _ := strings.Cut(packageID, "@")
+	return path
+}
+
+// goMajorVersionRegex matches the major version suffix of a module path ("v2")
+var goMajorVersionRegex = regexp.MustCompile(`^v[0-9]+$`)
+
+// GoBinaryName returns the name of the binary go install builds for a package ID: the last element
+// of the package path, skipping a major version suffix ("mockgen" for "go.uber.org/mock/mockgen",
+// "tool" for "example.com/tool/v2@latest")
+func GoBinaryName(packageID string) string {
+	elems := strings.Split(GoPackagePath(packageID), "/")
+	name := elems[len(elems)-1]
+	if len(elems) > 1 && goMajorVersionRegex.MatchString(name) {
+		name = elems[len(elems)-2]
+	}
+	return name
+}
+
+// goInstallSpec returns the argument of go install for a package ID: the ID itself when it has a
+// version query, or the package path with @latest
+func goInstallSpec(packageID string) string {
+	if strings.Contains(packageID, "@") {
+		return packageID
+	}
+	return packageID + "@latest"
+}
+
+// NormalizeID returns the package path without the version query, as listed by go version -m
+func (p *GoProvider) NormalizeID(packageID string) string {
+	return GoPackagePath(packageID)
+}
+
+// CheckInstalled checks if go is installed by running `go version`
+func (p *GoProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "go", "version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, go is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of go
+func (p *GoProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "go", "version")
+	if err != nil {
+		return "", err
+	}
+
+	// go version prints "go version go1.23.2 darwin/arm64"
+	fields := strings.Fields(string(output))
+	if len(fields) >= 3 {
+		return strings.TrimPrefix(fields[2], "go"), nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Install installs go using Homebrew
+func (p *GoProvider) Install(ctx context.Context) error {
+	// Check if go is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check go installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("go is already installed")
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install go. Please install brew first using 'al provider add brew'")
+	}
+
+	// Install go using brew
+	fmt.Fprintln(p.stdout, "Installing go using brew...")
+	if err := p.runWithRetry(ctx, "brew", "install", "go"); err != nil {
+		return fmt.Errorf("failed to install go: %w", err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for go provider
+func (p *GoProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if go is not installed
+func (p *GoProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check go installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("go is not installed. Please install it first using 'al provider add go'")
+	}
+	return nil
+}
+
+// binDir returns the directory go install writes binaries to: GOBIN, or the bin directory of the
+// first GOPATH entry
+func (p *GoProvider) binDir(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "go", "env", "GOBIN", "GOPATH")
+	if err != nil {
+		return "", fmt.Errorf("failed to get GOBIN: %w", err)
+	}
+	lines := strings.Split(string(output), "\n")
+	if gobin := strings.TrimSpace(lines[0]); gobin != "" {
+		return gobin, nil
+	}
+	if len(lines) > 1 {
+		if gopath := filepath.SplitList(strings.TrimSpace(lines[1])); len(gopath) > 0 && gopath[0] != "" {
+			return filepath.Join(gopath[0], "bin"), nil
+		}
+	}
+	return "", fmt.Errorf("neither GOBIN nor GOPATH is set")
+}
+
+// InstallPackage installs a tool with `go install <path>@<query>`
+// packageID is the full package path with a version query (e.g. "golang.org/x/tools/gopls@latest")
+func (p *GoProvider) InstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	// Run go install
+	spec := goInstallSpec(packageID)
+	fmt.Fprintf(p.stdout, "Installing %s using go...\n", spec)
+	if err := p.runWithRetry(ctx, "go", "install", spec); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", spec, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", spec)
+	return nil
+}
+
+// UninstallPackage removes the tool's binary from GOBIN (go has no uninstall command)
+func (p *GoProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	path := GoPackagePath(packageID)
+	tools, err := p.listTools(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", path, err)
+	}
+
+	removed := false
+	for _, tool := range tools {
+		if tool.path != path {
+			continue
+		}
+		fmt.Fprintf(p.stdout, "Removing %s...\n", tool.file)
+		if err := os.Remove(tool.file); err != nil {
+			return fmt.Errorf("failed to uninstall package %s: %w", path, err)
+		}
+		removed = true
+	}
+	if !removed {
+		return fmt.Errorf("failed to uninstall package %s: no binary built from it was found", path)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", path)
+	return nil
+}
+
+// UpgradePackage upgrades a tool by running `go install <path>@latest` again
+func (p *GoProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	spec := GoPackagePath(packageID) + "@latest"
+	fmt.Fprintf(p.stdout, "Upgrading %s using go...\n", GoPackagePath(packageID))
+	if err := p.runWithRetry(ctx, "go", "install", spec); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", GoPackagePath(packageID), err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", GoPackagePath(packageID))
+	return nil
+}
+
+// Upgrade upgrades go itself using brew
+func (p *GoProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	// Check if brew is installed
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to upgrade go. Please install brew first using 'al provider add brew'")
+	}
+
+	fmt.Fprintln(p.stdout, "Upgrading go using brew...")
+	if err := p.runWithRetry(ctx, "brew", "upgrade", "go"); err != nil {
+		return fmt.Errorf("failed to upgrade go: %w", err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully upgraded go")
+	return nil
+}
+
+// SearchPackage is not supported: the Go module index has no search API
+func (p *GoProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	return nil, fmt.Errorf("search is not supported by go (search on https://pkg.go.dev instead)")
+}
+
+// goTool is a binary in GOBIN as reported by `go version -m`
+type goTool struct {
+	file    string // path of the binary
+	path    string // package path it was built from
+	module  string // module path
+	version string // module version ("(devel)" for a local build)
+}
+
+// listTools runs `go version -m` on GOBIN. Binaries that were not built by go are left out.
+func (p *GoProvider) listTools(ctx context.Context) ([]goTool, error) {
+	dir, err := p.binDir(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		// Nothing has been installed yet
+		return nil, nil
+	}
+
+	output, err := p.output(ctx, "go", "version", "-m", dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read build info: %w", err)
+	}
+	return parseGoVersionM(string(output)), nil
+}
+
+// parseGoVersionM parses the output of `go version -m`:
+//
+//	/Users/me/go/bin/gopls: go1.23.2
+//		path	golang.org/x/tools/gopls
+//		mod	golang.org/x/tools/gopls	v0.16.2	h1:...
+func parseGoVersionM(output string) []goTool {
+	var tools []goTool
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "\t") {
+			if i := strings.LastIndex(line, ": "); i > 0 {
+				tools = append(tools, goTool{file: line[:i]})
+			}
+			continue
+		}
+		if len(tools) == 0 {
+			continue
+		}
+		tool := &tools[len(tools)-1]
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) >= 2 && fields[0] == "path":
+			tool.path = fields[1]
+		case len(fields) >= 3 && fields[0] == "mod":
+			tool.module = fields[1]
+			tool.version = fields[2]
+		}
+	}
+
+	result := tools[:0]
+	for _, tool := range tools {
+		if tool.path != "" {
+			result = append(result, tool)
+		}
+	}
+	return result
+}
+
+// ListInstalled lists the tools in GOBIN, with their package path as ID
+func (p *GoProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	tools, err := p.listTools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed tools: %w", err)
+	}
+
+	packages := make([]InstalledPackage, 0, len(tools))
+	for _, tool := range tools {
+		packages = append(packages, InstalledPackage{ID: tool.path, Name: filepath.Base(tool.file), Version: tool.version})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated lists the tools whose module has a newer version, asking `go list -m <module>@latest`
+// for each. Local builds ("(devel)") are left out.
+func (p *GoProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	tools, err := p.listTools(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdated tools: %w", err)
+	}
+
+	var packages []OutdatedPackage
+	for _, tool := range tools {
+		if tool.module == "" || !strings.HasPrefix(tool.version, "v") {
+			continue
+		}
+		output, err := p.output(ctx, "go", "list", "-m", "-f", "{{.Version}}", tool.module+"@latest")
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			// The module may be gone or private; it cannot be checked
+			continue
+		}
+		latest := strings.TrimSpace(string(output))
+		if latest != "" && version.Compare(latest, tool.version) > 0 {
+			packages = append(packages, OutdatedPackage{ID: tool.path, Name: filepath.Base(tool.file), CurrentVersion: tool.version, LatestVersion: latest})
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
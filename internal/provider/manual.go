@@ -1,8 +1,8 @@
 package provider
 
 import (
+	"context"
 	"fmt"
-	"time"
 
 	"github.com/kkato1030/al/internal/config"
 )
@@ -11,11 +11,12 @@ import (
 // This provider is used to track packages that are installed manually (via shell scripts, pkg/dmg files, etc.)
 type ManualProvider struct {
 	name string
+	runner
 }
 
 // NewManualProvider creates a new manual provider
 func NewManualProvider() *ManualProvider {
-	return &ManualProvider{name: "manual"}
+	return &ManualProvider{name: "manual", runner: newRunner("manual")}
 }
 
 // Name returns the provider name
@@ -23,34 +24,33 @@ func (p *ManualProvider) Name() string {
 	return p.name
 }
 
+// ResolvePackage returns the package name as the ID and the name
+func (p *ManualProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	return name, name, nil
+}
+
 // CheckInstalled always returns true for manual provider
 // Manual provider is always available as it's just a tracking mechanism
-func (p *ManualProvider) CheckInstalled() (bool, error) {
+func (p *ManualProvider) CheckInstalled(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
 // Install does nothing for manual provider
 // Manual provider doesn't need installation
-func (p *ManualProvider) Install() error {
+func (p *ManualProvider) Install(ctx context.Context) error {
 	// Manual provider doesn't require installation
 	return nil
 }
 
 // SetupConfig sets up the configuration for manual provider
-func (p *ManualProvider) SetupConfig() error {
+func (p *ManualProvider) SetupConfig(ctx context.Context) error {
 	// Ensure config directory exists
 	if err := config.EnsureConfigDir(); err != nil {
 		return fmt.Errorf("failed to ensure config directory: %w", err)
 	}
 
-	// Add provider to config
-	providerConfig := config.ProviderConfig{
-		Name:        p.name,
-		InstalledAt: time.Now(),
-		Version:     "", // Manual provider doesn't have a version
-	}
-
-	if err := config.AddOrUpdateProvider(providerConfig); err != nil {
+	// Add provider to config (manual provider doesn't have a version)
+	if err := saveProviderVersion(p.name, ""); err != nil {
 		return fmt.Errorf("failed to save provider config: %w", err)
 	}
 
@@ -59,42 +59,53 @@ func (p *ManualProvider) SetupConfig() error {
 
 // InstallPackage does nothing for manual provider
 // Packages are assumed to be already installed manually
-func (p *ManualProvider) InstallPackage(packageID string) error {
+func (p *ManualProvider) InstallPackage(ctx context.Context, packageID string) error {
 	// Manual provider doesn't install packages
 	// Packages are assumed to be already installed manually
-	fmt.Printf("Note: Package '%s' is tracked as manually installed. Please ensure it is already installed.\n", packageID)
+	fmt.Fprintf(p.stdout, "Note: Package '%s' is tracked as manually installed. Please ensure it is already installed.\n", packageID)
 	return nil
 }
 
 // UninstallPackage removes the package from tracking for manual provider
 // Actual uninstallation must be done manually by the user
-func (p *ManualProvider) UninstallPackage(packageID string) error {
+func (p *ManualProvider) UninstallPackage(ctx context.Context, packageID string) error {
 	// Manual provider doesn't uninstall packages automatically
 	// The package will be removed from config by the remove command
 	// Users need to uninstall the actual package manually if needed
-	fmt.Printf("Removing package '%s' from tracking (manual provider).\n", packageID)
-	fmt.Printf("Note: If you want to uninstall the actual package, please do so manually.\n")
+	fmt.Fprintf(p.stdout, "Removing package '%s' from tracking (manual provider).\n", packageID)
+	fmt.Fprintf(p.stdout, "Note: If you want to uninstall the actual package, please do so manually.\n")
 	return nil
 }
 
 // UpgradePackage does nothing for manual provider
 // Users need to upgrade packages manually
-func (p *ManualProvider) UpgradePackage(packageID string) error {
+func (p *ManualProvider) UpgradePackage(ctx context.Context, packageID string) error {
 	// Manual provider doesn't upgrade packages
 	// Users need to upgrade packages manually
-	fmt.Printf("Note: Package '%s' is tracked as manually installed. Please upgrade it manually if needed.\n", packageID)
+	fmt.Fprintf(p.stdout, "Note: Package '%s' is tracked as manually installed. Please upgrade it manually if needed.\n", packageID)
 	return nil
 }
 
 // Upgrade does nothing for manual provider
-func (p *ManualProvider) Upgrade() error {
+func (p *ManualProvider) Upgrade(ctx context.Context) error {
 	// Manual provider doesn't have an upgrade mechanism
 	return nil
 }
 
 // SearchPackage returns an empty result for manual provider
 // Manual provider doesn't support searching
-func (p *ManualProvider) SearchPackage(query string) ([]SearchResult, error) {
+func (p *ManualProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
 	// Manual provider doesn't support searching
 	return []SearchResult{}, nil
 }
+
+// ListInstalled returns an empty result for manual provider
+// Manual provider cannot detect installed packages
+func (p *ManualProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	return []InstalledPackage{}, nil
+}
+
+// ListOutdated returns an empty result for manual provider
+func (p *ManualProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	return []OutdatedPackage{}, nil
+}
@@ -1,11 +1,9 @@
 package provider
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
 	"strings"
-	"time"
 
 	"github.com/kkato1030/al/internal/config"
 )
@@ -13,11 +11,12 @@ import (
 // MasProvider implements the Provider interface for Mac App Store (mas)
 type MasProvider struct {
 	name string
+	runner
 }
 
 // NewMasProvider creates a new mas provider
 func NewMasProvider() *MasProvider {
-	return &MasProvider{name: "mas"}
+	return &MasProvider{name: "mas", runner: newRunner("mas")}
 }
 
 // Name returns the provider name
@@ -25,11 +24,18 @@ func (p *MasProvider) Name() string {
 	return p.name
 }
 
+// Platforms returns the operating systems mas runs on: the Mac App Store is only on macOS
+func (p *MasProvider) Platforms() []string {
+	return []string{"darwin"}
+}
+
 // CheckInstalled checks if mas is installed by running `mas version`
-func (p *MasProvider) CheckInstalled() (bool, error) {
-	cmd := exec.Command("mas", "version")
-	err := cmd.Run()
+func (p *MasProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "mas", "version")
 	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
 		// If command fails, mas is not installed
 		return false, nil
 	}
@@ -37,9 +43,8 @@ func (p *MasProvider) CheckInstalled() (bool, error) {
 }
 
 // GetVersion returns the version of mas
-func (p *MasProvider) GetVersion() (string, error) {
-	cmd := exec.Command("mas", "version")
-	output, err := cmd.Output()
+func (p *MasProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "mas", "version")
 	if err != nil {
 		return "", err
 	}
@@ -48,9 +53,9 @@ func (p *MasProvider) GetVersion() (string, error) {
 }
 
 // Install installs mas using Homebrew
-func (p *MasProvider) Install() error {
+func (p *MasProvider) Install(ctx context.Context) error {
 	// Check if mas is already installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check mas installation: %w", err)
 	}
@@ -60,8 +65,7 @@ func (p *MasProvider) Install() error {
 
 	// Check if brew is installed first
 	brewInstalled := false
-	brewCmd := exec.Command("brew", "--version")
-	if err := brewCmd.Run(); err == nil {
+	if err := p.check(ctx, "brew", "--version"); err == nil {
 		brewInstalled = true
 	}
 
@@ -70,13 +74,8 @@ func (p *MasProvider) Install() error {
 	}
 
 	// Install mas using brew
-	fmt.Println("Installing mas using brew...")
-	cmd := exec.Command("brew", "install", "mas")
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	fmt.Fprintln(p.stdout, "Installing mas using brew...")
+	if err := p.runWithRetry(ctx, "brew", "install", "mas"); err != nil {
 		return fmt.Errorf("failed to install mas: %w", err)
 	}
 
@@ -84,27 +83,21 @@ func (p *MasProvider) Install() error {
 }
 
 // SetupConfig sets up the configuration for mas provider
-func (p *MasProvider) SetupConfig() error {
+func (p *MasProvider) SetupConfig(ctx context.Context) error {
 	// Ensure config directory exists
 	if err := config.EnsureConfigDir(); err != nil {
 		return fmt.Errorf("failed to ensure config directory: %w", err)
 	}
 
 	// Get version
-	version, err := p.GetVersion()
+	version, err := p.GetVersion(ctx)
 	if err != nil {
 		// If version cannot be retrieved, continue without it
 		version = ""
 	}
 
 	// Add provider to config
-	providerConfig := config.ProviderConfig{
-		Name:        p.name,
-		InstalledAt: time.Now(),
-		Version:     version,
-	}
-
-	if err := config.AddOrUpdateProvider(providerConfig); err != nil {
+	if err := saveProviderVersion(p.name, version); err != nil {
 		return fmt.Errorf("failed to save provider config: %w", err)
 	}
 
@@ -113,9 +106,9 @@ func (p *MasProvider) SetupConfig() error {
 
 // InstallPackage installs a package using mas
 // packageID is the app ID (id = app_id for mas)
-func (p *MasProvider) InstallPackage(packageID string) error {
+func (p *MasProvider) InstallPackage(ctx context.Context, packageID string) error {
 	// Check if mas is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check mas installation: %w", err)
 	}
@@ -124,25 +117,20 @@ func (p *MasProvider) InstallPackage(packageID string) error {
 	}
 
 	// Run mas install command
-	fmt.Printf("Installing %s using mas...\n", packageID)
-	cmd := exec.Command("mas", "install", packageID)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	fmt.Fprintf(p.stdout, "Installing %s using mas...\n", packageID)
+	if err := p.runWithRetry(ctx, "mas", "install", packageID); err != nil {
 		return fmt.Errorf("failed to install package %s: %w", packageID, err)
 	}
 
-	fmt.Printf("Successfully installed %s\n", packageID)
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
 	return nil
 }
 
 // UninstallPackage uninstalls a package using mas
 // packageID is the app ID (id = app_id for mas)
-func (p *MasProvider) UninstallPackage(packageID string) error {
+func (p *MasProvider) UninstallPackage(ctx context.Context, packageID string) error {
 	// Check if mas is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check mas installation: %w", err)
 	}
@@ -151,25 +139,20 @@ func (p *MasProvider) UninstallPackage(packageID string) error {
 	}
 
 	// Run mas uninstall command
-	fmt.Printf("Uninstalling %s using mas...\n", packageID)
-	cmd := exec.Command("mas", "uninstall", packageID)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	fmt.Fprintf(p.stdout, "Uninstalling %s using mas...\n", packageID)
+	if err := p.run(ctx, "mas", "uninstall", packageID); err != nil {
 		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
 	}
 
-	fmt.Printf("Successfully uninstalled %s\n", packageID)
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
 	return nil
 }
 
 // UpgradePackage upgrades a package using mas
 // packageID is the app ID (id = app_id for mas)
-func (p *MasProvider) UpgradePackage(packageID string) error {
+func (p *MasProvider) UpgradePackage(ctx context.Context, packageID string) error {
 	// Check if mas is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check mas installation: %w", err)
 	}
@@ -178,24 +161,37 @@ func (p *MasProvider) UpgradePackage(packageID string) error {
 	}
 
 	// Run mas upgrade command
-	fmt.Printf("Upgrading %s using mas...\n", packageID)
-	cmd := exec.Command("mas", "upgrade", packageID)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	fmt.Fprintf(p.stdout, "Upgrading %s using mas...\n", packageID)
+	if err := p.run(ctx, "mas", "upgrade", packageID); err != nil {
 		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
 	}
 
-	fmt.Printf("Successfully upgraded %s\n", packageID)
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", packageID)
+	return nil
+}
+
+// UpgradePackages upgrades several apps with one `mas upgrade`
+func (p *MasProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
+	// Check if mas is installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check mas installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("mas is not installed. Please install it first using 'al provider add mas'")
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using mas...\n", strings.Join(packageIDs, ", "))
+	if err := p.run(ctx, "mas", append([]string{"upgrade"}, packageIDs...)...); err != nil {
+		return fmt.Errorf("failed to upgrade packages %s: %w", strings.Join(packageIDs, ", "), err)
+	}
 	return nil
 }
 
 // Upgrade upgrades mas itself using brew
-func (p *MasProvider) Upgrade() error {
+func (p *MasProvider) Upgrade(ctx context.Context) error {
 	// Check if mas is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to check mas installation: %w", err)
 	}
@@ -205,8 +201,7 @@ func (p *MasProvider) Upgrade() error {
 
 	// Check if brew is installed
 	brewInstalled := false
-	brewCmd := exec.Command("brew", "--version")
-	if err := brewCmd.Run(); err == nil {
+	if err := p.check(ctx, "brew", "--version"); err == nil {
 		brewInstalled = true
 	}
 
@@ -215,37 +210,27 @@ func (p *MasProvider) Upgrade() error {
 	}
 
 	// Run brew upgrade mas
-	fmt.Println("Upgrading mas using brew...")
-	cmd := exec.Command("brew", "upgrade", "mas")
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
+	fmt.Fprintln(p.stdout, "Upgrading mas using brew...")
+	if err := p.runWithRetry(ctx, "brew", "upgrade", "mas"); err != nil {
 		return fmt.Errorf("failed to upgrade mas: %w", err)
 	}
 
 	// Update version in config
-	version, err := p.GetVersion()
+	version, err := p.GetVersion(ctx)
 	if err == nil {
-		providerConfig := config.ProviderConfig{
-			Name:        p.name,
-			InstalledAt: time.Now(),
-			Version:     version,
-		}
-		if err := config.AddOrUpdateProvider(providerConfig); err != nil {
-			fmt.Printf("Warning: failed to update provider config: %v\n", err)
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
 		}
 	}
 
-	fmt.Println("Successfully upgraded mas")
+	fmt.Fprintln(p.stdout, "Successfully upgraded mas")
 	return nil
 }
 
 // SearchPackage searches for packages using mas search
-func (p *MasProvider) SearchPackage(query string) ([]SearchResult, error) {
+func (p *MasProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
 	// Check if mas is installed
-	installed, err := p.CheckInstalled()
+	installed, err := p.CheckInstalled(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to check mas installation: %w", err)
 	}
@@ -254,8 +239,7 @@ func (p *MasProvider) SearchPackage(query string) ([]SearchResult, error) {
 	}
 
 	// Run mas search command
-	cmd := exec.Command("mas", "search", query)
-	output, err := cmd.Output()
+	output, err := p.output(ctx, "mas", "search", query)
 	if err != nil {
 		return nil, fmt.Errorf("failed to search packages: %w", err)
 	}
@@ -299,3 +283,65 @@ func (p *MasProvider) SearchPackage(query string) ([]SearchResult, error) {
 
 	return results, nil
 }
+
+// ListInstalled lists installed apps with `mas list`
+func (p *MasProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	output, err := p.output(ctx, "mas", "list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed apps: %w", err)
+	}
+
+	// Parse output - mas list returns lines like:
+	// "497799835  Xcode  (15.0)"
+	var packages []InstalledPackage
+	for _, line := range strings.Split(string(output), "\n") {
+		appID, name, version, ok := parseMasLine(line)
+		if !ok {
+			continue
+		}
+		packages = append(packages, InstalledPackage{ID: appID, Name: name, Version: version})
+	}
+	return packages, nil
+}
+
+// ListOutdated lists apps with updates available with `mas outdated`
+func (p *MasProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	output, err := p.output(ctx, "mas", "outdated")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdated apps: %w", err)
+	}
+
+	// Parse output - mas outdated returns lines like:
+	// "497799835  Xcode  (15.0 -> 15.1)"
+	var packages []OutdatedPackage
+	for _, line := range strings.Split(string(output), "\n") {
+		appID, name, versions, ok := parseMasLine(line)
+		if !ok {
+			continue
+		}
+		pkg := OutdatedPackage{ID: appID, Name: name}
+		if current, latest, found := strings.Cut(versions, "->"); found {
+			pkg.CurrentVersion = strings.TrimSpace(current)
+			pkg.LatestVersion = strings.TrimSpace(latest)
+		}
+		packages = append(packages, pkg)
+	}
+	return packages, nil
+}
+
+// parseMasLine parses "<app_id> <name> (<detail>)" as printed by mas list and mas outdated
+func parseMasLine(line string) (appID, name, detail string, ok bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 2 {
+		return "", "", "", false
+	}
+	appID = fields[0]
+	rest := strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), appID))
+	if strings.HasSuffix(rest, ")") {
+		if lastParen := strings.LastIndex(rest, "("); lastParen >= 0 {
+			detail = strings.TrimSpace(rest[lastParen+1 : len(rest)-1])
+			rest = strings.TrimSpace(rest[:lastParen])
+		}
+	}
+	return appID, rest, detail, true
+}
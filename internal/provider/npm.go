@@ -0,0 +1,347 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// NpmProvider implements the Provider interface for global npm packages (npm install -g)
+type NpmProvider struct {
+	name string
+	runner
+}
+
+// NewNpmProvider creates a new npm provider
+func NewNpmProvider() *NpmProvider {
+	return &NpmProvider{name: "npm", runner: newRunner("npm")}
+}
+
+// Name returns the provider name
+func (p *NpmProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the package name with an optional version spec (typescript@5, @scope/name)
+// as the ID, named by the package name
+func (p *NpmProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, NpmPackageName(id), nil
+}
+
+// NpmPackageName returns the package name of an npm package ID, which is the package name
+// optionally followed by a version spec: "typescript" for "typescript@5", "@scope/name" for
+// "@scope/name@^1.2"
+func NpmPackageName(packageID string) string {
+	name, _ := splitNpmSpec(packageID)
+	return name
+}
+
+// splitNpmSpec splits "name@spec" into name and spec. The "@" of a scope is not a separator.
+func splitNpmSpec(packageID string) (name, spec string) {
+	if i := strings.LastIndex(packageID, "@"); i > 0 {
+		return packageID[:i], packageID[i+1:]
+	}
+	return packageID, ""
+}
+
+// NormalizeID returns the package name without the version spec, as listed by npm ls and npm outdated
+func (p *NpmProvider) NormalizeID(packageID string) string {
+	return NpmPackageName(packageID)
+}
+
+// CheckInstalled checks if npm is installed by running `npm --version`
+func (p *NpmProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "npm", "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, npm is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of npm
+func (p *NpmProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, "npm", "--version")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Install installs npm (with Node.js) using Homebrew
+func (p *NpmProvider) Install(ctx context.Context) error {
+	// Check if npm is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check npm installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("npm is already installed")
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install npm. Please install brew first using 'al provider add brew'")
+	}
+
+	// Install node (which includes npm) using brew
+	fmt.Fprintln(p.stdout, "Installing node using brew...")
+	if err := p.runWithRetry(ctx, "brew", "install", "node"); err != nil {
+		return fmt.Errorf("failed to install npm: %w", err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for npm provider
+func (p *NpmProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, version); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if npm is not installed
+func (p *NpmProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check npm installation: %w", err)
+	}
+	if !installed {
+		return fmt.Errorf("npm is not installed. Please install it first using 'al provider add npm'")
+	}
+	return nil
+}
+
+// InstallPackage installs a global package with `npm install -g`
+// packageID is the package name, optionally with a version spec (e.g. "typescript@5")
+func (p *NpmProvider) InstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	// Run npm install
+	fmt.Fprintf(p.stdout, "Installing %s using npm...\n", packageID)
+	if err := p.runWithRetry(ctx, "npm", "install", "-g", packageID); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls a global package with `npm uninstall -g`
+func (p *NpmProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	name := NpmPackageName(packageID)
+	fmt.Fprintf(p.stdout, "Uninstalling %s using npm...\n", name)
+	if err := p.run(ctx, "npm", "uninstall", "-g", name); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", name, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", name)
+	return nil
+}
+
+// npmUpgradeSpec returns what npm install -g upgrades packageID to: the latest version within its
+// version spec, or the latest version when it has none
+func npmUpgradeSpec(packageID string) string {
+	if _, spec := splitNpmSpec(packageID); spec != "" {
+		return packageID
+	}
+	return packageID + "@latest"
+}
+
+// UpgradePackage upgrades a global package by installing its latest version (within its version spec)
+func (p *NpmProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using npm...\n", NpmPackageName(packageID))
+	if err := p.runWithRetry(ctx, "npm", "install", "-g", npmUpgradeSpec(packageID)); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", NpmPackageName(packageID), err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", NpmPackageName(packageID))
+	return nil
+}
+
+// UpgradePackages upgrades several global packages with one `npm install -g`
+func (p *NpmProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	args := []string{"install", "-g"}
+	names := make([]string, len(packageIDs))
+	for i, packageID := range packageIDs {
+		args = append(args, npmUpgradeSpec(packageID))
+		names[i] = NpmPackageName(packageID)
+	}
+	fmt.Fprintf(p.stdout, "Upgrading %s using npm...\n", strings.Join(names, ", "))
+	if err := p.runWithRetry(ctx, "npm", args...); err != nil {
+		return fmt.Errorf("failed to upgrade packages %s: %w", strings.Join(names, ", "), err)
+	}
+	return nil
+}
+
+// Upgrade upgrades npm itself with `npm install -g npm@latest`
+func (p *NpmProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(p.stdout, "Upgrading npm...")
+	if err := p.runWithRetry(ctx, "npm", "install", "-g", "npm@latest"); err != nil {
+		return fmt.Errorf("failed to upgrade npm: %w", err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintln(p.stdout, "Successfully upgraded npm")
+	return nil
+}
+
+// npmSearchResult is one result of `npm search --json`
+type npmSearchResult struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description"`
+	Links       struct {
+		Homepage string `json:"homepage"`
+		Npm      string `json:"npm"`
+	} `json:"links"`
+}
+
+// SearchPackage searches the npm registry with `npm search --json`
+func (p *NpmProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return nil, err
+	}
+
+	output, err := p.output(ctx, "npm", "search", "--json", query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search packages: %w", err)
+	}
+
+	var found []npmSearchResult
+	if err := json.Unmarshal(output, &found); err != nil {
+		return nil, fmt.Errorf("failed to parse npm search output: %w", err)
+	}
+
+	results := make([]SearchResult, 0, len(found))
+	for _, r := range found {
+		homepage := r.Links.Homepage
+		if homepage == "" {
+			homepage = r.Links.Npm
+		}
+		results = append(results, SearchResult{
+			ID:          r.Name,
+			Name:        r.Name,
+			Description: r.Description,
+			Version:     r.Version,
+			Homepage:    homepage,
+		})
+	}
+	return results, nil
+}
+
+// ListInstalled lists the global packages with `npm ls -g --json --depth=0`
+func (p *NpmProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	output, err := p.jsonOutput(ctx, "ls", "-g", "--json", "--depth=0")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed packages: %w", err)
+	}
+
+	var tree struct {
+		Dependencies map[string]struct {
+			Version string `json:"version"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(output, &tree); err != nil {
+		return nil, fmt.Errorf("failed to parse npm ls output: %w", err)
+	}
+
+	packages := make([]InstalledPackage, 0, len(tree.Dependencies))
+	for name, dep := range tree.Dependencies {
+		packages = append(packages, InstalledPackage{ID: name, Name: name, Version: dep.Version})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated lists the global packages with a newer version with `npm outdated -g --json`
+func (p *NpmProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	output, err := p.jsonOutput(ctx, "outdated", "-g", "--json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outdated packages: %w", err)
+	}
+
+	outdated := map[string]struct {
+		Current string `json:"current"`
+		Latest  string `json:"latest"`
+	}{}
+	if len(strings.TrimSpace(string(output))) > 0 {
+		if err := json.Unmarshal(output, &outdated); err != nil {
+			return nil, fmt.Errorf("failed to parse npm outdated output: %w", err)
+		}
+	}
+
+	packages := make([]OutdatedPackage, 0, len(outdated))
+	for name, versions := range outdated {
+		packages = append(packages, OutdatedPackage{ID: name, Name: name, CurrentVersion: versions.Current, LatestVersion: versions.Latest})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// jsonOutput runs an npm command that prints JSON. npm outdated exits with 1 when it finds
+// outdated packages, and npm ls when the tree has problems; their output is used all the same.
+func (p *NpmProvider) jsonOutput(ctx context.Context, args ...string) ([]byte, error) {
+	output, err := p.output(ctx, "npm", args...)
+	var exitErr *exec.ExitError
+	if err != nil && errors.As(err, &exitErr) && len(output) > 0 {
+		return output, nil
+	}
+	return output, err
+}
@@ -0,0 +1,116 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// PlatformRestricted is implemented by providers that only run on some operating systems.
+// Providers that do not implement it run everywhere al runs.
+type PlatformRestricted interface {
+	// Platforms returns the operating systems the provider runs on (runtime.GOOS values, e.g. "darwin", "linux")
+	Platforms() []string
+}
+
+// Platforms returns the operating systems the named provider runs on, or nil if it runs on all of them
+func Platforms(name string) []string {
+	p, err := New(name)
+	if err != nil {
+		return nil
+	}
+	if r, ok := p.(PlatformRestricted); ok {
+		return r.Platforms()
+	}
+	return nil
+}
+
+// SupportsHost reports whether the named provider runs on this machine's operating system
+func SupportsHost(name string) bool {
+	platforms := Platforms(name)
+	if platforms == nil {
+		return true
+	}
+	for _, platform := range platforms {
+		if platform == runtime.GOOS {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckHost returns an error if the named provider does not run on this machine's operating system
+func CheckHost(name string) error {
+	if SupportsHost(name) {
+		return nil
+	}
+	return fmt.Errorf("provider %s is not supported on %s (it runs on %s)", name, runtime.GOOS, strings.Join(Platforms(name), ", "))
+}
+
+// HostNames returns the providers that run on this machine's operating system
+func HostNames() []string {
+	var names []string
+	for _, name := range Names {
+		if SupportsHost(name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// RootRequirer is implemented by providers whose package commands run with root privileges
+// through sudo, e.g. system package managers
+type RootRequirer interface {
+	RequiresRoot() bool
+}
+
+// NeedsSudo reports whether the named provider runs commands through sudo, which may prompt for a
+// password: it requires root and al is not running as root
+func NeedsSudo(name string) bool {
+	if os.Geteuid() == 0 {
+		return false
+	}
+	p, err := New(name)
+	if err != nil {
+		return false
+	}
+	r, ok := p.(RootRequirer)
+	return ok && r.RequiresRoot()
+}
+
+// ValidateSudo runs `sudo -v` on the terminal, so that the password is asked once up front and
+// later sudo commands do not prompt while their output is hidden
+func ValidateSudo(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "sudo", "-v")
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("sudo -v failed: %w", err)
+	}
+	return nil
+}
+
+// sudo returns the command and arguments that run name with root privileges: name itself when al
+// runs as root, otherwise through sudo, which asks for the password on the terminal when needed
+func sudo(name string, args ...string) (string, []string) {
+	if os.Geteuid() == 0 {
+		return name, args
+	}
+	return "sudo", append([]string{name}, args...)
+}
+
+// versionField returns the first field of a tool's --version output that looks like a version,
+// without a leading "v" (e.g. "2.4.11" for "apt 2.4.11 (amd64)")
+func versionField(output string) string {
+	for _, field := range strings.Fields(output) {
+		field = strings.TrimPrefix(field, "v")
+		if field != "" && field[0] >= '0' && field[0] <= '9' {
+			return field
+		}
+	}
+	return ""
+}
@@ -1,38 +1,239 @@
 package provider
 
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
 // SearchResult represents a search result from a provider
 type SearchResult struct {
-	ID          string
-	Name        string
-	Description string
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version,omitempty"`
+	Homepage    string `json:"homepage,omitempty"`
+	Tap         string `json:"tap,omitempty"`
+}
+
+// InstalledPackage represents a package installed by a provider
+type InstalledPackage struct {
+	// ID is in the same format as PackageConfig.ID for the provider
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+}
+
+// OutdatedPackage represents an installed package with a newer version available
+type OutdatedPackage struct {
+	// ID is in the same format as PackageConfig.ID for the provider
+	ID             string `json:"id"`
+	Name           string `json:"name"`
+	CurrentVersion string `json:"current_version,omitempty"`
+	LatestVersion  string `json:"latest_version,omitempty"`
 }
 
-// Provider represents a package manager provider
+// Provider represents a package manager provider.
+// Methods that run commands take a context: canceling it interrupts the running command.
 type Provider interface {
 	// Name returns the name of the provider
 	Name() string
 
 	// CheckInstalled checks if the package manager is installed
-	CheckInstalled() (bool, error)
+	CheckInstalled(ctx context.Context) (bool, error)
 
 	// Install installs the package manager
-	Install() error
+	Install(ctx context.Context) error
 
 	// SetupConfig sets up the configuration for the provider
-	SetupConfig() error
+	SetupConfig(ctx context.Context) error
 
 	// InstallPackage installs a package using the provider
-	InstallPackage(packageID string) error
+	InstallPackage(ctx context.Context, packageID string) error
 
 	// UninstallPackage uninstalls a package using the provider
-	UninstallPackage(packageID string) error
+	UninstallPackage(ctx context.Context, packageID string) error
 
 	// UpgradePackage upgrades a package using the provider
-	UpgradePackage(packageID string) error
+	UpgradePackage(ctx context.Context, packageID string) error
 
 	// Upgrade upgrades the provider itself (e.g., brew update, mas upgrade)
-	Upgrade() error
+	Upgrade(ctx context.Context) error
 
 	// SearchPackage searches for packages matching the query
-	SearchPackage(query string) ([]SearchResult, error)
+	SearchPackage(ctx context.Context, query string) ([]SearchResult, error)
+
+	// ListInstalled lists the packages installed by the provider with their versions
+	ListInstalled(ctx context.Context) ([]InstalledPackage, error)
+
+	// ListOutdated lists the installed packages that have a newer version available
+	ListOutdated(ctx context.Context) ([]OutdatedPackage, error)
+}
+
+// IDNormalizer is implemented by providers whose package IDs can be written in more than one way
+type IDNormalizer interface {
+	// NormalizeID returns the canonical form of a package ID, used to match registered
+	// packages against ListInstalled and ListOutdated
+	NormalizeID(packageID string) string
+}
+
+// Pinner is implemented by providers that can pin packages, so that the package manager's own
+// upgrade commands also leave them alone
+type Pinner interface {
+	// Pin pins a package. Packages that cannot be pinned are left as they are.
+	Pin(ctx context.Context, packageID string) error
+
+	// Unpin unpins a package
+	Unpin(ctx context.Context, packageID string) error
+}
+
+// OutputSetter is implemented by providers whose command output and messages can be redirected
+type OutputSetter interface {
+	// SetOutput redirects command output and messages (default: os.Stdout and os.Stderr)
+	SetOutput(stdout, stderr io.Writer)
+}
+
+// BatchUpgrader is implemented by providers that can upgrade several packages with one command
+type BatchUpgrader interface {
+	// UpgradePackages upgrades packages together. If it fails, some of the packages may have
+	// been upgraded; upgrade them one by one with UpgradePackage to find the failing ones.
+	UpgradePackages(ctx context.Context, packageIDs []string) error
+}
+
+// OptionsInstaller is implemented by providers that accept per-package options (PackageConfig.Options)
+type OptionsInstaller interface {
+	// InstallPackageWithOptions installs a package, passing options to the package manager
+	InstallPackageWithOptions(ctx context.Context, packageID string, options []string) error
+
+	// UpgradePackageWithOptions upgrades a package, passing the options that apply to upgrades
+	UpgradePackageWithOptions(ctx context.Context, packageID string, options []string) error
+}
+
+// VersionUpgrader is implemented by providers that install the registered version of a package
+// (PackageConfig.Version), so that an upgrade is only kept on other machines once it is recorded
+type VersionUpgrader interface {
+	// UpgradePackageVersion upgrades a package like UpgradePackage and returns the version now
+	// installed, for the caller to record in the upgraded profiles
+	UpgradePackageVersion(ctx context.Context, packageID string) (string, error)
+}
+
+// PackageResolver is implemented by providers that derive a package's ID and name from the name
+// and ID given to 'al package add'
+type PackageResolver interface {
+	// ResolvePackage returns the package ID and name to register; id is empty when not given
+	ResolvePackage(ctx context.Context, name, id string) (packageID, packageName string, err error)
+}
+
+// BackendSelector is implemented by providers that can run one of several tools (ProviderConfig.Backend)
+type BackendSelector interface {
+	// SetBackend selects the tool used by CheckInstalled, Install, and SetupConfig
+	SetBackend(backend string) error
+}
+
+// saveProviderVersion records the installed version of a provider in providers.json,
+// keeping the provider's other settings
+func saveProviderVersion(name, version string) error {
+	providerConfig, err := config.GetProvider(name)
+	if err != nil {
+		return err
+	}
+	if providerConfig == nil {
+		providerConfig = &config.ProviderConfig{Name: name}
+	}
+	providerConfig.InstalledAt = time.Now()
+	providerConfig.Version = version
+	return config.AddOrUpdateProvider(*providerConfig)
+}
+
+// saveProviderBackend records the installed version and the backend of a provider in providers.json,
+// keeping the provider's other settings
+func saveProviderBackend(name, version, backend string) error {
+	providerConfig, err := config.GetProvider(name)
+	if err != nil {
+		return err
+	}
+	if providerConfig == nil {
+		providerConfig = &config.ProviderConfig{Name: name}
+	}
+	providerConfig.InstalledAt = time.Now()
+	providerConfig.Version = version
+	providerConfig.Backend = backend
+	return config.AddOrUpdateProvider(*providerConfig)
+}
+
+// expandHome replaces a leading "~" of path with the home directory
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(home, strings.TrimPrefix(strings.TrimPrefix(path, "~"), "/")), nil
+}
+
+// registeredIDs returns the IDs of the packages registered with a provider, in any profile
+func registeredIDs(providerName string) (map[string]bool, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+	ids := make(map[string]bool)
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider == providerName {
+			ids[pkg.ID] = true
+		}
+	}
+	return ids, nil
+}
+
+// NormalizeID returns the canonical form of packageID for p
+func NormalizeID(p Provider, packageID string) string {
+	if n, ok := p.(IDNormalizer); ok {
+		return n.NormalizeID(packageID)
+	}
+	return packageID
+}
+
+// InstallPackage installs packageID with p, passing options if there are any
+func InstallPackage(ctx context.Context, p Provider, packageID string, options []string) error {
+	if len(options) == 0 {
+		return p.InstallPackage(ctx, packageID)
+	}
+	o, ok := p.(OptionsInstaller)
+	if !ok {
+		return fmt.Errorf("provider %s does not support package options", p.Name())
+	}
+	return o.InstallPackageWithOptions(ctx, packageID, options)
+}
+
+// ResolvePackage returns the package ID and name p registers for name and id. Without a
+// PackageResolver, the ID is id, or name when id is empty.
+func ResolvePackage(ctx context.Context, p Provider, name, id string) (string, string, error) {
+	if r, ok := p.(PackageResolver); ok {
+		return r.ResolvePackage(ctx, name, id)
+	}
+	if id == "" {
+		id = name
+	}
+	return id, name, nil
+}
+
+// UpgradePackage upgrades packageID with p, passing options if there are any
+func UpgradePackage(ctx context.Context, p Provider, packageID string, options []string) error {
+	if len(options) == 0 {
+		return p.UpgradePackage(ctx, packageID)
+	}
+	o, ok := p.(OptionsInstaller)
+	if !ok {
+		return fmt.Errorf("provider %s does not support package options", p.Name())
+	}
+	return o.UpgradePackageWithOptions(ctx, packageID, options)
 }
@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"testing"
+)
+
+func TestResolvePackage(t *testing.T) {
+	t.Setenv("AL_HOME", t.TempDir())
+
+	tests := []struct {
+		provider string
+		name     string
+		id       string
+		wantID   string
+		wantName string
+	}{
+		{provider: "brew", name: "slack", id: "cask:slack", wantID: "cask:slack", wantName: "slack"},
+		{provider: "mas", name: "Xcode", id: "497799835", wantID: "497799835", wantName: "Xcode"},
+		{provider: "manual", name: "tool", id: "ignored", wantID: "tool", wantName: "tool"},
+		{provider: "npm", name: "typescript@5", wantID: "typescript@5", wantName: "typescript"},
+		{provider: "npm", name: "ng", id: "@angular/cli", wantID: "@angular/cli", wantName: "@angular/cli"},
+		{provider: "pytool", name: "black[d]==24.1.0", wantID: "black[d]==24.1.0", wantName: "black"},
+		{provider: "cargo", name: "rg", id: "ripgrep", wantID: "ripgrep", wantName: "ripgrep"},
+		{provider: "go", name: "golang.org/x/tools/gopls", wantID: "golang.org/x/tools/gopls@latest", wantName: "gopls"},
+		{provider: "go", name: "go.uber.org/mock/mockgen@v0.4.0", wantID: "go.uber.org/mock/mockgen@v0.4.0", wantName: "mockgen"},
+		{provider: "vscode", name: "golang.go", wantID: "golang.go", wantName: "golang.go"},
+		{provider: "runtime", name: "node", wantID: "node@latest", wantName: "node@latest"},
+		{provider: "runtime", name: "python@3.12", wantID: "python@3.12", wantName: "python@3.12"},
+		{provider: "script", name: "dotfiles", wantID: "dotfiles", wantName: "dotfiles"},
+		{provider: "script", name: "dotfiles", id: "my-dotfiles", wantID: "my-dotfiles", wantName: "dotfiles"},
+		{provider: "git", name: "autosuggestions", id: "~/.zsh/zsh-autosuggestions/", wantID: "~/.zsh/zsh-autosuggestions", wantName: "autosuggestions"},
+		{provider: "binary", name: "fzf", wantID: "fzf", wantName: "fzf"},
+		{provider: "apt", name: "curl", wantID: "curl", wantName: "curl"},
+		{provider: "dnf", name: "curl", wantID: "curl", wantName: "curl"},
+		{provider: "flatpak", name: "org.mozilla.firefox", wantID: "org.mozilla.firefox", wantName: "org.mozilla.firefox"},
+		{provider: "flatpak", name: "firefox", id: "fedora:org.mozilla.firefox", wantID: "fedora:org.mozilla.firefox", wantName: "org.mozilla.firefox"},
+	}
+
+	for _, tt := range tests {
+		p, err := New(tt.provider)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, name, err := ResolvePackage(context.Background(), p, tt.name, tt.id)
+		if err != nil {
+			t.Errorf("%s %s (id %q): %v", tt.provider, tt.name, tt.id, err)
+			continue
+		}
+		if id != tt.wantID || name != tt.wantName {
+			t.Errorf("%s %s (id %q) = %s, %s; want %s, %s", tt.provider, tt.name, tt.id, id, name, tt.wantID, tt.wantName)
+		}
+	}
+
+	// A script package ID names a directory under scripts.d
+	for _, id := range []string{".", ".."} {
+		if _, _, err := ResolvePackage(context.Background(), NewScriptProvider(), "dotfiles", id); err == nil {
+			t.Errorf("script with ID %q: expected an error", id)
+		}
+	}
+
+	// A git package needs a destination path
+	if _, _, err := ResolvePackage(context.Background(), NewGitProvider(), "autosuggestions", ""); err == nil {
+		t.Error("git without a path: expected an error")
+	}
+}
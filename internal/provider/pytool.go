@@ -0,0 +1,420 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// Backends of the pytool provider
+const (
+	PyToolBackendPipx = "pipx"
+	PyToolBackendUv   = "uv"
+)
+
+// PyToolProvider implements the Provider interface for Python CLI tools installed in isolated
+// environments with pipx or uv tool. The backend is set in providers.json ("backend"); when it is
+// not set, uv is used if it is installed, then pipx.
+type PyToolProvider struct {
+	name    string
+	backend string
+	runner
+}
+
+// NewPyToolProvider creates a new pytool provider with the backend configured in providers.json
+func NewPyToolProvider() *PyToolProvider {
+	p := &PyToolProvider{name: "pytool", runner: newRunner("pytool")}
+	if providerConfig, err := config.GetProvider("pytool"); err == nil && providerConfig != nil {
+		p.backend = providerConfig.Backend
+	}
+	return p
+}
+
+// Name returns the provider name
+func (p *PyToolProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the requirement with optional extras and version (black[d]==24.1.0) as the
+// ID, named by the package name
+func (p *PyToolProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, PyToolPackageName(id), nil
+}
+
+// SetBackend selects the backend (pipx or uv)
+func (p *PyToolProvider) SetBackend(backend string) error {
+	if backend != PyToolBackendPipx && backend != PyToolBackendUv {
+		return fmt.Errorf("invalid backend for pytool: %s (must be pipx or uv)", backend)
+	}
+	p.backend = backend
+	return nil
+}
+
+// detectBackend returns the configured backend, or the first installed one of uv and pipx.
+// It returns "" when no backend is configured or installed.
+func (p *PyToolProvider) detectBackend(ctx context.Context) (string, error) {
+	if p.backend != "" {
+		return p.backend, nil
+	}
+	for _, backend := range []string{PyToolBackendUv, PyToolBackendPipx} {
+		if err := p.check(ctx, backend, "--version"); err == nil {
+			p.backend = backend
+			return backend, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+	return "", nil
+}
+
+// CheckInstalled checks if the backend is installed by running `pipx --version` or `uv --version`
+func (p *PyToolProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	backend, err := p.detectBackend(ctx)
+	if err != nil || backend == "" {
+		return false, err
+	}
+	if err := p.check(ctx, backend, "--version"); err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, the backend is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of the backend
+func (p *PyToolProvider) GetVersion(ctx context.Context) (string, error) {
+	backend, err := p.detectBackend(ctx)
+	if err != nil {
+		return "", err
+	}
+	if backend == "" {
+		return "", fmt.Errorf("neither uv nor pipx is installed")
+	}
+	output, err := p.output(ctx, backend, "--version")
+	if err != nil {
+		return "", err
+	}
+
+	// pipx prints "1.7.1", uv prints "uv 0.4.20 (Homebrew 2024-10-08)"
+	fields := strings.Fields(string(output))
+	if backend == PyToolBackendUv && len(fields) >= 2 {
+		return fields[1], nil
+	}
+	return strings.TrimSpace(string(output)), nil
+}
+
+// Install installs the backend (pipx unless uv was selected) using Homebrew
+func (p *PyToolProvider) Install(ctx context.Context) error {
+	// Check if the backend is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check pytool installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("%s is already installed", p.backend)
+	}
+
+	if p.backend == "" {
+		p.backend = PyToolBackendPipx
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install %s. Please install brew first using 'al provider add brew'", p.backend)
+	}
+
+	// Install the backend using brew
+	fmt.Fprintf(p.stdout, "Installing %s using brew...\n", p.backend)
+	if err := p.runWithRetry(ctx, "brew", "install", p.backend); err != nil {
+		return fmt.Errorf("failed to install %s: %w", p.backend, err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for pytool provider, recording the backend
+func (p *PyToolProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config, with the backend so that it does not change when another one is installed
+	if err := saveProviderBackend(p.name, version, p.backend); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns the backend, or an error if it is not installed
+func (p *PyToolProvider) ensureInstalled(ctx context.Context) (string, error) {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check pytool installation: %w", err)
+	}
+	if !installed {
+		return "", fmt.Errorf("pipx or uv is not installed. Please install it first using 'al provider add pytool'")
+	}
+	return p.backend, nil
+}
+
+// pyToolCommand returns the command and arguments running a tool subcommand with the backend:
+// `pipx <subcommand>` or `uv tool <subcommand>`
+func pyToolCommand(backend, subcommand string, args ...string) (string, []string) {
+	if backend == PyToolBackendUv {
+		return "uv", append([]string{"tool", subcommand}, args...)
+	}
+	return "pipx", append([]string{subcommand}, args...)
+}
+
+// pyRequirementRegex matches the name at the start of a requirement such as "black[d]==24.1.0"
+var pyRequirementRegex = regexp.MustCompile(`^\s*([A-Za-z0-9][A-Za-z0-9._-]*)`)
+
+// PyToolPackageName returns the package name of a pytool package ID, which is a requirement
+// specifier: "black" for "black[d]==24.1.0"
+func PyToolPackageName(packageID string) string {
+	if m := pyRequirementRegex.FindStringSubmatch(packageID); m != nil {
+		return m[1]
+	}
+	return strings.TrimSpace(packageID)
+}
+
+// NormalizeID returns the normalized package name (PEP 503: lowercase, runs of "-", "_", and "."
+// as "-"), so that "Black[d]==24.1" matches "black" as listed by pipx and uv
+func (p *PyToolProvider) NormalizeID(packageID string) string {
+	return normalizePyName(PyToolPackageName(packageID))
+}
+
+var pySeparatorRegex = regexp.MustCompile(`[-_.]+`)
+
+func normalizePyName(name string) string {
+	return pySeparatorRegex.ReplaceAllString(strings.ToLower(name), "-")
+}
+
+// InstallPackage installs a tool with `pipx install` or `uv tool install`
+// packageID is a requirement specifier with optional extras and version (e.g. "black[d]==24.1.0")
+func (p *PyToolProvider) InstallPackage(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Install
+	fmt.Fprintf(p.stdout, "Installing %s using %s...\n", packageID, backend)
+	name, args := pyToolCommand(backend, "install", packageID)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls a tool with `pipx uninstall` or `uv tool uninstall`
+func (p *PyToolProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	pkgName := PyToolPackageName(packageID)
+	fmt.Fprintf(p.stdout, "Uninstalling %s using %s...\n", pkgName, backend)
+	name, args := pyToolCommand(backend, "uninstall", pkgName)
+	if err := p.run(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", pkgName, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", pkgName)
+	return nil
+}
+
+// UpgradePackage upgrades a tool with `pipx upgrade` or `uv tool upgrade`. Both keep the version
+// specifier the tool was installed with.
+func (p *PyToolProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	pkgName := PyToolPackageName(packageID)
+	fmt.Fprintf(p.stdout, "Upgrading %s using %s...\n", pkgName, backend)
+	name, args := pyToolCommand(backend, "upgrade", pkgName)
+	if err := p.runWithRetry(ctx, name, args...); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", pkgName, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", pkgName)
+	return nil
+}
+
+// Upgrade upgrades the backend itself using brew
+func (p *PyToolProvider) Upgrade(ctx context.Context) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Check if brew is installed
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to upgrade %s. Please install brew first using 'al provider add brew'", backend)
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using brew...\n", backend)
+	if err := p.runWithRetry(ctx, "brew", "upgrade", backend); err != nil {
+		return fmt.Errorf("failed to upgrade %s: %w", backend, err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", backend)
+	return nil
+}
+
+// SearchPackage is not supported: PyPI has no search API
+func (p *PyToolProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	return nil, fmt.Errorf("search is not supported by pytool (PyPI has no search API)")
+}
+
+// pipxList is the output of `pipx list --json`
+type pipxList struct {
+	Venvs map[string]struct {
+		Metadata struct {
+			MainPackage struct {
+				Package        string `json:"package"`
+				PackageOrURL   string `json:"package_or_url"`
+				PackageVersion string `json:"package_version"`
+			} `json:"main_package"`
+		} `json:"metadata"`
+	} `json:"venvs"`
+}
+
+// ListInstalled lists the installed tools with `pipx list --json` or `uv tool list`. With pipx,
+// the ID is the requirement the tool was installed with (e.g. "black[d]==24.1.0").
+func (p *PyToolProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	backend, err := p.detectBackend(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []InstalledPackage
+	if backend == PyToolBackendUv {
+		output, err := p.output(ctx, "uv", "tool", "list")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed tools: %w", err)
+		}
+		// uv tool list prints "black v24.1.0" followed by "- black" lines for the executables
+		for _, line := range strings.Split(string(output), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) < 2 || strings.HasPrefix(fields[0], "-") || !strings.HasPrefix(fields[1], "v") {
+				continue
+			}
+			packages = append(packages, InstalledPackage{ID: fields[0], Name: fields[0], Version: strings.TrimPrefix(fields[1], "v")})
+		}
+	} else {
+		output, err := p.output(ctx, "pipx", "list", "--json")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed tools: %w", err)
+		}
+		var list pipxList
+		if err := json.Unmarshal(output, &list); err != nil {
+			return nil, fmt.Errorf("failed to parse pipx list output: %w", err)
+		}
+		for venv, info := range list.Venvs {
+			main := info.Metadata.MainPackage
+			name := main.Package
+			if name == "" {
+				name = venv
+			}
+			id := main.PackageOrURL
+			if id == "" {
+				id = name
+			}
+			packages = append(packages, InstalledPackage{ID: id, Name: name, Version: main.PackageVersion})
+		}
+	}
+
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// uvOutdatedRegex matches a line of `uv tool list --outdated`: "black v24.1.0 [latest: 24.2.0]"
+var uvOutdatedRegex = regexp.MustCompile(`^(\S+)\s+v(\S+)\s+\[latest:\s*([^\]\s]+)\]`)
+
+// ListOutdated lists the tools with a newer version: with `uv tool list --outdated`, or for pipx
+// by asking pip in each tool's environment (`pipx runpip <tool> list --outdated`)
+func (p *PyToolProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	backend, err := p.detectBackend(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var packages []OutdatedPackage
+	if backend == PyToolBackendUv {
+		output, err := p.output(ctx, "uv", "tool", "list", "--outdated")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list outdated tools: %w", err)
+		}
+		for _, line := range strings.Split(string(output), "\n") {
+			if m := uvOutdatedRegex.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+				packages = append(packages, OutdatedPackage{ID: m[1], Name: m[1], CurrentVersion: m[2], LatestVersion: m[3]})
+			}
+		}
+		return packages, nil
+	}
+
+	installed, err := p.ListInstalled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, tool := range installed {
+		output, err := p.output(ctx, "pipx", "runpip", tool.Name, "list", "--outdated", "--format=json")
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, err
+			}
+			continue
+		}
+		var outdated []struct {
+			Name          string `json:"name"`
+			Version       string `json:"version"`
+			LatestVersion string `json:"latest_version"`
+		}
+		if err := json.Unmarshal(output, &outdated); err != nil {
+			continue
+		}
+		for _, dep := range outdated {
+			// Only the tool itself counts, not its dependencies
+			if normalizePyName(dep.Name) == normalizePyName(tool.Name) {
+				packages = append(packages, OutdatedPackage{ID: tool.ID, Name: tool.Name, CurrentVersion: dep.Version, LatestVersion: dep.LatestVersion})
+			}
+		}
+	}
+	return packages, nil
+}
@@ -0,0 +1,52 @@
+package provider
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Names lists the available providers
+var Names = []string{"brew", "mas", "manual", "npm", "pytool", "cargo", "go", "vscode", "runtime", "script", "git", "binary", "apt", "dnf", "flatpak"}
+
+// New returns the provider with the given name
+func New(name string) (Provider, error) {
+	switch name {
+	case "brew":
+		return NewBrewProvider(), nil
+	case "mas":
+		return NewMasProvider(), nil
+	case "manual":
+		return NewManualProvider(), nil
+	case "npm":
+		return NewNpmProvider(), nil
+	case "pytool":
+		return NewPyToolProvider(), nil
+	case "cargo":
+		return NewCargoProvider(), nil
+	case "go":
+		return NewGoProvider(), nil
+	case "vscode":
+		return NewVSCodeProvider(), nil
+	case "runtime":
+		return NewRuntimeProvider(), nil
+	case "script":
+		return NewScriptProvider(), nil
+	case "git":
+		return NewGitProvider(), nil
+	case "binary":
+		return NewBinaryProvider(), nil
+	case "apt":
+		return NewAptProvider(), nil
+	case "dnf":
+		return NewDnfProvider(), nil
+	case "flatpak":
+		return NewFlatpakProvider(), nil
+	default:
+		return nil, fmt.Errorf("unsupported provider: %s", name)
+	}
+}
+
+// AvailableNames returns the available providers as a comma-separated list for messages
+func AvailableNames() string {
+	return strings.Join(Names, ", ")
+}
@@ -0,0 +1,95 @@
+package provider
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// fakeFailingTool puts a tool on PATH that succeeds for --version and fails otherwise, logging each
+// call, and a sudo that runs its arguments. It returns a function reading the logged calls.
+func fakeFailingTool(t *testing.T, tool string) func() []string {
+	t.Helper()
+	bin := t.TempDir()
+	log := filepath.Join(bin, "calls.log")
+	scripts := map[string]string{
+		tool:   "#!/bin/sh\n[ \"$1\" = --version ] && { echo \"" + tool + " 1.0\"; exit 0; }\necho \"$*\" >> " + log + "\nexit 1\n",
+		"sudo": "#!/bin/sh\nexec \"$@\"\n",
+	}
+	for name, script := range scripts {
+		if err := os.WriteFile(filepath.Join(bin, name), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+	t.Setenv("AL_HOME", t.TempDir())
+
+	backoff := retryBackoff
+	retryBackoff = 0
+	t.Cleanup(func() { retryBackoff = backoff })
+
+	return func() []string {
+		data, err := os.ReadFile(log)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		os.Remove(log)
+		return strings.Split(strings.TrimSpace(string(data)), "\n")
+	}
+}
+
+// TestSystemPackageRetries checks that downloads are retried and removals run once
+func TestSystemPackageRetries(t *testing.T) {
+	attempts := DefaultRetries + 1
+	tests := []struct {
+		tool      string
+		provider  func() Provider
+		install   int
+		uninstall int
+	}{
+		{tool: "apt-get", provider: func() Provider { return NewAptProvider() }, install: attempts, uninstall: 1},
+		{tool: "dnf", provider: func() Provider { return NewDnfProvider() }, install: attempts, uninstall: 1},
+		{tool: "flatpak", provider: func() Provider { return NewFlatpakProvider() }, install: attempts, uninstall: 1},
+	}
+
+	ctx := context.Background()
+	for _, tt := range tests {
+		t.Run(tt.tool, func(t *testing.T) {
+			calls := fakeFailingTool(t, tt.tool)
+			p := tt.provider()
+			p.(OutputSetter).SetOutput(io.Discard, io.Discard)
+
+			if err := p.InstallPackage(ctx, "pkg"); err == nil {
+				t.Error("InstallPackage: expected an error")
+			}
+			if got := len(calls()); got != tt.install {
+				t.Errorf("install ran %d time(s), want %d", got, tt.install)
+			}
+
+			if err := p.UninstallPackage(ctx, "pkg"); err == nil {
+				t.Error("UninstallPackage: expected an error")
+			}
+			if got := len(calls()); got != tt.uninstall {
+				t.Errorf("uninstall ran %d time(s), want %d", got, tt.uninstall)
+			}
+		})
+	}
+
+	t.Run("flatpak remote-add", func(t *testing.T) {
+		calls := fakeFailingTool(t, "flatpak")
+		p := NewFlatpakProvider()
+		p.SetOutput(io.Discard, io.Discard)
+		if err := p.SetupConfig(ctx); err == nil {
+			t.Error("SetupConfig: expected an error")
+		}
+		if got := len(calls()); got != 1 {
+			t.Errorf("remote-add ran %d time(s), want 1", got)
+		}
+	})
+}
@@ -0,0 +1,646 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+	"github.com/kkato1030/al/internal/version"
+)
+
+// Backends of the runtime provider
+const (
+	RuntimeBackendMise = "mise"
+	RuntimeBackendAsdf = "asdf"
+)
+
+// runtimeActivation is the shell.d snippet that activates each backend, by shell extension
+var runtimeActivation = map[string]map[string]string{
+	RuntimeBackendMise: {
+		".zsh":  `eval "$(mise activate zsh)"`,
+		".bash": `eval "$(mise activate bash)"`,
+	},
+	RuntimeBackendAsdf: {
+		".zsh":  `export PATH="${ASDF_DATA_DIR:-$HOME/.asdf}/shims:$PATH"`,
+		".bash": `export PATH="${ASDF_DATA_DIR:-$HOME/.asdf}/shims:$PATH"`,
+	},
+}
+
+// RuntimeProvider implements the Provider interface for language runtime versions (node, python,
+// go, java, ...) installed with mise or asdf. A runtime package's ID is "runtime@version", where the
+// version may be a prefix ("node@20") or "latest". The backend is set in providers.json ("backend");
+// when it is not set, mise is used if it is installed, then asdf.
+type RuntimeProvider struct {
+	name    string
+	backend string
+	runner
+}
+
+// NewRuntimeProvider creates a new runtime provider with the backend configured in providers.json
+func NewRuntimeProvider() *RuntimeProvider {
+	p := &RuntimeProvider{name: "runtime", runner: newRunner("runtime")}
+	if providerConfig, err := config.GetProvider("runtime"); err == nil && providerConfig != nil {
+		p.backend = providerConfig.Backend
+	}
+	return p
+}
+
+// Name returns the provider name
+func (p *RuntimeProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the runtime with a version (node@20, "@latest" when there is none)
+// as the ID and the name
+func (p *RuntimeProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	id = RuntimePackageID(id)
+	return id, id, nil
+}
+
+// SetBackend selects the backend (mise or asdf)
+func (p *RuntimeProvider) SetBackend(backend string) error {
+	if backend != RuntimeBackendMise && backend != RuntimeBackendAsdf {
+		return fmt.Errorf("invalid backend for runtime: %s (must be mise or asdf)", backend)
+	}
+	p.backend = backend
+	return nil
+}
+
+// detectBackend returns the configured backend, or the first installed one of mise and asdf.
+// It returns "" when no backend is configured or installed.
+func (p *RuntimeProvider) detectBackend(ctx context.Context) (string, error) {
+	if p.backend != "" {
+		return p.backend, nil
+	}
+	for _, backend := range []string{RuntimeBackendMise, RuntimeBackendAsdf} {
+		if err := p.check(ctx, backend, "--version"); err == nil {
+			p.backend = backend
+			return backend, nil
+		}
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+	}
+	return "", nil
+}
+
+// RuntimeSpec splits a runtime package ID into the runtime and the requested version
+// ("node", "20" for "node@20"). The version is "latest" when the ID has none.
+func RuntimeSpec(packageID string) (runtime, requested string) {
+	runtime, requested, _ = strings.Cut(packageID, "@")
+	if requested == "" {
+		requested = "latest"
+	}
+	return runtime, requested
+}
+
+// RuntimePackageID returns the ID of a runtime package written with or without a version
+// ("node@latest" for "node")
+func RuntimePackageID(spec string) string {
+	runtime, requested := RuntimeSpec(spec)
+	return runtime + "@" + requested
+}
+
+// NormalizeID returns the ID with an explicit version, so that "node" matches "node@latest"
+func (p *RuntimeProvider) NormalizeID(packageID string) string {
+	return RuntimePackageID(packageID)
+}
+
+// matchesRequest reports whether an installed version satisfies a requested version: the same
+// version, a version starting with a requested prefix ("20.11.1" for "20"), or any version for
+// "latest" and other aliases such as "lts"
+func matchesRequest(installed, requested string) bool {
+	if requested == "latest" || requested == "lts" {
+		return true
+	}
+	return installed == requested || strings.HasPrefix(installed, requested+".")
+}
+
+// CheckInstalled checks if the backend is installed by running `mise --version` or `asdf --version`
+func (p *RuntimeProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	backend, err := p.detectBackend(ctx)
+	if err != nil || backend == "" {
+		return false, err
+	}
+	if err := p.check(ctx, backend, "--version"); err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, the backend is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of the backend
+func (p *RuntimeProvider) GetVersion(ctx context.Context) (string, error) {
+	backend, err := p.detectBackend(ctx)
+	if err != nil {
+		return "", err
+	}
+	if backend == "" {
+		return "", fmt.Errorf("neither mise nor asdf is installed")
+	}
+	output, err := p.output(ctx, backend, "--version")
+	if err != nil {
+		return "", err
+	}
+
+	// mise prints "2024.9.5 macos-arm64 (2024-09-20)", asdf prints "asdf version 0.16.0" or "v0.14.1-f00f759"
+	return versionField(string(output)), nil
+}
+
+// Install installs the backend (mise unless asdf was selected) using Homebrew
+func (p *RuntimeProvider) Install(ctx context.Context) error {
+	// Check if the backend is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check runtime installation: %w", err)
+	}
+	if installed {
+		return fmt.Errorf("%s is already installed", p.backend)
+	}
+
+	if p.backend == "" {
+		p.backend = RuntimeBackendMise
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install %s. Please install brew first using 'al provider add brew'", p.backend)
+	}
+
+	// Install the backend using brew
+	fmt.Fprintf(p.stdout, "Installing %s using brew...\n", p.backend)
+	if err := p.runWithRetry(ctx, "brew", "install", p.backend); err != nil {
+		return fmt.Errorf("failed to install %s: %w", p.backend, err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for runtime provider, recording the backend and writing
+// the shell.d snippet that activates it
+func (p *RuntimeProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config, with the backend so that it does not change when another one is installed
+	if err := saveProviderBackend(p.name, version, p.backend); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	if err := p.writeActivationSnippets(); err != nil {
+		return fmt.Errorf("failed to write shell.d snippet: %w", err)
+	}
+
+	return nil
+}
+
+// writeActivationSnippets writes the shell.d snippets that activate the backend, so that
+// `al activate` includes `mise activate` (or asdf's shims), and removes those of the other backend
+func (p *RuntimeProvider) writeActivationSnippets() error {
+	for backend, snippets := range runtimeActivation {
+		if backend != p.backend {
+			if err := config.RemoveShellPackageDir(backend, p.name); err != nil {
+				return err
+			}
+			continue
+		}
+		for ext, content := range snippets {
+			if err := config.WriteShellSnippet(backend, p.name, ext, content); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ensureInstalled returns the backend, or an error if it is not installed
+func (p *RuntimeProvider) ensureInstalled(ctx context.Context) (string, error) {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to check runtime installation: %w", err)
+	}
+	if !installed {
+		return "", fmt.Errorf("mise or asdf is not installed. Please install it first using 'al provider add runtime'")
+	}
+	return p.backend, nil
+}
+
+// listVersions returns the installed versions of every runtime, in ascending order
+func (p *RuntimeProvider) listVersions(ctx context.Context, backend string) (map[string][]string, error) {
+	versions := make(map[string][]string)
+	if backend == RuntimeBackendAsdf {
+		output, err := p.output(ctx, "asdf", "plugin", "list")
+		if err != nil {
+			// asdf exits with an error when no plugins are installed
+			return versions, nil
+		}
+		for _, runtime := range strings.Fields(string(output)) {
+			list, err := p.output(ctx, "asdf", "list", runtime)
+			if err != nil {
+				continue
+			}
+			versions[runtime] = parseAsdfList(string(list))
+		}
+	} else {
+		output, err := p.output(ctx, "mise", "ls", "--json", "--installed")
+		if err != nil {
+			return nil, fmt.Errorf("failed to list installed runtimes: %w", err)
+		}
+		if versions, err = parseMiseLs(output); err != nil {
+			return nil, err
+		}
+	}
+
+	for runtime := range versions {
+		sort.Slice(versions[runtime], func(i, j int) bool {
+			return version.Compare(versions[runtime][i], versions[runtime][j]) < 0
+		})
+	}
+	return versions, nil
+}
+
+// parseMiseLs parses `mise ls --json`: installed versions by runtime
+func parseMiseLs(output []byte) (map[string][]string, error) {
+	var tools map[string][]struct {
+		Version   string `json:"version"`
+		Installed bool   `json:"installed"`
+	}
+	if err := json.Unmarshal(output, &tools); err != nil {
+		return nil, fmt.Errorf("failed to parse mise ls output: %w", err)
+	}
+
+	versions := make(map[string][]string)
+	for runtime, entries := range tools {
+		for _, entry := range entries {
+			if entry.Installed && entry.Version != "" {
+				versions[runtime] = append(versions[runtime], entry.Version)
+			}
+		}
+	}
+	return versions, nil
+}
+
+// parseAsdfList parses `asdf list <runtime>`: one version per line, the current one marked with "*"
+func parseAsdfList(output string) []string {
+	var versions []string
+	for _, line := range strings.Split(output, "\n") {
+		v := strings.TrimPrefix(strings.TrimSpace(line), "*")
+		if v == "" || strings.Contains(v, " ") {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	return versions
+}
+
+// matchingVersions returns the installed versions that satisfy the package's requested version
+func matchingVersions(versions map[string][]string, packageID string) []string {
+	runtime, requested := RuntimeSpec(packageID)
+	var matching []string
+	for _, v := range versions[runtime] {
+		if matchesRequest(v, requested) {
+			matching = append(matching, v)
+		}
+	}
+	return matching
+}
+
+// latest returns the latest available version that satisfies the package's requested version
+func (p *RuntimeProvider) latest(ctx context.Context, backend, packageID string) (string, error) {
+	runtime, requested := RuntimeSpec(packageID)
+	var output []byte
+	var err error
+	switch {
+	case backend == RuntimeBackendAsdf && requested == "latest":
+		output, err = p.output(ctx, "asdf", "latest", runtime)
+	case backend == RuntimeBackendAsdf:
+		output, err = p.output(ctx, "asdf", "latest", runtime, requested)
+	case requested == "latest":
+		output, err = p.output(ctx, "mise", "latest", runtime)
+	default:
+		output, err = p.output(ctx, "mise", "latest", runtime+"@"+requested)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to check the latest version of %s: %w", packageID, err)
+	}
+	latest := strings.TrimSpace(string(output))
+	if latest == "" {
+		return "", fmt.Errorf("no version of %s found", packageID)
+	}
+	return latest, nil
+}
+
+// installVersion installs a version of a runtime, adding the asdf plugin first if needed
+func (p *RuntimeProvider) installVersion(ctx context.Context, backend, runtime, v string) error {
+	if backend == RuntimeBackendMise {
+		return p.runWithRetry(ctx, "mise", "install", runtime+"@"+v)
+	}
+
+	if output, err := p.output(ctx, "asdf", "plugin", "list"); err != nil || !containsField(string(output), runtime) {
+		if err := p.runWithRetry(ctx, "asdf", "plugin", "add", runtime); err != nil {
+			return fmt.Errorf("failed to add asdf plugin %s: %w", runtime, err)
+		}
+	}
+	return p.runWithRetry(ctx, "asdf", "install", runtime, v)
+}
+
+// containsField reports whether one of the whitespace-separated fields of s is field
+func containsField(s, field string) bool {
+	for _, f := range strings.Fields(s) {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// InstallPackage installs a runtime version with `mise install` or `asdf install`. With asdf, a
+// version prefix is resolved to the latest matching version first.
+func (p *RuntimeProvider) InstallPackage(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	runtime, requested := RuntimeSpec(packageID)
+	v := requested
+	if backend == RuntimeBackendAsdf {
+		if v, err = p.latest(ctx, backend, packageID); err != nil {
+			return fmt.Errorf("failed to install package %s: %w", packageID, err)
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Installing %s@%s using %s...\n", runtime, v, backend)
+	if err := p.installVersion(ctx, backend, runtime, v); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls every installed version that satisfies the package's requested version
+func (p *RuntimeProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+	versions, err := p.listVersions(ctx, backend)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	runtime, _ := RuntimeSpec(packageID)
+	matching := matchingVersions(versions, packageID)
+	if len(matching) == 0 {
+		fmt.Fprintf(p.stdout, "%s is not installed\n", packageID)
+		return nil
+	}
+	for _, v := range matching {
+		fmt.Fprintf(p.stdout, "Uninstalling %s@%s using %s...\n", runtime, v, backend)
+		if backend == RuntimeBackendAsdf {
+			err = p.run(ctx, "asdf", "uninstall", runtime, v)
+		} else {
+			err = p.run(ctx, "mise", "uninstall", runtime+"@"+v)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage installs the latest version that satisfies the package's requested version
+// ("node@20" gets the latest 20.x). Older versions are kept.
+func (p *RuntimeProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+	latest, err := p.latest(ctx, backend, packageID)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	versions, err := p.listVersions(ctx, backend)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+
+	runtime, _ := RuntimeSpec(packageID)
+	for _, v := range versions[runtime] {
+		if v == latest {
+			fmt.Fprintf(p.stdout, "%s is up to date (%s)\n", packageID, latest)
+			return nil
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s to %s using %s...\n", packageID, latest, backend)
+	if err := p.installVersion(ctx, backend, runtime, latest); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", packageID)
+	return nil
+}
+
+// SetGlobal makes the package's version the global default of its runtime: `mise use --global`, or
+// `asdf set --home` (`asdf global` before asdf 0.16) with the latest installed matching version
+func (p *RuntimeProvider) SetGlobal(ctx context.Context, packageID string) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	runtime, requested := RuntimeSpec(packageID)
+	fmt.Fprintf(p.stdout, "Setting the global %s to %s using %s...\n", runtime, requested, backend)
+	if backend == RuntimeBackendMise {
+		if err := p.run(ctx, "mise", "use", "--global", packageID); err != nil {
+			return fmt.Errorf("failed to set the global version of %s: %w", runtime, err)
+		}
+		return nil
+	}
+
+	versions, err := p.listVersions(ctx, backend)
+	if err != nil {
+		return err
+	}
+	matching := matchingVersions(versions, packageID)
+	if len(matching) == 0 {
+		return fmt.Errorf("%s is not installed", packageID)
+	}
+	v := matching[len(matching)-1]
+	if err := p.run(ctx, "asdf", "set", "--home", runtime, v); err != nil {
+		if err := p.run(ctx, "asdf", "global", runtime, v); err != nil {
+			return fmt.Errorf("failed to set the global version of %s: %w", runtime, err)
+		}
+	}
+	return nil
+}
+
+// Upgrade upgrades the backend using brew
+func (p *RuntimeProvider) Upgrade(ctx context.Context) error {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return err
+	}
+
+	// Check if brew is installed
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to upgrade %s. Please install brew first using 'al provider add brew'", backend)
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using brew...\n", backend)
+	if err := p.runWithRetry(ctx, "brew", "upgrade", backend); err != nil {
+		return fmt.Errorf("failed to upgrade %s: %w", backend, err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", backend)
+	return nil
+}
+
+// SearchPackage searches the runtimes the backend can install (`mise registry` or `asdf plugin list all`)
+func (p *RuntimeProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var output []byte
+	if backend == RuntimeBackendAsdf {
+		output, err = p.output(ctx, "asdf", "plugin", "list", "all")
+	} else {
+		output, err = p.output(ctx, "mise", "registry")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to search runtimes: %w", err)
+	}
+
+	results := []SearchResult{}
+	for _, line := range strings.Split(string(output), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || !strings.Contains(fields[0], query) {
+			continue
+		}
+		results = append(results, SearchResult{ID: fields[0], Name: fields[0]})
+	}
+	return results, nil
+}
+
+// ListInstalled lists the registered runtime packages with the latest installed version that
+// satisfies them, and every other installed version as "runtime@version"
+func (p *RuntimeProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := p.listVersions(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+
+	var packages []InstalledPackage
+	seen := make(map[string]bool)
+	covered := make(map[string]bool)
+	for _, pkg := range packagesConfig.Packages {
+		id := RuntimePackageID(pkg.ID)
+		if pkg.Provider != p.name || seen[id] {
+			continue
+		}
+		seen[id] = true
+		matching := matchingVersions(versions, id)
+		if len(matching) == 0 {
+			continue
+		}
+		runtime, _ := RuntimeSpec(id)
+		for _, v := range matching {
+			covered[runtime+"@"+v] = true
+		}
+		packages = append(packages, InstalledPackage{ID: id, Name: id, Version: matching[len(matching)-1]})
+	}
+	for runtime, list := range versions {
+		for _, v := range list {
+			id := runtime + "@" + v
+			if !covered[id] && !seen[id] {
+				packages = append(packages, InstalledPackage{ID: id, Name: id, Version: v})
+			}
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated lists the registered runtime packages whose latest matching version is not installed
+func (p *RuntimeProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	backend, err := p.ensureInstalled(ctx)
+	if err != nil {
+		return nil, err
+	}
+	versions, err := p.listVersions(ctx, backend)
+	if err != nil {
+		return nil, err
+	}
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+
+	var packages []OutdatedPackage
+	seen := make(map[string]bool)
+	for _, pkg := range packagesConfig.Packages {
+		id := RuntimePackageID(pkg.ID)
+		if pkg.Provider != p.name || seen[id] {
+			continue
+		}
+		seen[id] = true
+		matching := matchingVersions(versions, id)
+		if len(matching) == 0 {
+			continue
+		}
+		latest, err := p.latest(ctx, backend, id)
+		if err != nil {
+			return nil, err
+		}
+		current := matching[len(matching)-1]
+		if version.Compare(latest, current) > 0 {
+			packages = append(packages, OutdatedPackage{ID: id, Name: id, CurrentVersion: current, LatestVersion: latest})
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
@@ -0,0 +1,227 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// ErrNoCheckScript is returned by ScriptProvider.IsInstalled for a package without an is-installed script
+var ErrNoCheckScript = errors.New("no is-installed script")
+
+// ScriptProvider implements the Provider interface for packages installed, uninstalled, upgraded,
+// and checked by their own shell scripts, stored in ~/.al/scripts.d/<id>/
+type ScriptProvider struct {
+	name string
+	runner
+}
+
+// NewScriptProvider creates a new script provider
+func NewScriptProvider() *ScriptProvider {
+	return &ScriptProvider{name: "script", runner: newRunner("script")}
+}
+
+// Name returns the provider name
+func (p *ScriptProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage uses the name as the ID unless one is given. The ID names the package's
+// scripts.d directory, so "." and ".." are rejected.
+func (p *ScriptProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	if err := config.ValidateScriptPackageID(id); err != nil {
+		return "", "", err
+	}
+	return id, name, nil
+}
+
+// CheckInstalled checks if sh, which runs the scripts, is available
+func (p *ScriptProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, "sh", "-c", "exit 0")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, sh is not available
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns an empty version: the script provider has none
+func (p *ScriptProvider) GetVersion(ctx context.Context) (string, error) {
+	return "", nil
+}
+
+// Install returns an error: sh is part of the system and cannot be installed by al
+func (p *ScriptProvider) Install(ctx context.Context) error {
+	return fmt.Errorf("sh is required to run scripts")
+}
+
+// SetupConfig sets up the configuration for script provider
+func (p *ScriptProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Add provider to config
+	if err := saveProviderVersion(p.name, ""); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// scriptPath returns the path of a package's script, or "" if the package does not have it
+func scriptPath(packageID, kind string) (string, error) {
+	exists, err := config.ScriptExists(packageID, kind)
+	if err != nil || !exists {
+		return "", err
+	}
+	return config.GetScriptPath(packageID, kind)
+}
+
+// IsInstalled runs the package's is-installed script: exit status 0 means installed, and the first
+// line it prints, if any, is the installed version. It returns ErrNoCheckScript if there is no such script.
+func (p *ScriptProvider) IsInstalled(ctx context.Context, packageID string) (installed bool, version string, err error) {
+	path, err := scriptPath(packageID, config.ScriptIsInstalled)
+	if err != nil {
+		return false, "", err
+	}
+	if path == "" {
+		return false, "", ErrNoCheckScript
+	}
+
+	output, err := p.output(ctx, "sh", path)
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return false, "", nil
+		}
+		return false, "", fmt.Errorf("failed to run is-installed script of %s: %w", packageID, err)
+	}
+	version, _, _ = strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return true, strings.TrimSpace(version), nil
+}
+
+// InstallPackage runs the package's install script, unless its is-installed script reports that it
+// is already installed
+func (p *ScriptProvider) InstallPackage(ctx context.Context, packageID string) error {
+	path, err := scriptPath(packageID, config.ScriptInstall)
+	if err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+	if path == "" {
+		return fmt.Errorf("package %s has no install script. Add one with 'al package add %s -p script --install-script ...'", packageID, packageID)
+	}
+
+	if installed, _, err := p.IsInstalled(ctx, packageID); err == nil && installed {
+		fmt.Fprintf(p.stdout, "%s is already installed\n", packageID)
+		return nil
+	}
+
+	fmt.Fprintf(p.stdout, "Installing %s using its install script...\n", packageID)
+	if err := p.run(ctx, "sh", path); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage runs the package's uninstall script. Without one, the package is left installed.
+func (p *ScriptProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	path, err := scriptPath(packageID, config.ScriptUninstall)
+	if err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+	if path == "" {
+		fmt.Fprintf(p.stdout, "%s has no uninstall script. Please uninstall it yourself.\n", packageID)
+		return nil
+	}
+
+	fmt.Fprintf(p.stdout, "Uninstalling %s using its uninstall script...\n", packageID)
+	if err := p.run(ctx, "sh", path); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", packageID)
+	return nil
+}
+
+// UpgradePackage runs the package's upgrade script. Without one, the package is not upgraded.
+func (p *ScriptProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	path, err := scriptPath(packageID, config.ScriptUpgrade)
+	if err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+	if path == "" {
+		fmt.Fprintf(p.stdout, "%s has no upgrade script; skipped\n", packageID)
+		return nil
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using its upgrade script...\n", packageID)
+	if err := p.run(ctx, "sh", path); err != nil {
+		return fmt.Errorf("failed to upgrade package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", packageID)
+	return nil
+}
+
+// Upgrade does nothing: the script provider has nothing to upgrade
+func (p *ScriptProvider) Upgrade(ctx context.Context) error {
+	fmt.Fprintln(p.stdout, "script provider has nothing to upgrade")
+	return nil
+}
+
+// SearchPackage returns an empty result: script packages are defined by their scripts
+func (p *ScriptProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	return []SearchResult{}, nil
+}
+
+// ListInstalled runs the is-installed script of every registered script package and lists the
+// installed ones. Packages without an is-installed script are left out.
+func (p *ScriptProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	packagesConfig, err := config.LoadPackagesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load packages config: %w", err)
+	}
+
+	seen := make(map[string]bool)
+	var packages []InstalledPackage
+	for _, pkg := range packagesConfig.Packages {
+		if pkg.Provider != p.name || seen[pkg.ID] {
+			continue
+		}
+		seen[pkg.ID] = true
+		installed, version, err := p.IsInstalled(ctx, pkg.ID)
+		if errors.Is(err, ErrNoCheckScript) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if installed {
+			packages = append(packages, InstalledPackage{ID: pkg.ID, Name: pkg.Name, Version: version})
+		}
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated returns an empty result: scripts cannot report updates
+func (p *ScriptProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	return []OutdatedPackage{}, nil
+}
@@ -0,0 +1,289 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// DefaultVSCodeCLI is the editor CLI the vscode provider runs when no backend is configured
+const DefaultVSCodeCLI = "code"
+
+// vscodeCasks maps editor CLIs to the brew casks that install them
+var vscodeCasks = map[string]string{
+	"code":          "visual-studio-code",
+	"code-insiders": "visual-studio-code@insiders",
+	"cursor":        "cursor",
+	"codium":        "vscodium",
+}
+
+// VSCodeProvider implements the Provider interface for editor extensions installed with the VS Code
+// CLI. The CLI is set in providers.json ("backend"), so that forks such as cursor and codium work too.
+type VSCodeProvider struct {
+	name string
+	cli  string
+	runner
+}
+
+// NewVSCodeProvider creates a new vscode provider with the CLI configured in providers.json
+func NewVSCodeProvider() *VSCodeProvider {
+	p := &VSCodeProvider{name: "vscode", cli: DefaultVSCodeCLI, runner: newRunner("vscode")}
+	if providerConfig, err := config.GetProvider("vscode"); err == nil && providerConfig != nil && providerConfig.Backend != "" {
+		p.cli = providerConfig.Backend
+	}
+	return p
+}
+
+// Name returns the provider name
+func (p *VSCodeProvider) Name() string {
+	return p.name
+}
+
+// ResolvePackage returns the extension ID (publisher.name), optionally with a version, as the ID and the name
+func (p *VSCodeProvider) ResolvePackage(ctx context.Context, name, id string) (string, string, error) {
+	if id == "" {
+		id = name
+	}
+	return id, id, nil
+}
+
+// SetBackend selects the editor CLI (code, cursor, codium, or the path of another compatible CLI)
+func (p *VSCodeProvider) SetBackend(cli string) error {
+	if strings.TrimSpace(cli) == "" {
+		return fmt.Errorf("invalid backend for vscode: the editor CLI must not be empty")
+	}
+	p.cli = cli
+	return nil
+}
+
+// NormalizeID returns the extension ID in lowercase without a version ("ms-python.python" for
+// "MS-Python.python@2024.14.1"), as extension IDs are case-insensitive
+func (p *VSCodeProvider) NormalizeID(packageID string) string {
+	id, _, _ := strings.Cut(packageID, "@")
+	return strings.ToLower(id)
+}
+
+// CheckInstalled checks if the editor CLI is installed by running `code --version`
+func (p *VSCodeProvider) CheckInstalled(ctx context.Context) (bool, error) {
+	err := p.check(ctx, p.cli, "--version")
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, err
+		}
+		// If command fails, the editor CLI is not installed
+		return false, nil
+	}
+	return true, nil
+}
+
+// GetVersion returns the version of the editor (the first line of `code --version`)
+func (p *VSCodeProvider) GetVersion(ctx context.Context) (string, error) {
+	output, err := p.output(ctx, p.cli, "--version")
+	if err != nil {
+		return "", err
+	}
+
+	version, _, _ := strings.Cut(strings.TrimSpace(string(output)), "\n")
+	return strings.TrimSpace(version), nil
+}
+
+// cask returns the brew cask of the editor CLI
+func (p *VSCodeProvider) cask() (string, error) {
+	cask, ok := vscodeCasks[filepath.Base(p.cli)]
+	if !ok {
+		return "", fmt.Errorf("al cannot install %s with brew. Install the editor and its CLI manually", p.cli)
+	}
+	return cask, nil
+}
+
+// Install installs the editor using a Homebrew cask
+func (p *VSCodeProvider) Install(ctx context.Context) error {
+	// Check if the editor CLI is already installed
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check %s installation: %w", p.cli, err)
+	}
+	if installed {
+		return fmt.Errorf("%s is already installed", p.cli)
+	}
+
+	cask, err := p.cask()
+	if err != nil {
+		return err
+	}
+
+	// Check if brew is installed first
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to install %s. Please install brew first using 'al provider add brew'", p.cli)
+	}
+
+	// Install the editor using brew
+	fmt.Fprintf(p.stdout, "Installing %s using brew...\n", cask)
+	if err := p.runWithRetry(ctx, "brew", "install", "--cask", cask); err != nil {
+		return fmt.Errorf("failed to install %s: %w", p.cli, err)
+	}
+
+	return nil
+}
+
+// SetupConfig sets up the configuration for vscode provider, recording the editor CLI
+func (p *VSCodeProvider) SetupConfig(ctx context.Context) error {
+	// Ensure config directory exists
+	if err := config.EnsureConfigDir(); err != nil {
+		return fmt.Errorf("failed to ensure config directory: %w", err)
+	}
+
+	// Get version
+	version, err := p.GetVersion(ctx)
+	if err != nil {
+		// If version cannot be retrieved, continue without it
+		version = ""
+	}
+
+	// Add provider to config, with the editor CLI
+	if err := saveProviderBackend(p.name, version, p.cli); err != nil {
+		return fmt.Errorf("failed to save provider config: %w", err)
+	}
+
+	return nil
+}
+
+// ensureInstalled returns an error if the editor CLI is not installed
+func (p *VSCodeProvider) ensureInstalled(ctx context.Context) error {
+	installed, err := p.CheckInstalled(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to check %s installation: %w", p.cli, err)
+	}
+	if !installed {
+		return fmt.Errorf("%s is not installed. Please install it first using 'al provider add vscode'", p.cli)
+	}
+	return nil
+}
+
+// InstallPackage installs an extension with `code --install-extension`
+// packageID is the extension ID, optionally with a version (e.g. "ms-python.python@2024.14.1")
+func (p *VSCodeProvider) InstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	// Install the extension
+	fmt.Fprintf(p.stdout, "Installing %s using %s...\n", packageID, p.cli)
+	if err := p.runWithRetry(ctx, p.cli, "--install-extension", packageID); err != nil {
+		return fmt.Errorf("failed to install package %s: %w", packageID, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully installed %s\n", packageID)
+	return nil
+}
+
+// UninstallPackage uninstalls an extension with `code --uninstall-extension`
+func (p *VSCodeProvider) UninstallPackage(ctx context.Context, packageID string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	id, _, _ := strings.Cut(packageID, "@")
+	fmt.Fprintf(p.stdout, "Uninstalling %s using %s...\n", id, p.cli)
+	if err := p.run(ctx, p.cli, "--uninstall-extension", id); err != nil {
+		return fmt.Errorf("failed to uninstall package %s: %w", id, err)
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully uninstalled %s\n", id)
+	return nil
+}
+
+// UpgradePackage upgrades an extension to its latest version with `code --install-extension --force`.
+// An extension registered with a version is reinstalled at that version.
+func (p *VSCodeProvider) UpgradePackage(ctx context.Context, packageID string) error {
+	return p.UpgradePackages(ctx, []string{packageID})
+}
+
+// UpgradePackages upgrades several extensions with one `code --install-extension ... --force`
+func (p *VSCodeProvider) UpgradePackages(ctx context.Context, packageIDs []string) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	var args []string
+	for _, packageID := range packageIDs {
+		args = append(args, "--install-extension", packageID)
+	}
+	args = append(args, "--force")
+	fmt.Fprintf(p.stdout, "Upgrading %s using %s...\n", strings.Join(packageIDs, ", "), p.cli)
+	if err := p.runWithRetry(ctx, p.cli, args...); err != nil {
+		return fmt.Errorf("failed to upgrade packages %s: %w", strings.Join(packageIDs, ", "), err)
+	}
+	return nil
+}
+
+// Upgrade upgrades the editor using brew
+func (p *VSCodeProvider) Upgrade(ctx context.Context) error {
+	if err := p.ensureInstalled(ctx); err != nil {
+		return err
+	}
+
+	cask, err := p.cask()
+	if err != nil {
+		return fmt.Errorf("al cannot upgrade %s; upgrade the editor itself", p.cli)
+	}
+
+	// Check if brew is installed
+	if err := p.check(ctx, "brew", "--version"); err != nil {
+		return fmt.Errorf("brew is required to upgrade %s. Please install brew first using 'al provider add brew'", p.cli)
+	}
+
+	fmt.Fprintf(p.stdout, "Upgrading %s using brew...\n", cask)
+	if err := p.runWithRetry(ctx, "brew", "upgrade", "--cask", cask); err != nil {
+		return fmt.Errorf("failed to upgrade %s: %w", p.cli, err)
+	}
+
+	// Update version in config
+	version, err := p.GetVersion(ctx)
+	if err == nil {
+		if err := saveProviderVersion(p.name, version); err != nil {
+			fmt.Fprintf(p.stdout, "Warning: failed to update provider config: %v\n", err)
+		}
+	}
+
+	fmt.Fprintf(p.stdout, "Successfully upgraded %s\n", p.cli)
+	return nil
+}
+
+// SearchPackage is not supported: the editor CLI cannot search the marketplace
+func (p *VSCodeProvider) SearchPackage(ctx context.Context, query string) ([]SearchResult, error) {
+	return nil, fmt.Errorf("search is not supported by vscode (search the extensions view of the editor instead)")
+}
+
+// ListInstalled lists the extensions with `code --list-extensions --show-versions`
+func (p *VSCodeProvider) ListInstalled(ctx context.Context) ([]InstalledPackage, error) {
+	output, err := p.output(ctx, p.cli, "--list-extensions", "--show-versions")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list installed extensions: %w", err)
+	}
+
+	var packages []InstalledPackage
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimSpace(line)
+		// Lines look like "ms-python.python@2024.14.1"; other lines are messages
+		id, version, ok := strings.Cut(line, "@")
+		if !ok || !strings.Contains(id, ".") || strings.ContainsAny(id, " \t") {
+			continue
+		}
+		packages = append(packages, InstalledPackage{ID: id, Name: id, Version: version})
+	}
+	sort.Slice(packages, func(i, j int) bool {
+		return packages[i].Name < packages[j].Name
+	})
+	return packages, nil
+}
+
+// ListOutdated returns an empty result: the editor CLI cannot report extension updates.
+// Upgrading reinstalls every extension at its latest version.
+func (p *VSCodeProvider) ListOutdated(ctx context.Context) ([]OutdatedPackage, error) {
+	return []OutdatedPackage{}, nil
+}
@@ -0,0 +1,225 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+)
+
+type tokenKind int
+
+const (
+	tokenLParen tokenKind = iota
+	tokenRParen
+	tokenAnd
+	tokenOr
+	tokenNot
+	tokenCond
+)
+
+type token struct {
+	kind  tokenKind
+	field string
+	op    string
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenAnd:
+		return "'and'"
+	case tokenOr:
+		return "'or'"
+	case tokenNot:
+		return "'not'"
+	}
+	return fmt.Sprintf("'%s%s%s'", t.field, t.op, t.value)
+}
+
+// operators in matching order: two-character operators first
+var operators = []string{"!=", "^=", "$=", "*=", "!~", "<=", ">=", "=", "~", "<", ">"}
+
+// tokenize splits an expression into parentheses, keywords, and conditions
+func tokenize(expr string) ([]token, error) {
+	var tokens []token
+	i := 0
+	skipSpace := func() {
+		for i < len(expr) && isSpace(expr[i]) {
+			i++
+		}
+	}
+
+	for {
+		skipSpace()
+		if i >= len(expr) {
+			return tokens, nil
+		}
+
+		switch expr[i] {
+		case '(':
+			tokens = append(tokens, token{kind: tokenLParen})
+			i++
+			continue
+		case ')':
+			tokens = append(tokens, token{kind: tokenRParen})
+			i++
+			continue
+		}
+
+		// Field name or keyword
+		start := i
+		for i < len(expr) && isIdentChar(expr[i]) {
+			i++
+		}
+		word := expr[start:i]
+		if word == "" {
+			return nil, fmt.Errorf("unexpected '%c' at position %d", expr[i], i+1)
+		}
+
+		skipSpace()
+		op := ""
+		for _, candidate := range operators {
+			if strings.HasPrefix(expr[i:], candidate) {
+				op = candidate
+				break
+			}
+		}
+
+		if op == "" {
+			switch strings.ToLower(word) {
+			case "and":
+				tokens = append(tokens, token{kind: tokenAnd})
+			case "or":
+				tokens = append(tokens, token{kind: tokenOr})
+			case "not":
+				tokens = append(tokens, token{kind: tokenNot})
+			default:
+				return nil, fmt.Errorf("expected an operator after '%s' at position %d", word, i+1)
+			}
+			continue
+		}
+		i += len(op)
+
+		skipSpace()
+		value, next, err := readValue(expr, i)
+		if err != nil {
+			return nil, err
+		}
+		i = next
+		tokens = append(tokens, token{kind: tokenCond, field: word, op: op, value: value})
+	}
+}
+
+// readValue reads a quoted or bare value starting at i and returns it with the next position
+func readValue(expr string, i int) (string, int, error) {
+	if i < len(expr) && (expr[i] == '\'' || expr[i] == '"') {
+		quote := expr[i]
+		end := strings.IndexByte(expr[i+1:], quote)
+		if end < 0 {
+			return "", 0, fmt.Errorf("unterminated quote at position %d", i+1)
+		}
+		return expr[i+1 : i+1+end], i + 2 + end, nil
+	}
+	start := i
+	for i < len(expr) && !isSpace(expr[i]) && expr[i] != '(' && expr[i] != ')' {
+		i++
+	}
+	return expr[start:i], i, nil
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\r'
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// parser is a recursive descent parser:
+//
+//	or    = and { "or" and }
+//	and   = unary { "and" unary }
+//	unary = "not" unary | "(" or ")" | condition
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() (token, bool) {
+	if p.pos >= len(p.tokens) {
+		return token{}, false
+	}
+	return p.tokens[p.pos], true
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokenOr {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		t, ok := p.peek()
+		if !ok || t.kind != tokenAnd {
+			return left, nil
+		}
+		p.pos++
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andNode{left: left, right: right}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	t, ok := p.peek()
+	if !ok {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+
+	switch t.kind {
+	case tokenNot:
+		inner, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{inner: inner}, nil
+	case tokenLParen:
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		closing, ok := p.peek()
+		if !ok || closing.kind != tokenRParen {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		p.pos++
+		return inner, nil
+	case tokenCond:
+		return newCond(t.field, t.op, t.value)
+	}
+	return nil, fmt.Errorf("unexpected %s", t)
+}
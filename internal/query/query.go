@@ -0,0 +1,324 @@
+// Package query implements the filter expressions accepted by --where.
+//
+// An expression is a list of conditions combined with "and", "or", "not", and parentheses:
+//
+//	provider=brew and id^=cask: and (profile~work* or stage=trial) and installed_at<30d
+//
+// A condition is <field><operator><value>. Values may be quoted with ' or " when they contain
+// spaces or parentheses. Operators:
+//
+//	=   !=   equal / not equal
+//	^=  $=   has prefix / has suffix
+//	*=       contains
+//	~   !~   matches / does not match a glob (* and ?)
+//	<  <=  >  >=   time comparison (installed_at only)
+//
+// Time values are either an age such as 30d, 12h, or 2w (installed_at<30d means "added within
+// the last 30 days") or a date such as 2026-10-01 or an RFC 3339 time.
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+// Fields lists the fields available in expressions. stage is derived from the package's profile,
+// and upgrade is the effective upgrade policy (auto when not set).
+var Fields = []string{"id", "name", "provider", "profile", "version", "description", "installed_at", "upgrade", "constraint", "service", "stage"}
+
+// Query is a parsed filter expression
+type Query struct {
+	root node
+}
+
+// Env holds what is needed to evaluate derived fields
+type Env struct {
+	// Profiles maps profile names to their configuration
+	Profiles map[string]config.ProfileConfig
+	// Now is the reference time for ages such as 30d
+	Now time.Time
+}
+
+// NewEnv loads the profiles and returns an Env for the current time
+func NewEnv() (*Env, error) {
+	profilesConfig, err := config.LoadProfilesConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error loading profiles config: %w", err)
+	}
+	profiles := make(map[string]config.ProfileConfig, len(profilesConfig.Profiles))
+	for _, p := range profilesConfig.Profiles {
+		profiles[p.Name] = p
+	}
+	return &Env{Profiles: profiles, Now: time.Now()}, nil
+}
+
+// Parse parses a filter expression
+func Parse(expr string) (*Query, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s", p.tokens[p.pos])
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether pkg satisfies the query
+func (q *Query) Match(pkg config.PackageConfig, env *Env) bool {
+	return q.root.eval(pkg, env)
+}
+
+// Filter returns the packages that satisfy the query, in their original order
+func (q *Query) Filter(packages []config.PackageConfig, env *Env) []config.PackageConfig {
+	matched := []config.PackageConfig{}
+	for _, pkg := range packages {
+		if q.Match(pkg, env) {
+			matched = append(matched, pkg)
+		}
+	}
+	return matched
+}
+
+// FilterPackages parses expr and returns the matching packages.
+// An empty expression matches every package.
+func FilterPackages(expr string, packages []config.PackageConfig) ([]config.PackageConfig, error) {
+	if strings.TrimSpace(expr) == "" {
+		return packages, nil
+	}
+	q, err := Parse(expr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --where expression: %w", err)
+	}
+	env, err := NewEnv()
+	if err != nil {
+		return nil, err
+	}
+	return q.Filter(packages, env), nil
+}
+
+// node is an expression tree node
+type node interface {
+	eval(pkg config.PackageConfig, env *Env) bool
+}
+
+type andNode struct{ left, right node }
+
+func (n andNode) eval(pkg config.PackageConfig, env *Env) bool {
+	return n.left.eval(pkg, env) && n.right.eval(pkg, env)
+}
+
+type orNode struct{ left, right node }
+
+func (n orNode) eval(pkg config.PackageConfig, env *Env) bool {
+	return n.left.eval(pkg, env) || n.right.eval(pkg, env)
+}
+
+type notNode struct{ inner node }
+
+func (n notNode) eval(pkg config.PackageConfig, env *Env) bool {
+	return !n.inner.eval(pkg, env)
+}
+
+// stringCond compares a string field
+type stringCond struct {
+	field string
+	op    string
+	value string
+	glob  *regexp.Regexp
+}
+
+func (c stringCond) eval(pkg config.PackageConfig, env *Env) bool {
+	actual := stringField(pkg, c.field, env)
+	switch c.op {
+	case "=":
+		return actual == c.value
+	case "!=":
+		return actual != c.value
+	case "^=":
+		return strings.HasPrefix(actual, c.value)
+	case "$=":
+		return strings.HasSuffix(actual, c.value)
+	case "*=":
+		return strings.Contains(actual, c.value)
+	case "~":
+		return c.glob.MatchString(actual)
+	case "!~":
+		return !c.glob.MatchString(actual)
+	}
+	return false
+}
+
+// timeCond compares installed_at with an absolute time or an age
+type timeCond struct {
+	op    string
+	value time.Time
+	// relative is set when the value is an age; it is then compared with Env.Now - installed_at
+	relative bool
+	age      time.Duration
+}
+
+func (c timeCond) eval(pkg config.PackageConfig, env *Env) bool {
+	if pkg.InstalledAt.IsZero() {
+		return false
+	}
+	if c.relative {
+		// installed_at<30d: the package is younger than 30 days
+		age := env.Now.Sub(pkg.InstalledAt)
+		switch c.op {
+		case "<":
+			return age < c.age
+		case "<=":
+			return age <= c.age
+		case ">":
+			return age > c.age
+		case ">=":
+			return age >= c.age
+		}
+		return false
+	}
+	switch c.op {
+	case "<":
+		return pkg.InstalledAt.Before(c.value)
+	case "<=":
+		return !pkg.InstalledAt.After(c.value)
+	case ">":
+		return pkg.InstalledAt.After(c.value)
+	case ">=":
+		return !pkg.InstalledAt.Before(c.value)
+	}
+	return false
+}
+
+// stringField returns the value of a string field of pkg
+func stringField(pkg config.PackageConfig, field string, env *Env) string {
+	switch field {
+	case "id":
+		return pkg.ID
+	case "name":
+		return pkg.Name
+	case "provider":
+		return pkg.Provider
+	case "profile":
+		return pkg.Profile
+	case "version":
+		return pkg.Version
+	case "description":
+		return pkg.Description
+	case "installed_at":
+		if pkg.InstalledAt.IsZero() {
+			return ""
+		}
+		return pkg.InstalledAt.Format(time.RFC3339)
+	case "upgrade":
+		return pkg.UpgradePolicy()
+	case "constraint":
+		return pkg.Constraint
+	case "service":
+		return pkg.Service
+	case "stage":
+		if env == nil {
+			return ""
+		}
+		return env.Profiles[pkg.Profile].Stage
+	}
+	return ""
+}
+
+func isField(name string) bool {
+	for _, f := range Fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// newCond builds a condition node, validating the field, operator, and value
+func newCond(field, op, value string) (node, error) {
+	if !isField(field) {
+		return nil, fmt.Errorf("unknown field '%s' (available: %s)", field, strings.Join(Fields, ", "))
+	}
+
+	switch op {
+	case "<", "<=", ">", ">=":
+		if field != "installed_at" {
+			return nil, fmt.Errorf("operator %s is only supported for installed_at", op)
+		}
+		if age, ok := parseAge(value); ok {
+			return timeCond{op: op, relative: true, age: age}, nil
+		}
+		t, err := parseTime(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time '%s' for installed_at (use an age like 30d or a date like 2026-10-01)", value)
+		}
+		return timeCond{op: op, value: t}, nil
+	case "~", "!~":
+		return stringCond{field: field, op: op, value: value, glob: globToRegexp(value)}, nil
+	}
+	return stringCond{field: field, op: op, value: value}, nil
+}
+
+// parseAge parses an age such as 30d, 2w, 12h, or 90m
+func parseAge(value string) (time.Duration, bool) {
+	if len(value) < 2 {
+		return 0, false
+	}
+	n, err := strconv.Atoi(value[:len(value)-1])
+	if err != nil || n < 0 {
+		return 0, false
+	}
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'w':
+		unit = 7 * 24 * time.Hour
+	case 'd':
+		unit = 24 * time.Hour
+	case 'h':
+		unit = time.Hour
+	case 'm':
+		unit = time.Minute
+	default:
+		return 0, false
+	}
+	return time.Duration(n) * unit, true
+}
+
+// parseTime parses a date (local time) or an RFC 3339 time
+func parseTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// globToRegexp converts a glob with * and ? to an anchored regular expression
+func globToRegexp(glob string) *regexp.Regexp {
+	var b strings.Builder
+	b.WriteString("^")
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteString(".*")
+		case '?':
+			b.WriteString(".")
+		default:
+			b.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.MustCompile(b.String())
+}
@@ -0,0 +1,70 @@
+package query
+
+import (
+	"testing"
+	"time"
+
+	"github.com/kkato1030/al/internal/config"
+)
+
+func TestParseErrors(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"provider",
+		"color=red",
+		"provider=brew and",
+		"(provider=brew",
+		"provider=brew)",
+		"name<30d",
+		"installed_at<yesterday",
+		"name='unterminated",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q): expected an error", expr)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	now := time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC)
+	env := &Env{
+		Profiles: map[string]config.ProfileConfig{"work": {Name: "work", Stage: "trial"}},
+		Now:      now,
+	}
+	pkg := config.PackageConfig{
+		Name: "slack", ID: "cask:slack", Provider: "brew", Profile: "work",
+		InstalledAt: now.Add(-10 * 24 * time.Hour),
+	}
+
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"provider=brew and id^=cask:", true},
+		{"provider!=brew", false},
+		{"id$=slack and name*=lac", true},
+		// and binds tighter than or
+		{"provider=npm and name=x or profile=work", true},
+		{"provider=npm and (name=x or profile=work)", false},
+		{"not provider=npm", true},
+		{"profile~wo*", true},
+		{"profile~w?rk and name!~s*", false},
+		{"stage=trial", true},
+		{"installed_at<30d", true},
+		{"installed_at<2w", true},
+		{"installed_at>12h and installed_at>90m", true},
+		{"installed_at>=2w", false},
+		{"installed_at<2026-10-01", false},
+		{"installed_at>'2026-10-01'", true},
+	}
+	for _, tt := range tests {
+		q, err := Parse(tt.expr)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", tt.expr, err)
+			continue
+		}
+		if got := q.Match(pkg, env); got != tt.want {
+			t.Errorf("Match(%q) = %v, want %v", tt.expr, got, tt.want)
+		}
+	}
+}
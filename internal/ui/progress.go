@@ -0,0 +1,176 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/muesli/termenv"
+	"golang.org/x/term"
+)
+
+// spinnerFrames are the frames of the spinner shown next to running tasks
+var spinnerFrames = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+
+// Progress shows a compact view of many tasks: a spinner per running task and a line per finished one
+type Progress struct {
+	program *tea.Program
+	labels  []string
+	done    chan struct{}
+}
+
+type progressState int
+
+const (
+	progressWaiting progressState = iota
+	progressRunning
+	progressFinished
+)
+
+type progressTask struct {
+	label string
+	state progressState
+}
+
+type progressStartMsg struct{ index int }
+
+type progressFinishMsg struct {
+	index int
+	ok    bool
+}
+
+type progressTickMsg struct{}
+
+type progressStopMsg struct{}
+
+// ProgressModel is the bubbletea model behind Progress
+type ProgressModel struct {
+	tasks    []progressTask
+	frame    int
+	finished int
+	failed   int
+	stopped  bool
+}
+
+// CanShowProgress returns true if w is a terminal, where the progress display can be drawn
+func CanShowProgress(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// NewProgress starts a progress display on w for tasks with the given labels
+func NewProgress(w io.Writer, labels []string) *Progress {
+	model := &ProgressModel{tasks: make([]progressTask, len(labels))}
+	for i, label := range labels {
+		model.tasks[i] = progressTask{label: label}
+	}
+
+	// No input and no signal handler: Ctrl-C keeps reaching the command as SIGINT. The output is
+	// wrapped without a color cache, which would query the terminal's colors and can stall for
+	// seconds on terminals that do not answer.
+	p := &Progress{
+		program: tea.NewProgram(model, tea.WithOutput(termenv.NewOutput(w)), tea.WithInput(nil), tea.WithoutSignalHandler()),
+		labels:  labels,
+		done:    make(chan struct{}),
+	}
+	go func() {
+		defer close(p.done)
+		_, _ = p.program.Run()
+	}()
+	return p
+}
+
+// Start shows a spinner for task i
+func (p *Progress) Start(i int) {
+	p.program.Send(progressStartMsg{index: i})
+}
+
+// Finish replaces the spinner of task i with a line marking it succeeded or failed
+func (p *Progress) Finish(i int, ok bool, detail string) {
+	if i < 0 || i >= len(p.labels) {
+		return
+	}
+	select {
+	case <-p.done:
+		// The display could not be started; Println would block
+		return
+	default:
+	}
+	mark := "✓"
+	if !ok {
+		mark = "✗"
+	}
+	line := fmt.Sprintf("  %s %s", mark, p.labels[i])
+	if detail != "" {
+		line += "  " + detail
+	}
+	p.program.Send(progressFinishMsg{index: i, ok: ok})
+	// Println is sent on the same channel as Send, so lines stay in order and are flushed by Stop
+	p.program.Println(line)
+}
+
+// Stop ends the display and waits until it has been cleared from the terminal
+func (p *Progress) Stop() {
+	p.program.Send(progressStopMsg{})
+	<-p.done
+}
+
+// Init starts the spinner
+func (m *ProgressModel) Init() tea.Cmd {
+	return progressTick()
+}
+
+// Update handles messages
+func (m *ProgressModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case progressTickMsg:
+		m.frame = (m.frame + 1) % len(spinnerFrames)
+		return m, progressTick()
+	case progressStartMsg:
+		if msg.index >= 0 && msg.index < len(m.tasks) && m.tasks[msg.index].state == progressWaiting {
+			m.tasks[msg.index].state = progressRunning
+		}
+	case progressFinishMsg:
+		if msg.index < 0 || msg.index >= len(m.tasks) || m.tasks[msg.index].state == progressFinished {
+			return m, nil
+		}
+		m.tasks[msg.index].state = progressFinished
+		m.finished++
+		if !msg.ok {
+			m.failed++
+		}
+	case progressStopMsg:
+		m.stopped = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+// View renders the running tasks and a counter
+func (m *ProgressModel) View() string {
+	if m.stopped {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, task := range m.tasks {
+		if task.state == progressRunning {
+			b.WriteString(fmt.Sprintf("  %s %s\n", spinnerFrames[m.frame], task.label))
+		}
+	}
+	b.WriteString(fmt.Sprintf("  %d/%d done", m.finished, len(m.tasks)))
+	if m.failed > 0 {
+		b.WriteString(fmt.Sprintf(", %d failed", m.failed))
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func progressTick() tea.Cmd {
+	return tea.Tick(100*time.Millisecond, func(time.Time) tea.Msg {
+		return progressTickMsg{}
+	})
+}
@@ -0,0 +1,150 @@
+// Package version compares package versions and evaluates version constraints.
+//
+// A constraint is one or more comma-separated clauses that must all hold:
+//
+//	20        same as 20.x: the version starts with the components 20 (20, 20.11.1, but not 200)
+//	~20.11    same as 20.11.x
+//	>=20, <22 comparison operators: = != < <= > >=
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Compare compares two versions component by component and returns -1, 0, or 1.
+// Components are separated by '.', '-', '_', or '+'; numeric components compare as numbers,
+// others as strings, and a missing component sorts before a present one (1.2 < 1.2.1).
+func Compare(a, b string) int {
+	pa, pb := split(a), split(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		if i >= len(pa) {
+			return -1
+		}
+		if i >= len(pb) {
+			return 1
+		}
+		if c := compareComponent(pa[i], pb[i]); c != 0 {
+			return c
+		}
+	}
+	return 0
+}
+
+func split(v string) []string {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	return strings.FieldsFunc(v, func(r rune) bool {
+		return r == '.' || r == '-' || r == '_' || r == '+'
+	})
+}
+
+func compareComponent(a, b string) int {
+	na, errA := strconv.Atoi(a)
+	nb, errB := strconv.Atoi(b)
+	switch {
+	case errA == nil && errB == nil:
+		switch {
+		case na < nb:
+			return -1
+		case na > nb:
+			return 1
+		}
+		return 0
+	case errA == nil:
+		// Numbers sort after pre-release labels such as "rc1"
+		return 1
+	case errB == nil:
+		return -1
+	}
+	return strings.Compare(a, b)
+}
+
+// Constraint is a parsed version constraint
+type Constraint struct {
+	text    string
+	clauses []clause
+}
+
+type clause struct {
+	op      string
+	version string
+}
+
+// ParseConstraint parses a constraint such as "20", "~20.11", or ">=20, <22"
+func ParseConstraint(text string) (*Constraint, error) {
+	c := &Constraint{text: strings.TrimSpace(text)}
+	for _, part := range strings.Split(text, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		op := "prefix"
+		for _, candidate := range []string{">=", "<=", "!=", "=", "<", ">", "~"} {
+			if strings.HasPrefix(part, candidate) {
+				op = candidate
+				part = strings.TrimSpace(part[len(candidate):])
+				break
+			}
+		}
+		if op == "~" {
+			op = "prefix"
+		}
+		if op == "prefix" {
+			part = strings.TrimSuffix(strings.TrimSuffix(part, ".x"), ".*")
+		}
+		if part == "" || strings.ContainsAny(part, " <>=!~") {
+			return nil, fmt.Errorf("invalid version constraint: %s", text)
+		}
+		c.clauses = append(c.clauses, clause{op: op, version: part})
+	}
+	if len(c.clauses) == 0 {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+	return c, nil
+}
+
+// String returns the constraint as written
+func (c *Constraint) String() string {
+	return c.text
+}
+
+// Check reports whether v satisfies every clause of the constraint
+func (c *Constraint) Check(v string) bool {
+	for _, cl := range c.clauses {
+		if !cl.check(v) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cl clause) check(v string) bool {
+	switch cl.op {
+	case "prefix":
+		want, got := split(cl.version), split(v)
+		if len(got) < len(want) {
+			return false
+		}
+		for i := range want {
+			if compareComponent(want[i], got[i]) != 0 {
+				return false
+			}
+		}
+		return true
+	case "=":
+		return Compare(v, cl.version) == 0
+	case "!=":
+		return Compare(v, cl.version) != 0
+	case "<":
+		return Compare(v, cl.version) < 0
+	case "<=":
+		return Compare(v, cl.version) <= 0
+	case ">":
+		return Compare(v, cl.version) > 0
+	case ">=":
+		return Compare(v, cl.version) >= 0
+	}
+	return false
+}
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"reflect"
+	"regexp"
+	"strconv"
 	"strings"
+	"syscall"
 
 	"github.com/kkato1030/al/cmd"
 	"github.com/kkato1030/al/internal/config"
@@ -35,7 +42,17 @@ func main() {
 
 	rootCmd := cmd.NewRootCmd()
 
-	if err := rootCmd.Execute(); err != nil {
+	// Ctrl-C cancels the context: running provider commands are interrupted and the command
+	// stops before recording unfinished work. A second Ctrl-C exits immediately.
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	err = rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
 	}
@@ -47,8 +64,11 @@ func resolveAliases(args []string) ([]string, error) {
 		return args, nil
 	}
 
-	// Get default aliases
-	aliases := config.GetDefaultAliases()
+	// Get default and user-defined aliases
+	aliases, err := config.GetAliases()
+	if err != nil {
+		return nil, fmt.Errorf("error loading aliases: %w", err)
+	}
 
 	// Check if first argument is an alias
 	aliasName := args[0]
@@ -57,123 +77,276 @@ func resolveAliases(args []string) ([]string, error) {
 		return args, nil
 	}
 
-	// Resolve special variables in alias command
+	// Resolve variables and arguments in alias command
 	originalArgs := args[1:]
-	resolvedCommand, err := resolveAliasVariables(aliasCommand, originalArgs)
+	resolvedArgs, err := expandAlias(aliasCommand, originalArgs)
 	if err != nil {
-		return nil, fmt.Errorf("error resolving alias variables: %w", err)
+		return nil, fmt.Errorf("alias '%s': %w", aliasName, err)
 	}
 
-	// Parse the resolved command into arguments
-	resolvedArgs := parseCommand(resolvedCommand)
-
-	// Insert original arguments at appropriate positions
-	// Replace {args} placeholder if present, otherwise append at the end
-	resolvedArgs = insertArguments(resolvedArgs, originalArgs)
+	if hasVerboseFlag(originalArgs) {
+		fmt.Fprintf(os.Stderr, "alias %s: al %s\n", aliasName, formatCommand(resolvedArgs))
+	}
 
 	return resolvedArgs, nil
 }
 
-// resolveAliasVariables resolves special variables like package.promote_to in alias commands
-func resolveAliasVariables(aliasCommand string, originalArgs []string) (string, error) {
-	result := aliasCommand
+// aliasVariablePattern matches alias variables: {1}, {2}, ..., {args},
+// {package.<field>}, {profile.<field>}, and {config.<field>}
+var aliasVariablePattern = regexp.MustCompile(`\{(args|[0-9]+|(?:package|profile|config)\.[a-z_]+)\}`)
 
-	// Resolve package.promote_to
-	if strings.Contains(result, "package.promote_to") {
-		promoteTo, err := resolvePromoteTo(originalArgs)
-		if err != nil {
-			return "", fmt.Errorf("error resolving package.promote_to: %w", err)
+// legacyAliasVariables rewrites the bare package.promote_to used by older aliases to {package.promote_to}
+var legacyAliasVariables = strings.NewReplacer(
+	"{package.promote_to}", "{package.promote_to}",
+	"package.promote_to", "{package.promote_to}",
+)
+
+// expandAlias expands an alias command with the arguments given after the alias name.
+// Positional variables ({1}, {2}, ...) consume their argument. The remaining arguments replace
+// an {args} token, or are appended at the end if there is none.
+func expandAlias(aliasCommand string, originalArgs []string) ([]string, error) {
+	tokens := parseCommand(legacyAliasVariables.Replace(aliasCommand))
+	ctx := &aliasContext{args: originalArgs}
+	used := make(map[int]bool)
+
+	// First pass: positional variables, so that {args} knows which arguments remain
+	for _, token := range tokens {
+		for _, m := range aliasVariablePattern.FindAllStringSubmatch(token, -1) {
+			n, err := strconv.Atoi(m[1])
+			if err != nil {
+				continue
+			}
+			if n < 1 || n > len(originalArgs) {
+				return nil, fmt.Errorf("{%d} requires at least %d argument(s)", n, n)
+			}
+			used[n-1] = true
 		}
-		if promoteTo == "" {
-			return "", fmt.Errorf("package.promote_to could not be resolved. Make sure the package exists and its profile has promote_to set")
+	}
+	var remaining []string
+	for i, arg := range originalArgs {
+		if !used[i] {
+			remaining = append(remaining, arg)
 		}
-		result = strings.ReplaceAll(result, "package.promote_to", promoteTo)
 	}
 
-	return result, nil
-}
+	resolved := make([]string, 0, len(tokens)+len(remaining))
+	hasArgsToken := false
+	for _, token := range tokens {
+		if token == "{args}" {
+			resolved = append(resolved, remaining...)
+			hasArgsToken = true
+			continue
+		}
 
-// resolvePromoteTo resolves the promote_to value for a package
-func resolvePromoteTo(args []string) (string, error) {
-	if len(args) == 0 {
-		return "", fmt.Errorf("package name is required")
+		var expandErr error
+		expanded := aliasVariablePattern.ReplaceAllStringFunc(token, func(match string) string {
+			if expandErr != nil {
+				return match
+			}
+			name := match[1 : len(match)-1]
+			if name == "args" {
+				hasArgsToken = true
+				return strings.Join(remaining, " ")
+			}
+			if n, err := strconv.Atoi(name); err == nil {
+				return originalArgs[n-1]
+			}
+			value, err := ctx.resolve(name)
+			if err != nil {
+				expandErr = fmt.Errorf("error resolving %s: %w", name, err)
+				return match
+			}
+			return value
+		})
+		if expandErr != nil {
+			return nil, expandErr
+		}
+		resolved = append(resolved, expanded)
 	}
 
-	packageName := args[0]
+	if !hasArgsToken {
+		resolved = append(resolved, remaining...)
+	}
 
-	// Load packages config to find the package
-	packagesConfig, err := config.LoadPackagesConfig()
+	return resolved, nil
+}
+
+// aliasContext resolves package.*, profile.*, and config.* variables.
+// The package is the registered package named by the first positional argument; the profile is
+// that package's profile, or the default profile when no such package is registered.
+type aliasContext struct {
+	args []string
+
+	loaded    bool
+	appConfig *config.AppConfig
+	pkg       *config.PackageConfig
+	profile   *config.ProfileConfig
+}
+
+func (c *aliasContext) load() error {
+	if c.loaded {
+		return nil
+	}
+
+	appConfig, err := config.LoadAppConfig()
 	if err != nil {
-		return "", err
+		return err
 	}
+	c.appConfig = appConfig
 
-	// Find the package
-	var foundPackage *config.PackageConfig
-	for i := range packagesConfig.Packages {
-		if packagesConfig.Packages[i].Name == packageName {
-			foundPackage = &packagesConfig.Packages[i]
-			break
+	if packageName := firstPositionalArg(c.args); packageName != "" {
+		packagesConfig, err := config.LoadPackagesConfig()
+		if err != nil {
+			return err
+		}
+		for i := range packagesConfig.Packages {
+			if packagesConfig.Packages[i].Name == packageName {
+				c.pkg = &packagesConfig.Packages[i]
+				break
+			}
 		}
 	}
 
-	if foundPackage == nil {
-		// Try to use default profile's promote_to
-		appConfig, err := config.LoadAppConfig()
+	profileName := appConfig.DefaultProfile
+	if c.pkg != nil {
+		profileName = c.pkg.Profile
+	}
+	if profileName != "" {
+		profile, err := config.GetProfile(profileName)
 		if err != nil {
-			return "", err
+			return err
 		}
+		c.profile = profile
+	}
 
-		if appConfig.DefaultProfile != "" {
-			profile, err := config.GetProfile(appConfig.DefaultProfile)
-			if err != nil {
-				return "", err
+	c.loaded = true
+	return nil
+}
+
+// resolve returns the value of a "<scope>.<field>" variable
+func (c *aliasContext) resolve(name string) (string, error) {
+	if err := c.load(); err != nil {
+		return "", err
+	}
+
+	scope, field, _ := strings.Cut(name, ".")
+	var value string
+	var found bool
+	switch scope {
+	case "config":
+		value, found = lookupJSONField(c.appConfig, field)
+	case "profile":
+		if c.profile == nil {
+			return "", fmt.Errorf("no profile to resolve (the package is not registered and default_profile is not set)")
+		}
+		value, found = lookupJSONField(c.profile, field)
+	case "package":
+		switch {
+		case field == "promote_to" || field == "stage":
+			// Derived from the package's profile (falls back to the default profile)
+			if c.profile == nil {
+				return "", fmt.Errorf("package '%s' not found", firstPositionalArg(c.args))
 			}
-			if profile != nil && profile.PromoteTo != "" {
-				return profile.PromoteTo, nil
+			value, found = lookupJSONField(c.profile, field)
+			if found && value == "" {
+				return "", fmt.Errorf("profile '%s' does not have %s set", c.profile.Name, field)
 			}
+		case c.pkg == nil:
+			if firstPositionalArg(c.args) == "" {
+				return "", fmt.Errorf("package name is required")
+			}
+			return "", fmt.Errorf("package '%s' not found", firstPositionalArg(c.args))
+		default:
+			value, found = lookupJSONField(c.pkg, field)
 		}
-
-		return "", fmt.Errorf("package '%s' not found", packageName)
 	}
+	if !found {
+		return "", fmt.Errorf("unknown field '%s'", field)
+	}
+	if value == "" {
+		return "", fmt.Errorf("%s is not set", name)
+	}
+	return value, nil
+}
 
-	// Get the profile's promote_to
-	profile, err := config.GetProfile(foundPackage.Profile)
+// lookupJSONField returns a field of v by its JSON name. Lists are joined with commas.
+func lookupJSONField(v interface{}, field string) (string, bool) {
+	data, err := json.Marshal(v)
 	if err != nil {
-		return "", err
+		return "", false
+	}
+	var fields map[string]interface{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return "", false
 	}
 
-	if profile == nil {
-		return "", fmt.Errorf("profile '%s' not found", foundPackage.Profile)
+	raw, ok := fields[field]
+	if !ok {
+		// Fields with omitempty are missing when empty; report them as known but empty
+		return "", jsonFieldExists(v, field)
+	}
+	switch value := raw.(type) {
+	case string:
+		return value, true
+	case []interface{}:
+		parts := make([]string, len(value))
+		for i, part := range value {
+			parts[i] = fmt.Sprint(part)
+		}
+		return strings.Join(parts, ","), true
+	case map[string]interface{}:
+		return "", false
+	default:
+		return fmt.Sprint(value), true
 	}
+}
 
-	if profile.PromoteTo == "" {
-		return "", fmt.Errorf("profile '%s' does not have promote_to set", foundPackage.Profile)
+// jsonFieldExists reports whether the struct pointed to by v has a field with the given JSON name
+func jsonFieldExists(v interface{}, field string) bool {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == field {
+			return true
+		}
 	}
+	return false
+}
 
-	return profile.PromoteTo, nil
+// firstPositionalArg returns the first argument that is not a flag
+func firstPositionalArg(args []string) string {
+	for _, arg := range args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
 }
 
-// insertArguments inserts original arguments into resolved command
-// Replaces {args} placeholder if present, otherwise appends at the end
-func insertArguments(resolvedArgs, originalArgs []string) []string {
-	if len(originalArgs) == 0 {
-		return resolvedArgs
-	}
-
-	// Check if {args} placeholder exists
-	for i, arg := range resolvedArgs {
-		if arg == "{args}" {
-			// Replace {args} with original arguments
-			newArgs := make([]string, 0, len(resolvedArgs)-1+len(originalArgs))
-			newArgs = append(newArgs, resolvedArgs[:i]...)
-			newArgs = append(newArgs, originalArgs...)
-			newArgs = append(newArgs, resolvedArgs[i+1:]...)
-			return newArgs
+// hasVerboseFlag returns true if --verbose is among the arguments
+func hasVerboseFlag(args []string) bool {
+	for _, arg := range args {
+		if arg == "--verbose" || arg == "--verbose=true" {
+			return true
 		}
 	}
+	return false
+}
 
-	// No placeholder found, append at the end
-	return append(resolvedArgs, originalArgs...)
+// formatCommand formats arguments for display, quoting those that contain spaces
+func formatCommand(args []string) string {
+	parts := make([]string, len(args))
+	for i, arg := range args {
+		if arg == "" || strings.ContainsAny(arg, " \t\"'") {
+			parts[i] = strconv.Quote(arg)
+		} else {
+			parts[i] = arg
+		}
+	}
+	return strings.Join(parts, " ")
 }
 
 // parseCommand parses a command string into arguments